@@ -0,0 +1,110 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bookingLinkPage is the page a reminder link opens. It describes the
+// booking and, until the action is taken, offers a button that posts it back
+// to the same URL. Opening the page changes nothing, so mail scanners that
+// follow links are harmless.
+var bookingLinkPage = template.Must(template.New("booking-link").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 32rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; }
+button { font-size: 1rem; padding: 0.5rem 1.25rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{with .Link}}<p><strong>{{.PetName}}'s {{.ServiceName}}</strong> with {{.ProviderName}}<br>
+{{.LocalTime.Format "Monday 2 January 2006 at 15:04 MST"}}</p>{{end}}
+{{with .Message}}<p>{{.}}</p>{{end}}
+{{with .Button}}<form method="post"><button type="submit">{{.}}</button></form>{{end}}
+</body>
+</html>
+`))
+
+type bookingLinkPageData struct {
+	Title   string
+	Message string
+	Button  string
+	Link    *models.BookingLink
+}
+
+func renderBookingLinkPage(c *gin.Context, status int, data bookingLinkPageData) {
+	var page bytes.Buffer
+	if err := bookingLinkPage.Execute(&page, data); err != nil {
+		log.Printf("Failed to render booking link page: %v", err)
+		c.String(http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", page.Bytes())
+}
+
+// renderBookingLinkError shows why a link could not be opened or followed.
+func renderBookingLinkError(c *gin.Context, link *models.BookingLink, err error) {
+	status, message := http.StatusInternalServerError, "Something went wrong. Please try again, or contact your provider."
+	switch {
+	case errors.Is(err, services.ErrInvalidBookingLink),
+		errors.Is(err, services.ErrBookingNotFound):
+		status, message = http.StatusNotFound, "This link is invalid or has expired."
+	case errors.Is(err, services.ErrInvalidTransition),
+		errors.Is(err, services.ErrBookingInactive):
+		status, message = http.StatusConflict, "This booking can no longer be changed from this link."
+	case errors.Is(err, services.ErrPaymentFailed):
+		status, message = http.StatusPaymentRequired, "The cancellation fee could not be charged. Please contact your provider."
+	default:
+		log.Printf("Failed to handle booking link: %v", err)
+	}
+	renderBookingLinkPage(c, status, bookingLinkPageData{Title: "Unable to update booking", Message: message, Link: link})
+}
+
+func (s *Server) handleBookingLinkPage(c *gin.Context) {
+	link, err := s.reminderService.InspectLink(c.Param("token"))
+	if err != nil {
+		renderBookingLinkError(c, nil, err)
+		return
+	}
+
+	data := bookingLinkPageData{Link: link}
+	switch link.Action {
+	case models.LinkConfirmAttendance:
+		data.Title = "Confirm your appointment"
+		data.Button = "Confirm I'll be there"
+	case models.LinkCancelBooking:
+		data.Title = "Cancel your appointment"
+		data.Message = "Your provider's cancellation policy applies, so a late cancellation may be charged a fee."
+		data.Button = "Cancel appointment"
+	}
+	renderBookingLinkPage(c, http.StatusOK, data)
+}
+
+func (s *Server) handleBookingLinkPageAction(c *gin.Context) {
+	token := c.Param("token")
+	link, err := s.reminderService.FollowLink(token)
+	if err != nil {
+		inspected, _ := s.reminderService.InspectLink(token)
+		renderBookingLinkError(c, inspected, err)
+		return
+	}
+
+	data := bookingLinkPageData{Link: link, Title: "Appointment confirmed", Message: "Thanks, we'll see you then."}
+	if link.Action == models.LinkCancelBooking {
+		data.Title, data.Message = "Appointment cancelled", "Your appointment has been cancelled."
+	}
+	renderBookingLinkPage(c, http.StatusOK, data)
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"pet-grooming-app/internal/caldav"
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const calendarContentType = "text/calendar; charset=utf-8"
+
+func respondCalendarError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrCalendarFeedNotFound),
+		errors.Is(err, services.ErrCalendarNotConnected):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, caldav.ErrNotCalendar),
+		errors.Is(err, caldav.ErrUnauthorized),
+		errors.Is(err, services.ErrCalendarURLNotPublic):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondBookingError(c, err, fallback)
+	}
+}
+
+func (s *Server) handleGetCalendarFeed(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	feed, err := s.calendarService.Feed(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+func (s *Server) handleResetCalendarFeed(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	feed, err := s.calendarService.ResetFeed(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+func (s *Server) handleCalendarFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	calendar, err := s.calendarService.FeedCalendar(token)
+	if err != nil {
+		respondCalendarError(c, err, "Failed to build calendar feed")
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, calendarContentType, calendar.Bytes())
+}
+
+func (s *Server) handleDownloadBookingCalendar(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	calendar, err := s.calendarService.BookingCalendar(bookingID, userID)
+	if err != nil {
+		respondCalendarError(c, err, "Failed to export booking")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="booking-%s.ics"`, bookingID))
+	c.Data(http.StatusOK, calendarContentType, calendar.Bytes())
+}
+
+func (s *Server) handleGetCalendarConnection(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	conn, err := s.calendarSyncService.Get(userID)
+	if err != nil {
+		respondCalendarError(c, err, "Failed to fetch calendar connection")
+		return
+	}
+
+	c.JSON(http.StatusOK, conn)
+}
+
+func (s *Server) handleConnectCalendar(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ConnectCalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conn, err := s.calendarSyncService.Connect(c.Request.Context(), userID, req)
+	if err != nil {
+		respondCalendarError(c, err, "Failed to connect calendar")
+		return
+	}
+
+	c.JSON(http.StatusOK, conn)
+}
+
+func (s *Server) handleDisconnectCalendar(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := s.calendarSyncService.Disconnect(userID); err != nil {
+		respondCalendarError(c, err, "Failed to disconnect calendar")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar disconnected"})
+}
+
+func (s *Server) handleSyncCalendar(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	conn, err := s.calendarSyncService.SyncNow(c.Request.Context(), userID)
+	if err != nil {
+		respondCalendarError(c, err, "Failed to sync calendar")
+		return
+	}
+
+	c.JSON(http.StatusOK, conn)
+}
+
+func (s *Server) handleGetBusyBlocks(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	blocks, err := s.calendarSyncService.BusyBlocks(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch busy times"})
+		return
+	}
+
+	c.JSON(http.StatusOK, blocks)
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) handleGetCareLog(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	days, err := s.careLogService.List(bookingID, userID)
+	if err != nil {
+		respondBookingError(c, err, "Failed to fetch care log")
+		return
+	}
+
+	c.JSON(http.StatusOK, days)
+}
+
+func (s *Server) handleAddCareLog(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	var req models.CreateCareLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := s.careLogService.Add(bookingID, userID, req)
+	if err != nil {
+		respondBookingError(c, err, "Failed to log care")
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
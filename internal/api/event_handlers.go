@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventHeartbeat is how often an idle stream sends a comment, keeping
+// proxies from closing it and letting the client notice a dead connection.
+const eventHeartbeat = 25 * time.Second
+
+// handleCreateStreamToken issues a short-lived token for opening the event
+// stream from clients that cannot send an Authorization header, such as a
+// browser's EventSource. Clients fetch a fresh one each time they connect.
+func (s *Server) handleCreateStreamToken(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	token, expiresAt, err := s.authService.GenerateStreamToken(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue stream token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"token": token, "expires_at": expiresAt})
+}
+
+// handleEventStream streams booking changes for the current user as
+// server-sent events. Each event is named by its type and carries the
+// booking's new state as data.
+func (s *Server) handleEventStream(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Streaming is not supported"})
+		return
+	}
+
+	events, unregister := s.hub.Register(userID)
+	defer unregister()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 5000\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event := <-events:
+			fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event.Type, event.Data)
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+		}
+		flusher.Flush()
+	}
+}
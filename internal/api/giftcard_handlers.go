@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondGiftCardError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidGiftCardAmount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondBookingError(c, err, fallback)
+	}
+}
+
+func (s *Server) handleGetGiftCards(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	cards, err := s.giftCardService.ListPurchased(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch gift cards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cards)
+}
+
+func (s *Server) handlePurchaseGiftCard(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.PurchaseGiftCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	card, err := s.giftCardService.Purchase(userID, req)
+	if err != nil {
+		respondGiftCardError(c, err, "Failed to purchase gift card")
+		return
+	}
+
+	c.JSON(http.StatusCreated, card)
+}
+
+func (s *Server) handleLookupGiftCard(c *gin.Context) {
+	details, err := s.giftCardService.Lookup(c.Param("code"))
+	if err != nil {
+		respondGiftCardError(c, err, "Failed to fetch gift card")
+		return
+	}
+
+	c.JSON(http.StatusOK, details)
+}
+
+func (s *Server) handleGetProviderGiftCards(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	cards, err := s.giftCardService.ListForProvider(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch gift cards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cards)
+}
+
+func (s *Server) handleIssueGiftCard(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.IssueGiftCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	card, err := s.giftCardService.Issue(userID, req)
+	if err != nil {
+		respondGiftCardError(c, err, "Failed to issue gift card")
+		return
+	}
+
+	c.JSON(http.StatusCreated, card)
+}
@@ -2,11 +2,13 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -27,6 +29,10 @@ func (s *Server) handleRegister(c *gin.Context) {
 	}
 
 	user, err := s.authService.Register(req)
+	if errors.Is(err, services.ErrInvalidReferralCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
@@ -402,38 +408,376 @@ func (s *Server) handleDeleteService(c *gin.Context) {
 	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
 }
 
-func (s *Server) handleGetBookings(c *gin.Context) {
+// currentUserID reads the authenticated user set by AuthMiddleware and writes
+// an error response when it is missing.
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
-		return
+		return uuid.Nil, false
 	}
 
 	userID, ok := userIDInterface.(uuid.UUID)
 	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID type"})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// paramUUID parses a UUID path parameter and writes a 400 response when it is
+// malformed.
+func paramUUID(c *gin.Context, name, label string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + label + " ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondBookingError maps booking service errors onto HTTP responses.
+func respondBookingError(c *gin.Context, err error, fallback string) {
+	var conflict *services.SlotConflictError
+	switch {
+	case errors.As(err, &conflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "conflicts": conflict.Conflicts})
+	case errors.Is(err, services.ErrBookingNotFound),
+		errors.Is(err, services.ErrPetNotFound),
+		errors.Is(err, services.ErrServiceNotFound),
+		errors.Is(err, services.ErrCreditNotFound),
+		errors.Is(err, services.ErrGiftCardNotFound),
+		errors.Is(err, services.ErrStaffNotFound),
+		errors.Is(err, services.ErrResourceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOwnerBlocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSlotUnavailable),
+		errors.Is(err, services.ErrNoShowTooEarly),
+		errors.Is(err, services.ErrInvalidTransition),
+		errors.Is(err, services.ErrBookingInactive),
+		errors.Is(err, services.ErrCreditsExhausted),
+		errors.Is(err, services.ErrPromoCodeUsedUp),
+		errors.Is(err, services.ErrInsufficientPoints),
+		errors.Is(err, services.ErrGiftCardEmpty),
+		errors.Is(err, services.ErrStaffUnavailable),
+		errors.Is(err, services.ErrNotInCare):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPaymentFailed):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidRecurrence),
+		errors.Is(err, services.ErrBookingInPast),
+		errors.Is(err, services.ErrPaymentMethodRequired),
+		errors.Is(err, services.ErrRefundTooLarge),
+		errors.Is(err, services.ErrCreditNotApplicable),
+		errors.Is(err, services.ErrPromoCodeInvalid),
+		errors.Is(err, services.ErrPromoNotApplicable),
+		errors.Is(err, services.ErrPointsNotApplicable),
+		errors.Is(err, services.ErrTooManyPoints),
+		errors.Is(err, services.ErrGiftCardNotApplicable),
+		errors.Is(err, services.ErrStaffCannotPerform),
+		errors.Is(err, services.ErrInvalidResourceUse),
+		errors.Is(err, services.ErrInvalidNightRange),
+		errors.Is(err, services.ErrInvalidStay),
+		errors.Is(err, services.ErrStayNotRepeatable),
+		errors.Is(err, services.ErrStayLengthChanged),
+		errors.Is(err, models.ErrCurrencyMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden),
+		errors.Is(err, services.ErrNotProvider):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func (s *Server) handleGetBookings(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	// For now, return an empty list of bookings
-	// In a real implementation, this would query the database for user's bookings
-	_ = userID // Use the userID variable to avoid compiler warning
+	bookings, err := s.bookingService.List(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bookings"})
+		return
+	}
 
-	c.JSON(http.StatusOK, []interface{}{})
+	results := []models.BookingWithDetails{}
+	for _, booking := range bookings {
+		details, err := s.bookingService.Details(booking)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch booking details"})
+			return
+		}
+		results = append(results, *details)
+	}
+
+	c.JSON(http.StatusOK, results)
 }
 
 func (s *Server) handleCreateBooking(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Recurrence != nil {
+		response, err := s.bookingService.CreateRecurring(userID, req)
+		if err != nil {
+			respondBookingError(c, err, "Failed to create recurring booking")
+			return
+		}
+
+		c.JSON(http.StatusCreated, response)
+		return
+	}
+
+	booking, err := s.bookingService.Create(userID, req)
+	if err != nil {
+		respondBookingError(c, err, "Failed to create booking")
+		return
+	}
+
+	details, err := s.bookingService.Details(*booking)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch booking details"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, details)
 }
 
 func (s *Server) handleGetBooking(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	booking, err := s.bookingService.Get(bookingID, userID)
+	if err != nil {
+		respondBookingError(c, err, "Failed to fetch booking")
+		return
+	}
+
+	details, err := s.bookingService.Details(*booking)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch booking details"})
+		return
+	}
+
+	c.JSON(http.StatusOK, details)
+}
+
+func (s *Server) handleGetBookingSeries(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	seriesID, ok := paramUUID(c, "id", "series")
+	if !ok {
+		return
+	}
+
+	series, err := s.bookingService.GetSeries(seriesID, userID)
+	if err != nil {
+		respondBookingError(c, err, "Failed to fetch booking series")
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
 }
 
 func (s *Server) handleUpdateBooking(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Scope == "" {
+		req.Scope = models.ScopeThis
+	}
+	if req.Scope != models.ScopeThis && req.Scope != models.ScopeFollowing {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scope must be 'this' or 'following'"})
+		return
+	}
+
+	booking, err := s.bookingService.Update(bookingID, userID, req)
+	if err != nil {
+		respondBookingError(c, err, "Failed to update booking")
+		return
+	}
+
+	details, err := s.bookingService.Details(*booking)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch booking details"})
+		return
+	}
+
+	c.JSON(http.StatusOK, details)
 }
 
 func (s *Server) handleCancelBooking(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
-}
\ No newline at end of file
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	var req models.CancelBookingRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Scope == "" {
+		req.Scope = models.RecurrenceScope(c.DefaultQuery("scope", string(models.ScopeThis)))
+	}
+	if req.Scope != models.ScopeThis && req.Scope != models.ScopeFollowing {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scope must be 'this' or 'following'"})
+		return
+	}
+
+	result, err := s.bookingService.Cancel(bookingID, userID, req)
+	if err != nil {
+		respondBookingError(c, err, "Failed to cancel booking")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) handleGetCancellationPolicy(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	policy, err := s.bookingService.GetCancellationPolicy(userID)
+	if err != nil {
+		respondBookingError(c, err, "Failed to fetch cancellation policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func (s *Server) handleUpdateCancellationPolicy(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCancellationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := s.bookingService.UpdateCancellationPolicy(userID, req)
+	if err != nil {
+		respondBookingError(c, err, "Failed to update cancellation policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func (s *Server) handleGetTaxSettings(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := s.bookingService.GetTaxSettings(userID)
+	if err != nil {
+		respondBookingError(c, err, "Failed to fetch tax settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (s *Server) handleUpdateTaxSettings(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTaxSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := s.bookingService.UpdateTaxSettings(userID, req)
+	if err != nil {
+		respondBookingError(c, err, "Failed to update tax settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (s *Server) handleMarkNoShow(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	booking, err := s.bookingService.MarkNoShow(bookingID, userID)
+	if err != nil {
+		respondBookingError(c, err, "Failed to mark booking as no-show")
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
+func (s *Server) handleGetProviderCustomers(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	customers, err := s.bookingService.ListCustomers(userID)
+	if err != nil {
+		respondBookingError(c, err, "Failed to fetch customers")
+		return
+	}
+
+	c.JSON(http.StatusOK, customers)
+}
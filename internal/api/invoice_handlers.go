@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondInvoiceError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, services.ErrInvoiceNotAvailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	respondBookingError(c, err, fallback)
+}
+
+func (s *Server) handleGetInvoices(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	invoices, err := s.invoiceService.List(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invoices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
+
+func (s *Server) handleGetBookingInvoice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	invoice, err := s.invoiceService.Get(bookingID, userID)
+	if err != nil {
+		respondInvoiceError(c, err, "Failed to fetch invoice")
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+func (s *Server) handleDownloadBookingInvoice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	invoice, err := s.invoiceService.Get(bookingID, userID)
+	if err != nil {
+		respondInvoiceError(c, err, "Failed to fetch invoice")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, invoice.InvoiceNumber))
+	c.Data(http.StatusOK, "application/pdf", services.RenderInvoicePDF(invoice))
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) handleGetLoyaltyBalance(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	balance, err := s.loyaltyService.Balance(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch loyalty balance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+func (s *Server) handleGetLoyaltyHistory(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	entries, err := s.loyaltyService.History(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch loyalty history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
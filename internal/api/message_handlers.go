@@ -0,0 +1,194 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxMessageWait caps how long a long-polling read is held open.
+const maxMessageWait = 60 * time.Second
+
+func respondMessageError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrAttachmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAttachmentTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEmptyMessage),
+		errors.Is(err, services.ErrTooManyAttachments),
+		errors.Is(err, services.ErrAttachmentType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondBookingError(c, err, fallback)
+	}
+}
+
+// handleGetMessages returns messages after the ?after cursor, the seq of
+// the last message the client has (default the beginning of the thread).
+// With ?wait=N it long-polls for up to N seconds when there is nothing new.
+func (s *Server) handleGetMessages(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	var after int64
+	if raw := c.Query("after"); raw != "" {
+		var err error
+		if after, err = strconv.ParseInt(raw, 10, 64); err != nil || after < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "after must be a message seq"})
+			return
+		}
+	}
+	var wait time.Duration
+	if raw := c.Query("wait"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "wait must be a number of seconds"})
+			return
+		}
+		wait = min(time.Duration(seconds)*time.Second, maxMessageWait)
+	}
+
+	messages, err := s.messageService.List(c.Request.Context(), bookingID, userID, after, wait)
+	if err != nil {
+		respondMessageError(c, err, "Failed to fetch messages")
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+// handleSendMessage accepts either JSON or a multipart form with a "body"
+// field and files under "attachments".
+func (s *Server) handleSendMessage(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	var body string
+	var files []models.NewAttachment
+	if c.ContentType() == "multipart/form-data" {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body,
+			int64(models.MaxMessageAttachments*models.MaxAttachmentSize+1<<20))
+		form, err := c.MultipartForm()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		body = c.PostForm("body")
+		for _, header := range form.File["attachments"] {
+			if header.Size > models.MaxAttachmentSize {
+				respondMessageError(c, services.ErrAttachmentTooLarge, "")
+				return
+			}
+			f, err := header.Open()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			files = append(files, models.NewAttachment{Filename: header.Filename, Data: data})
+		}
+	} else {
+		var req models.SendMessageRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		body = req.Body
+	}
+
+	message, err := s.messageService.Send(bookingID, userID, body, files)
+	if err != nil {
+		respondMessageError(c, err, "Failed to send message")
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+func (s *Server) handleMarkMessagesRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	n, err := s.messageService.MarkRead(bookingID, userID)
+	if err != nil {
+		respondMessageError(c, err, "Failed to mark messages read")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked_read": n})
+}
+
+func (s *Server) handleDownloadAttachment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+	attachmentID, ok := paramUUID(c, "attachmentId", "attachment")
+	if !ok {
+		return
+	}
+
+	attachment, data, err := s.messageService.Attachment(bookingID, attachmentID, userID)
+	if err != nil {
+		respondMessageError(c, err, "Failed to fetch attachment")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename=%q`, attachment.Filename))
+	c.Data(http.StatusOK, attachment.ContentType, data)
+}
+
+func (s *Server) handleGetUnreadMessages(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := s.messageService.Unread(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch unread messages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
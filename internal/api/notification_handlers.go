@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondNotificationError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidBookingLink):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTimeZone),
+		errors.Is(err, services.ErrInvalidQuietHours),
+		errors.Is(err, services.ErrInvalidPreference),
+		errors.Is(err, services.ErrUnsupportedLanguage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondBookingError(c, err, fallback)
+	}
+}
+
+func (s *Server) handleGetNotifications(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	notifications, err := s.notificationService.List(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+func (s *Server) handleGetNotificationPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	prefs, err := s.notificationService.GetPreferences(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+func (s *Server) handleUpdateNotificationPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prefs, err := s.notificationService.UpdatePreferences(userID, req)
+	if err != nil {
+		respondNotificationError(c, err, "Failed to update notification preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+func (s *Server) handleInspectBookingLink(c *gin.Context) {
+	link, err := s.reminderService.InspectLink(c.Param("token"))
+	if err != nil {
+		respondNotificationError(c, err, "Failed to read link")
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+func (s *Server) handleFollowBookingLink(c *gin.Context) {
+	link, err := s.reminderService.FollowLink(c.Param("token"))
+	if err != nil {
+		respondNotificationError(c, err, "Failed to follow link")
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
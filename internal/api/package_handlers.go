@@ -0,0 +1,137 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func respondPackageError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrPackageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidPackage),
+		errors.Is(err, services.ErrInvalidPackagePrice),
+		errors.Is(err, services.ErrInvalidPackageCategory):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondBookingError(c, err, fallback)
+	}
+}
+
+func (s *Server) handleGetPackages(c *gin.Context) {
+	providerID, err := uuid.Parse(c.Query("provider_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider_id is required"})
+		return
+	}
+
+	packages, err := s.packageService.List(providerID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch packages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, packages)
+}
+
+func (s *Server) handlePurchasePackage(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	packageID, ok := paramUUID(c, "id", "package")
+	if !ok {
+		return
+	}
+
+	var req models.PurchasePackageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	credit, err := s.packageService.Purchase(packageID, userID, req)
+	if err != nil {
+		respondPackageError(c, err, "Failed to purchase package")
+		return
+	}
+
+	c.JSON(http.StatusCreated, credit)
+}
+
+func (s *Server) handleGetCredits(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	credits, err := s.packageService.ListCredits(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch credits"})
+		return
+	}
+
+	c.JSON(http.StatusOK, credits)
+}
+
+func (s *Server) handleGetProviderPackages(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	packages, err := s.packageService.List(userID, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch packages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, packages)
+}
+
+func (s *Server) handleCreatePackage(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreatePackageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pkg, err := s.packageService.Create(userID, req)
+	if err != nil {
+		respondPackageError(c, err, "Failed to create package")
+		return
+	}
+
+	c.JSON(http.StatusCreated, pkg)
+}
+
+func (s *Server) handleDeactivatePackage(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	packageID, ok := paramUUID(c, "id", "package")
+	if !ok {
+		return
+	}
+
+	if err := s.packageService.Deactivate(packageID, userID); err != nil {
+		respondPackageError(c, err, "Failed to deactivate package")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Package withdrawn from sale"})
+}
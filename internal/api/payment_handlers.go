@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) handleGetBookingPayments(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	result, err := s.paymentService.List(bookingID, userID)
+	if err != nil {
+		respondBookingError(c, err, "Failed to fetch payments")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) handleRefundBooking(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	var req models.RefundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	result, err := s.paymentService.Refund(bookingID, userID, req)
+	if err != nil {
+		respondBookingError(c, err, "Failed to refund booking")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
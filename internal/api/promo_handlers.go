@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondPromoError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrPromoCodeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPromoCodeExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidDiscount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondBookingError(c, err, fallback)
+	}
+}
+
+func (s *Server) handleGetPromoCodes(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	codes, err := s.bookingService.ListPromoCodes(userID)
+	if err != nil {
+		respondPromoError(c, err, "Failed to fetch promo codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+func (s *Server) handleCreatePromoCode(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promo, err := s.bookingService.CreatePromoCode(userID, req)
+	if err != nil {
+		respondPromoError(c, err, "Failed to create promo code")
+		return
+	}
+
+	c.JSON(http.StatusCreated, promo)
+}
+
+func (s *Server) handleDeactivatePromoCode(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	promoID, ok := paramUUID(c, "id", "promo code")
+	if !ok {
+		return
+	}
+
+	if err := s.bookingService.DeactivatePromoCode(promoID, userID); err != nil {
+		respondPromoError(c, err, "Failed to deactivate promo code")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promo code deactivated"})
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) handleGetResources(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	resources, err := s.resourceService.List(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch resources"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resources)
+}
+
+func (s *Server) handleCreateResource(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resource, err := s.resourceService.Create(userID, req)
+	if err != nil {
+		respondBookingError(c, err, "Failed to create resource")
+		return
+	}
+
+	c.JSON(http.StatusCreated, resource)
+}
+
+func (s *Server) handleUpdateResource(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	resourceID, ok := paramUUID(c, "id", "resource")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resource, err := s.resourceService.Update(resourceID, userID, req)
+	if err != nil {
+		respondBookingError(c, err, "Failed to update resource")
+		return
+	}
+
+	c.JSON(http.StatusOK, resource)
+}
+
+func (s *Server) handleRetireResource(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	resourceID, ok := paramUUID(c, "id", "resource")
+	if !ok {
+		return
+	}
+
+	if err := s.resourceService.Retire(resourceID, userID); err != nil {
+		respondBookingError(c, err, "Failed to retire resource")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Resource retired"})
+}
+
+func (s *Server) handleGetServiceResources(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	serviceID, ok := paramUUID(c, "id", "service")
+	if !ok {
+		return
+	}
+
+	uses, err := s.resourceService.ServiceResources(serviceID, userID)
+	if err != nil {
+		respondBookingError(c, err, "Failed to fetch service resources")
+		return
+	}
+
+	c.JSON(http.StatusOK, uses)
+}
+
+func (s *Server) handleSetServiceResources(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	serviceID, ok := paramUUID(c, "id", "service")
+	if !ok {
+		return
+	}
+
+	var req models.SetServiceResourcesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uses, err := s.resourceService.SetServiceResources(serviceID, userID, req.Resources)
+	if err != nil {
+		respondBookingError(c, err, "Failed to update service resources")
+		return
+	}
+
+	c.JSON(http.StatusOK, uses)
+}
+
+// defaultOccupancyNights is how many nights occupancy covers without a to
+// date.
+const defaultOccupancyNights = 30
+
+// handleGetResourceOccupancy reports a resource's bookings night by night
+// between the optional from and to dates, formatted 2006-01-02.
+func (s *Server) handleGetResourceOccupancy(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	resourceID, ok := paramUUID(c, "id", "resource")
+	if !ok {
+		return
+	}
+
+	from := time.Now()
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+			return
+		}
+		from = parsed
+	}
+	to := from.AddDate(0, 0, defaultOccupancyNights)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+			return
+		}
+		to = parsed
+	}
+
+	nights, err := s.resourceService.Occupancy(resourceID, userID, from, to)
+	if err != nil {
+		respondBookingError(c, err, "Failed to fetch occupancy")
+		return
+	}
+
+	c.JSON(http.StatusOK, nights)
+}
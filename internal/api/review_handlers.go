@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (s *Server) handleCreateReview(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	var req models.CreateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := s.reviewService.Create(bookingID, userID, req)
+	switch {
+	case errors.Is(err, services.ErrReviewNotAllowed),
+		errors.Is(err, services.ErrReviewExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		respondBookingError(c, err, "Failed to create review")
+		return
+	}
+
+	c.JSON(http.StatusCreated, review)
+}
+
+func (s *Server) handleGetReviews(c *gin.Context) {
+	providerID, err := uuid.Parse(c.Query("provider_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider_id is required"})
+		return
+	}
+
+	reviews, err := s.reviewService.List(providerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
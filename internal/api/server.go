@@ -1,36 +1,130 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
 
 	"pet-grooming-app/internal/config"
 	"pet-grooming-app/internal/middleware"
+	"pet-grooming-app/internal/notify"
+	"pet-grooming-app/internal/payments"
+	"pet-grooming-app/internal/realtime"
+	"pet-grooming-app/internal/secrets"
 	"pet-grooming-app/internal/services"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// notificationInterval is how often the outbox is checked for due
+	// messages.
+	notificationInterval = 15 * time.Second
+	// reminderInterval is how often bookings are checked for due reminders.
+	reminderInterval = time.Minute
+	// webhookInterval is how often queued webhook deliveries are sent.
+	webhookInterval = 15 * time.Second
+	// waitlistInterval is how often lapsed waitlist holds are passed on.
+	waitlistInterval = time.Minute
+	// loyaltyInterval is how often lapsed loyalty points are written off.
+	loyaltyInterval = time.Hour
+	// calendarSyncInterval is how often connected calendars are checked for
+	// a due sync.
+	calendarSyncInterval = time.Minute
+	// subscribeRetryMin and subscribeRetryMax bound the backoff between
+	// attempts to resubscribe to events from other instances.
+	subscribeRetryMin = time.Second
+	subscribeRetryMax = time.Minute
+)
+
 type Server struct {
 	router      *gin.Engine
 	db          *sql.DB
 	config      *config.Config
 	authService *services.AuthService
+
+	bookingService  *services.BookingService
+	waitlistService *services.WaitlistService
+	paymentService  *services.PaymentService
+	invoiceService  *services.InvoiceService
+	tipService      *services.TipService
+	packageService  *services.PackageService
+	loyaltyService  *services.LoyaltyService
+	reviewService   *services.ReviewService
+	giftCardService *services.GiftCardService
+
+	notificationService *services.NotificationService
+	reminderService     *services.ReminderService
+	messageService      *services.MessageService
+	webhookService      *services.WebhookService
+	calendarService     *services.CalendarService
+	calendarSyncService *services.CalendarSyncService
+	staffService        *services.StaffService
+	resourceService     *services.ResourceService
+	careLogService      *services.CareLogService
+	walkService         *services.WalkService
+
+	events realtime.PubSub
+	hub    *realtime.Hub
 }
 
-func NewServer(db *sql.DB, cfg *config.Config) *Server {
+// NewServer wires the services together. It fails when the configuration
+// names a payment provider or notification sender that is not bundled.
+func NewServer(db *sql.DB, cfg *config.Config) (*Server, error) {
+	provider, err := newPaymentProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	notificationService, err := newNotificationService(db, cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	router := gin.Default()
 	authService := services.NewAuthService(db, cfg.JWTSecret)
+	paymentService := services.NewPaymentService(db, provider)
+	invoiceService := services.NewInvoiceService(db)
+	loyaltyService := services.NewLoyaltyService(db)
+	events := newPubSub(db, cfg)
+	bookingService := services.NewBookingService(db, paymentService, invoiceService, loyaltyService, events)
 
 	server := &Server{
 		router:      router,
 		db:          db,
 		config:      cfg,
 		authService: authService,
+
+		bookingService:  bookingService,
+		waitlistService: services.NewWaitlistService(db, paymentService, events),
+		paymentService:  paymentService,
+		invoiceService:  invoiceService,
+		tipService:      services.NewTipService(db, paymentService, invoiceService),
+		packageService:  services.NewPackageService(db, paymentService),
+		loyaltyService:  loyaltyService,
+		reviewService:   services.NewReviewService(db),
+		giftCardService: services.NewGiftCardService(db, paymentService),
+
+		notificationService: notificationService,
+		reminderService:     services.NewReminderService(db, bookingService, []byte(cfg.JWTSecret), cfg.PublicURL),
+		messageService:      services.NewMessageService(db),
+		webhookService:      services.NewWebhookService(db),
+		calendarService:     services.NewCalendarService(db, cfg.PublicURL),
+		calendarSyncService: services.NewCalendarSyncService(db, secrets.NewBox(cfg.CredentialsKey), cfg.CalDAVAllowPrivate),
+		staffService:        services.NewStaffService(db),
+		resourceService:     services.NewResourceService(db),
+		careLogService:      services.NewCareLogService(db),
+		walkService:         services.NewWalkService(db, events),
+
+		events: events,
+		hub:    realtime.NewHub(),
 	}
 
 	server.setupRoutes()
-	return server
+	return server, nil
 }
 
 func (s *Server) setupRoutes() {
@@ -42,6 +136,11 @@ func (s *Server) setupRoutes() {
 	config.AllowCredentials = true
 	s.router.Use(cors.New(config))
 
+	// Reminder links open a page that shows the booking and posts the
+	// action back, so following one needs nothing but a browser
+	s.router.GET("/booking-links/:token", s.handleBookingLinkPage)
+	s.router.POST("/booking-links/:token", s.handleBookingLinkPageAction)
+
 	api := s.router.Group("/api/v1")
 
 	// Public routes
@@ -51,6 +150,20 @@ func (s *Server) setupRoutes() {
 		auth.POST("/login", s.handleLogin)
 	}
 
+	// Signed links from reminders act for the owner without a login
+	bookingLinks := api.Group("/booking-links")
+	{
+		bookingLinks.GET("/:token", s.handleInspectBookingLink)
+		bookingLinks.POST("/:token", s.handleFollowBookingLink)
+	}
+
+	// Calendar feeds are read by calendar apps, which cannot log in; the
+	// secret token in the URL stands in for the user
+	api.GET("/calendar/:token", s.handleCalendarFeed)
+
+	// Event stream, which also accepts a stream token as a query parameter
+	api.GET("/events", middleware.StreamAuthMiddleware(s.authService), s.handleEventStream)
+
 	// Protected routes
 	protected := api.Group("/")
 	protected.Use(middleware.AuthMiddleware(s.authService))
@@ -60,8 +173,14 @@ func (s *Server) setupRoutes() {
 		{
 			users.GET("/profile", s.handleGetProfile)
 			users.PUT("/profile", s.handleUpdateProfile)
+			users.GET("/notification-preferences", s.handleGetNotificationPreferences)
+			users.PUT("/notification-preferences", s.handleUpdateNotificationPreferences)
+			users.GET("/calendar-feed", s.handleGetCalendarFeed)
+			users.POST("/calendar-feed/reset", s.handleResetCalendarFeed)
 		}
 
+		protected.POST("/events/token", s.handleCreateStreamToken)
+
 		// Pet routes
 		pets := protected.Group("/pets")
 		{
@@ -80,6 +199,7 @@ func (s *Server) setupRoutes() {
 			services.GET("", s.handleGetServices)   // Accept /services without trailing slash
 			services.GET("/", s.handleGetServices)  // Accept /services/ with trailing slash
 			services.GET("/:id", s.handleGetService)
+			services.GET("/:id/staff", s.handleGetServiceStaff)
 		}
 
 		// Provider routes (for service providers)
@@ -89,6 +209,48 @@ func (s *Server) setupRoutes() {
 			provider.POST("/", s.handleCreateService)
 			provider.PUT("/:id", s.handleUpdateService)
 			provider.DELETE("/:id", s.handleDeleteService)
+			provider.GET("/:id/resources", s.handleGetServiceResources)
+			provider.PUT("/:id/resources", s.handleSetServiceResources)
+		}
+
+		// Provider settings
+		providerSettings := protected.Group("/provider")
+		{
+			providerSettings.GET("/cancellation-policy", s.handleGetCancellationPolicy)
+			providerSettings.PUT("/cancellation-policy", s.handleUpdateCancellationPolicy)
+			providerSettings.GET("/tax-settings", s.handleGetTaxSettings)
+			providerSettings.PUT("/tax-settings", s.handleUpdateTaxSettings)
+			providerSettings.GET("/customers", s.handleGetProviderCustomers)
+			providerSettings.GET("/reports/tips", s.handleGetTipReport)
+			providerSettings.GET("/packages", s.handleGetProviderPackages)
+			providerSettings.POST("/packages", s.handleCreatePackage)
+			providerSettings.DELETE("/packages/:id", s.handleDeactivatePackage)
+			providerSettings.GET("/promo-codes", s.handleGetPromoCodes)
+			providerSettings.POST("/promo-codes", s.handleCreatePromoCode)
+			providerSettings.DELETE("/promo-codes/:id", s.handleDeactivatePromoCode)
+			providerSettings.GET("/gift-cards", s.handleGetProviderGiftCards)
+			providerSettings.POST("/gift-cards", s.handleIssueGiftCard)
+			providerSettings.GET("/webhooks", s.handleGetWebhooks)
+			providerSettings.POST("/webhooks", s.handleCreateWebhook)
+			providerSettings.DELETE("/webhooks/:id", s.handleDeleteWebhook)
+			providerSettings.GET("/webhooks/:id/deliveries", s.handleGetWebhookDeliveries)
+			providerSettings.POST("/webhooks/:id/test", s.handleTestWebhook)
+			providerSettings.GET("/calendar-sync", s.handleGetCalendarConnection)
+			providerSettings.PUT("/calendar-sync", s.handleConnectCalendar)
+			providerSettings.DELETE("/calendar-sync", s.handleDisconnectCalendar)
+			providerSettings.POST("/calendar-sync/sync", s.handleSyncCalendar)
+			providerSettings.GET("/busy-blocks", s.handleGetBusyBlocks)
+			providerSettings.GET("/organization", s.handleGetOrganization)
+			providerSettings.PUT("/organization", s.handleSaveOrganization)
+			providerSettings.GET("/staff", s.handleGetStaff)
+			providerSettings.POST("/staff", s.handleAddStaff)
+			providerSettings.PUT("/staff/:id", s.handleUpdateStaff)
+			providerSettings.DELETE("/staff/:id", s.handleRemoveStaff)
+			providerSettings.GET("/resources", s.handleGetResources)
+			providerSettings.POST("/resources", s.handleCreateResource)
+			providerSettings.PUT("/resources/:id", s.handleUpdateResource)
+			providerSettings.DELETE("/resources/:id", s.handleRetireResource)
+			providerSettings.GET("/resources/:id/occupancy", s.handleGetResourceOccupancy)
 		}
 
 		// Booking routes
@@ -98,9 +260,72 @@ func (s *Server) setupRoutes() {
 			bookings.GET("/", s.handleGetBookings)    // Accept /bookings/ with trailing slash
 			bookings.POST("", s.handleCreateBooking)  // Accept /bookings without trailing slash
 			bookings.POST("/", s.handleCreateBooking) // Accept /bookings/ with trailing slash
+			bookings.GET("/series/:id", s.handleGetBookingSeries)
 			bookings.GET("/:id", s.handleGetBooking)
 			bookings.PUT("/:id", s.handleUpdateBooking)
 			bookings.DELETE("/:id", s.handleCancelBooking)
+			bookings.POST("/:id/no-show", s.handleMarkNoShow)
+			bookings.GET("/:id/payments", s.handleGetBookingPayments)
+			bookings.POST("/:id/refund", s.handleRefundBooking)
+			bookings.GET("/:id/invoice", s.handleGetBookingInvoice)
+			bookings.GET("/:id/invoice/pdf", s.handleDownloadBookingInvoice)
+			bookings.GET("/:id/calendar.ics", s.handleDownloadBookingCalendar)
+			bookings.GET("/:id/tip", s.handleGetTip)
+			bookings.POST("/:id/tip", s.handleAddTip)
+			bookings.POST("/:id/review", s.handleCreateReview)
+			bookings.GET("/:id/care-log", s.handleGetCareLog)
+			bookings.POST("/:id/care-log", s.handleAddCareLog)
+			bookings.GET("/:id/walk", s.handleGetWalk)
+			bookings.GET("/:id/walk/geojson", s.handleGetWalkGeoJSON)
+			bookings.POST("/:id/walk/start", s.handleStartWalk)
+			bookings.POST("/:id/walk/track", s.handleRecordWalk)
+			bookings.POST("/:id/walk/stop", s.handleStopWalk)
+			bookings.GET("/:id/messages", s.handleGetMessages)
+			bookings.POST("/:id/messages", s.handleSendMessage)
+			bookings.POST("/:id/messages/read", s.handleMarkMessagesRead)
+			bookings.GET("/:id/messages/attachments/:attachmentId", s.handleDownloadAttachment)
+		}
+
+		// Waitlist routes
+		waitlist := protected.Group("/waitlist")
+		{
+			waitlist.GET("", s.handleGetWaitlist)
+			waitlist.POST("", s.handleJoinWaitlist)
+			waitlist.POST("/:id/accept", s.handleAcceptWaitlistOffer)
+			waitlist.POST("/:id/decline", s.handleDeclineWaitlistOffer)
+			waitlist.DELETE("/:id", s.handleLeaveWaitlist)
+		}
+
+		// Invoice routes
+		protected.GET("/invoices", s.handleGetInvoices)
+
+		// Package and credit routes
+		protected.GET("/packages", s.handleGetPackages)
+		protected.POST("/packages/:id/purchase", s.handlePurchasePackage)
+		protected.GET("/credits", s.handleGetCredits)
+
+		// Gift card routes
+		giftCards := protected.Group("/gift-cards")
+		{
+			giftCards.GET("", s.handleGetGiftCards)
+			giftCards.POST("", s.handlePurchaseGiftCard)
+			giftCards.GET("/lookup/:code", s.handleLookupGiftCard)
+		}
+
+		// Message routes
+		protected.GET("/messages/unread", s.handleGetUnreadMessages)
+
+		// Notification routes
+		protected.GET("/notifications", s.handleGetNotifications)
+
+		// Review routes
+		protected.GET("/reviews", s.handleGetReviews)
+
+		// Loyalty routes
+		loyalty := protected.Group("/loyalty")
+		{
+			loyalty.GET("", s.handleGetLoyaltyBalance)
+			loyalty.GET("/history", s.handleGetLoyaltyHistory)
 		}
 	}
 
@@ -108,6 +333,71 @@ func (s *Server) setupRoutes() {
 	s.router.GET("/health", s.handleHealthCheck)
 }
 
+// newPaymentProvider selects the card processor. Only the in-memory fake is
+// bundled; real processors implement payments.Provider and are added here.
+// The fake accepts any card and forgets its transactions on restart, so it
+// must be asked for by name and only runs in development.
+func newPaymentProvider(cfg *config.Config) (payments.Provider, error) {
+	switch cfg.PaymentProvider {
+	case "fake":
+		if cfg.Environment != "development" {
+			return nil, fmt.Errorf("the fake payment provider only runs in development, not %q", cfg.Environment)
+		}
+		return payments.NewFakeProvider(), nil
+	case "":
+		return nil, errors.New("PAYMENT_PROVIDER is not set")
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", cfg.PaymentProvider)
+	}
+}
+
+// newNotificationService wires the outbox dispatcher to its senders. Only the
+// logging fake is bundled; real email, SMS and push gateways implement the
+// notify sender interfaces and are added here. The fake only logs messages
+// that the outbox then records as sent, so like the fake payment provider it
+// must be asked for by name and only runs in development.
+func newNotificationService(db *sql.DB, cfg *config.Config) (*services.NotificationService, error) {
+	switch cfg.NotificationSender {
+	case "fake":
+		if cfg.Environment != "development" {
+			return nil, fmt.Errorf("the fake notification sender only runs in development, not %q", cfg.Environment)
+		}
+		sender := notify.NewFakeSender()
+		return services.NewNotificationService(db, sender, sender, sender), nil
+	case "":
+		return nil, errors.New("NOTIFICATION_SENDER is not set")
+	default:
+		return nil, fmt.Errorf("unknown notification sender %q", cfg.NotificationSender)
+	}
+}
+
+// newPubSub selects how booking events reach the other server instances.
+// "postgres" fans them out with LISTEN/NOTIFY; "memory", the default, only
+// reaches clients connected to this instance.
+func newPubSub(db *sql.DB, cfg *config.Config) realtime.PubSub {
+	switch cfg.PubSub {
+	case "postgres":
+		if db != nil {
+			return realtime.NewPostgresPubSub(db, cfg.DatabaseURL)
+		}
+	case "memory":
+	default:
+		log.Printf("Unknown pub/sub backend %q, using in-memory pub/sub", cfg.PubSub)
+	}
+	return realtime.NewMemoryPubSub()
+}
+
+// Run starts the background workers, when there is a database for them, and
+// serves HTTP on addr.
 func (s *Server) Run(addr string) error {
+	go realtime.Listen(context.Background(), s.events, s.hub.Deliver, subscribeRetryMin, subscribeRetryMax)
+	if s.db != nil {
+		go s.notificationService.Run(context.Background(), notificationInterval)
+		go s.reminderService.Run(context.Background(), reminderInterval)
+		go s.webhookService.Run(context.Background(), webhookInterval)
+		go s.calendarSyncService.Run(context.Background(), calendarSyncInterval)
+		go s.waitlistService.Run(context.Background(), waitlistInterval)
+		go s.loyaltyService.Run(context.Background(), loyaltyInterval)
+	}
 	return s.router.Run(addr)
 }
\ No newline at end of file
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"testing"
+
+	"pet-grooming-app/internal/config"
+)
+
+func TestNewPaymentProvider(t *testing.T) {
+	tests := []struct {
+		provider, environment string
+		ok                    bool
+	}{
+		{"fake", "development", true},
+		{"fake", "production", false},
+		{"", "development", false},
+		{"stripe-typo", "development", false},
+	}
+	for _, tt := range tests {
+		_, err := newPaymentProvider(&config.Config{PaymentProvider: tt.provider, Environment: tt.environment})
+		if (err == nil) != tt.ok {
+			t.Errorf("provider %q in %s: error %v", tt.provider, tt.environment, err)
+		}
+	}
+}
+
+func TestNewNotificationService(t *testing.T) {
+	tests := []struct {
+		sender, environment string
+		ok                  bool
+	}{
+		{"fake", "development", true},
+		{"fake", "production", false},
+		{"", "development", false},
+		{"smtp-typo", "development", false},
+	}
+	for _, tt := range tests {
+		_, err := newNotificationService(nil, &config.Config{NotificationSender: tt.sender, Environment: tt.environment})
+		if (err == nil) != tt.ok {
+			t.Errorf("sender %q in %s: error %v", tt.sender, tt.environment, err)
+		}
+	}
+}
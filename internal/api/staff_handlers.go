@@ -0,0 +1,168 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondStaffError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrOrganizationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidSchedule),
+		errors.Is(err, services.ErrInvalidTimeZone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondBookingError(c, err, fallback)
+	}
+}
+
+func (s *Server) handleGetOrganization(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	org, err := s.staffService.GetOrganization(userID)
+	if err != nil {
+		respondStaffError(c, err, "Failed to fetch organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+func (s *Server) handleSaveOrganization(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SaveOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	org, err := s.staffService.SaveOrganization(userID, req)
+	if err != nil {
+		respondStaffError(c, err, "Failed to save organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+func (s *Server) handleGetStaff(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	staff, err := s.staffService.ListStaff(userID)
+	if err != nil {
+		respondStaffError(c, err, "Failed to fetch staff")
+		return
+	}
+
+	c.JSON(http.StatusOK, staff)
+}
+
+func (s *Server) handleAddStaff(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := s.staffService.AddStaff(userID, req)
+	if err != nil {
+		respondStaffError(c, err, "Failed to add staff member")
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+func (s *Server) handleUpdateStaff(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	staffID, ok := paramUUID(c, "id", "staff")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := s.staffService.UpdateStaff(staffID, userID, req)
+	if err != nil {
+		respondStaffError(c, err, "Failed to update staff member")
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+func (s *Server) handleRemoveStaff(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	staffID, ok := paramUUID(c, "id", "staff")
+	if !ok {
+		return
+	}
+
+	if err := s.staffService.RemoveStaff(staffID, userID); err != nil {
+		respondStaffError(c, err, "Failed to remove staff member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Staff member removed"})
+}
+
+// handleGetServiceStaff lists the groomers offering a service, marking who
+// is free at the optional RFC 3339 at time.
+func (s *Server) handleGetServiceStaff(c *gin.Context) {
+	serviceID, ok := paramUUID(c, "id", "service")
+	if !ok {
+		return
+	}
+
+	var at *time.Time
+	if value := c.Query("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid at time"})
+			return
+		}
+		at = &parsed
+	}
+
+	staff, err := s.staffService.ServiceStaff(serviceID, at)
+	if err != nil {
+		respondStaffError(c, err, "Failed to fetch staff")
+		return
+	}
+
+	c.JSON(http.StatusOK, staff)
+}
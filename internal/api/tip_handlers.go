@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultReportPeriod is covered by reports requested without a start date.
+const defaultReportPeriod = 30 * 24 * time.Hour
+
+func respondTipError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrTipNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTipNotAllowed),
+		errors.Is(err, services.ErrTipExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTip),
+		errors.Is(err, services.ErrTipStaffFixed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondBookingError(c, err, fallback)
+	}
+}
+
+// reportPeriod reads the optional RFC 3339 from and to query parameters,
+// defaulting to the defaultReportPeriod up to now.
+func reportPeriod(c *gin.Context) (time.Time, time.Time, bool) {
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultReportPeriod)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+	return from, to, true
+}
+
+func (s *Server) handleAddTip(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	var req models.AddTipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tip, err := s.tipService.Add(bookingID, userID, req)
+	if err != nil {
+		respondTipError(c, err, "Failed to add tip")
+		return
+	}
+
+	c.JSON(http.StatusCreated, tip)
+}
+
+func (s *Server) handleGetTip(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	tip, err := s.tipService.Get(bookingID, userID)
+	if err != nil {
+		respondTipError(c, err, "Failed to fetch tip")
+		return
+	}
+
+	c.JSON(http.StatusOK, tip)
+}
+
+func (s *Server) handleGetTipReport(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := reportPeriod(c)
+	if !ok {
+		return
+	}
+
+	report, err := s.tipService.Report(userID, from, to)
+	if err != nil {
+		respondTipError(c, err, "Failed to build tip report")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
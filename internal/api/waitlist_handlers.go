@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondWaitlistError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrWaitlistEntryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOfferNotActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidWindow):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondBookingError(c, err, fallback)
+	}
+}
+
+func (s *Server) handleGetWaitlist(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	entries, err := s.waitlistService.List(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (s *Server) handleJoinWaitlist(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateWaitlistEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := s.waitlistService.Join(userID, req)
+	if err != nil {
+		respondWaitlistError(c, err, "Failed to join waitlist")
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (s *Server) handleAcceptWaitlistOffer(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	entryID, ok := paramUUID(c, "id", "waitlist entry")
+	if !ok {
+		return
+	}
+
+	var req models.AcceptWaitlistOfferRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	booking, err := s.waitlistService.Accept(entryID, userID, req.PaymentMethod)
+	if err != nil {
+		respondWaitlistError(c, err, "Failed to accept waitlist offer")
+		return
+	}
+
+	c.JSON(http.StatusCreated, booking)
+}
+
+func (s *Server) handleDeclineWaitlistOffer(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	entryID, ok := paramUUID(c, "id", "waitlist entry")
+	if !ok {
+		return
+	}
+
+	entry, err := s.waitlistService.Decline(entryID, userID)
+	if err != nil {
+		respondWaitlistError(c, err, "Failed to decline waitlist offer")
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+func (s *Server) handleLeaveWaitlist(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	entryID, ok := paramUUID(c, "id", "waitlist entry")
+	if !ok {
+		return
+	}
+
+	if err := s.waitlistService.Withdraw(entryID, userID); err != nil {
+		respondWaitlistError(c, err, "Failed to leave waitlist")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Removed from waitlist"})
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"pet-grooming-app/internal/geo"
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondWalkError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrWalkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrWalkAlreadyStarted),
+		errors.Is(err, services.ErrWalkEnded):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotWalk):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondBookingError(c, err, fallback)
+	}
+}
+
+func (s *Server) handleGetWalk(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	walk, err := s.walkService.Get(bookingID, userID)
+	if err != nil {
+		respondWalkError(c, err, "Failed to fetch walk")
+		return
+	}
+
+	c.JSON(http.StatusOK, walk)
+}
+
+func (s *Server) handleGetWalkGeoJSON(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	fc, err := s.walkService.GeoJSON(bookingID, userID)
+	if err != nil {
+		respondWalkError(c, err, "Failed to fetch walk")
+		return
+	}
+
+	data, err := json.Marshal(fc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode walk"})
+		return
+	}
+
+	c.Data(http.StatusOK, geo.ContentType, data)
+}
+
+func (s *Server) handleStartWalk(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	walk, err := s.walkService.Start(bookingID, userID)
+	if err != nil {
+		respondWalkError(c, err, "Failed to start walk")
+		return
+	}
+
+	c.JSON(http.StatusCreated, walk)
+}
+
+func (s *Server) handleRecordWalk(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	var req models.RecordWalkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	walk, err := s.walkService.Record(bookingID, userID, req)
+	if err != nil {
+		respondWalkError(c, err, "Failed to record walk")
+		return
+	}
+
+	c.JSON(http.StatusOK, walk)
+}
+
+func (s *Server) handleStopWalk(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, ok := paramUUID(c, "id", "booking")
+	if !ok {
+		return
+	}
+
+	walk, err := s.walkService.Stop(bookingID, userID)
+	if err != nil {
+		respondWalkError(c, err, "Failed to stop walk")
+		return
+	}
+
+	c.JSON(http.StatusOK, walk)
+}
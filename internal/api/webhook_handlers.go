@@ -0,0 +1,119 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondWebhookError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidWebhookURL),
+		errors.Is(err, services.ErrWebhookURLNotPublic),
+		errors.Is(err, services.ErrInvalidWebhookEvent):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondBookingError(c, err, fallback)
+	}
+}
+
+func (s *Server) handleGetWebhooks(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	webhooks, err := s.webhookService.List(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+func (s *Server) handleCreateWebhook(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := s.webhookService.Create(userID, req)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+func (s *Server) handleDeleteWebhook(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	webhookID, ok := paramUUID(c, "id", "webhook")
+	if !ok {
+		return
+	}
+
+	if err := s.webhookService.Delete(webhookID, userID); err != nil {
+		respondWebhookError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+func (s *Server) handleGetWebhookDeliveries(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	webhookID, ok := paramUUID(c, "id", "webhook")
+	if !ok {
+		return
+	}
+
+	deliveries, err := s.webhookService.Deliveries(webhookID, userID)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to fetch webhook deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (s *Server) handleTestWebhook(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	webhookID, ok := paramUUID(c, "id", "webhook")
+	if !ok {
+		return
+	}
+
+	delivery, err := s.webhookService.Test(c.Request.Context(), webhookID, userID)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to send test delivery")
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
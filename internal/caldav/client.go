@@ -0,0 +1,225 @@
+// Package caldav is a small CalDAV (RFC 4791) client: enough to read the
+// events in one calendar collection over a time range and to write and
+// remove events in it.
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"pet-grooming-app/internal/ical"
+)
+
+var (
+	ErrUnauthorized = errors.New("calendar server rejected the credentials")
+	ErrNotCalendar  = errors.New("URL is not a calendar collection")
+)
+
+// RequestTimeout bounds each request to the calendar server.
+const RequestTimeout = 30 * time.Second
+
+// Client talks to one calendar collection, such as
+// https://dav.example.com/calendars/alice/work/.
+type Client struct {
+	collection *url.URL
+	username   string
+	password   string
+	http       *http.Client
+}
+
+// NewClient returns a client for the collection at calendarURL using HTTP
+// basic authentication. Requests go through httpClient, or a plain client
+// with RequestTimeout when it is nil.
+func NewClient(httpClient *http.Client, calendarURL, username, password string) (*Client, error) {
+	u, err := url.Parse(calendarURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrNotCalendar
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: RequestTimeout}
+	}
+	return &Client{
+		collection: u,
+		username:   username,
+		password:   password,
+		http:       httpClient,
+	}, nil
+}
+
+func (c *Client) do(ctx context.Context, method, target string, headers map[string]string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		resp.Body.Close()
+		return nil, ErrUnauthorized
+	}
+	return resp, nil
+}
+
+// Check confirms the URL is a calendar collection the credentials can read.
+func (c *Client) Check(ctx context.Context) error {
+	body := []byte(`<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:"><d:prop><d:resourcetype/></d:prop></d:propfind>`)
+	resp, err := c.do(ctx, "PROPFIND", c.collection.String(), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+		"Depth":        "0",
+	}, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return fmt.Errorf("%w: server responded %s", ErrNotCalendar, resp.Status)
+	}
+
+	var result struct {
+		Responses []struct {
+			Propstat []struct {
+				Prop struct {
+					ResourceType struct {
+						Calendar *struct{} `xml:"urn:ietf:params:xml:ns:caldav calendar"`
+					} `xml:"resourcetype"`
+				} `xml:"prop"`
+			} `xml:"propstat"`
+		} `xml:"response"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return err
+	}
+	for _, r := range result.Responses {
+		for _, ps := range r.Propstat {
+			if ps.Prop.ResourceType.Calendar != nil {
+				return nil
+			}
+		}
+	}
+	return ErrNotCalendar
+}
+
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Prop struct {
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// Events returns the events overlapping [start, end). Recurring events are
+// expanded by the server into one event per occurrence. Resources that do
+// not parse are logged and skipped, so one odd event written by another app
+// does not hide the rest.
+func (c *Client) Events(ctx context.Context, start, end time.Time) ([]ical.Event, error) {
+	const format = "20060102T150405Z"
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop>
+    <c:calendar-data>
+      <c:expand start="%[1]s" end="%[2]s"/>
+    </c:calendar-data>
+  </d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VEVENT">
+        <c:time-range start="%[1]s" end="%[2]s"/>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`, start.UTC().Format(format), end.UTC().Format(format))
+
+	resp, err := c.do(ctx, "REPORT", c.collection.String(), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+		"Depth":        "1",
+	}, []byte(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("calendar query failed: %s", resp.Status)
+	}
+
+	var result multistatus
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 16<<20)).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	var events []ical.Event
+	for _, r := range result.Responses {
+		for _, ps := range r.Propstat {
+			if ps.Prop.CalendarData == "" {
+				continue
+			}
+			parsed, err := ical.Parse([]byte(ps.Prop.CalendarData))
+			if err != nil {
+				log.Printf("Skipping unreadable calendar event %s: %v", r.Href, err)
+				continue
+			}
+			events = append(events, parsed...)
+		}
+	}
+	return events, nil
+}
+
+// resource returns the URL of the event stored under name in the collection.
+func (c *Client) resource(name string) string {
+	return c.collection.ResolveReference(&url.URL{Path: name + ".ics"}).String()
+}
+
+// Put stores calendar under name, creating or replacing it.
+func (c *Client) Put(ctx context.Context, name string, calendar *ical.Calendar) error {
+	resp, err := c.do(ctx, http.MethodPut, c.resource(name), map[string]string{
+		"Content-Type": "text/calendar; charset=utf-8",
+	}, calendar.Bytes())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("storing event failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// Delete removes the event stored under name. Removing an event that is not
+// there is not an error.
+func (c *Client) Delete(ctx context.Context, name string) error {
+	resp, err := c.do(ctx, http.MethodDelete, c.resource(name), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("deleting event failed: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package caldav
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"pet-grooming-app/internal/ical"
+
+	"github.com/google/uuid"
+)
+
+func TestEventsSkipsUnparsableEvents(t *testing.T) {
+	good := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:good\r\nDTSTART:20261020T100000Z\r\nDTEND:20261020T110000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	bad := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:bad\r\nDTSTART:sometime\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(w, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:response><d:href>/cal/bad.ics</d:href><d:propstat><d:prop><c:calendar-data>%s</c:calendar-data></d:prop></d:propstat></d:response>
+  <d:response><d:href>/cal/good.ics</d:href><d:propstat><d:prop><c:calendar-data>%s</c:calendar-data></d:prop></d:propstat></d:response>
+</d:multistatus>`, bad, good)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(nil, server.URL+"/cal", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := client.Events(context.Background(), time.Now(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].UID != "good" {
+		t.Errorf("got %+v, want only the good event", events)
+	}
+}
+
+func TestNewClientRejectsBadURLs(t *testing.T) {
+	for _, u := range []string{"", "not a url", "ftp://dav.example.com/cal/", "https:///cal/"} {
+		if _, err := NewClient(nil, u, "", ""); err != ErrNotCalendar {
+			t.Errorf("%q: %v, want ErrNotCalendar", u, err)
+		}
+	}
+}
+
+// TestClientAgainstServer runs against a real CalDAV server, such as the
+// radicale compose service:
+//
+//	TEST_CALDAV_URL=http://localhost:5232/test/ go test ./internal/caldav
+func TestClientAgainstServer(t *testing.T) {
+	base := os.Getenv("TEST_CALDAV_URL")
+	if base == "" {
+		t.Skip("TEST_CALDAV_URL not set")
+	}
+	ctx := context.Background()
+	calendarURL := strings.TrimSuffix(base, "/") + "/" + uuid.NewString() + "/"
+
+	req, err := http.NewRequestWithContext(ctx, "MKCALENDAR", calendarURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("MKCALENDAR: %s", resp.Status)
+	}
+
+	client, err := NewClient(nil, calendarURL, "test", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Check(ctx); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	start := time.Now().UTC().Truncate(time.Hour).Add(24 * time.Hour)
+	event := ical.Event{UID: "booking-1", Start: start, End: start.Add(time.Hour), Summary: "Full groom"}
+	if err := client.Put(ctx, "booking-1", &ical.Calendar{Events: []ical.Event{event}}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	events, err := client.Events(ctx, start.Add(-time.Hour), start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+	if len(events) != 1 || events[0].UID != "booking-1" || !events[0].Start.Equal(start) || !events[0].End.Equal(event.End) {
+		t.Errorf("Events = %+v", events)
+	}
+	if events, _ := client.Events(ctx, start.Add(2*time.Hour), start.Add(3*time.Hour)); len(events) != 0 {
+		t.Errorf("events outside the range: %+v", events)
+	}
+
+	if err := client.Delete(ctx, "booking-1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := client.Delete(ctx, "booking-1"); err != nil {
+		t.Errorf("second Delete: %v", err)
+	}
+	if events, _ := client.Events(ctx, start.Add(-time.Hour), start.Add(2*time.Hour)); len(events) != 0 {
+		t.Errorf("events after Delete: %+v", events)
+	}
+
+	notCalendar, err := NewClient(nil, strings.TrimSuffix(base, "/")+"/missing-"+uuid.NewString()+"/", "test", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notCalendar.Check(ctx); err == nil {
+		t.Error("Check passed for a collection that does not exist")
+	}
+}
@@ -9,6 +9,25 @@ type Config struct {
 	JWTSecret   string
 	Port        string
 	Environment string
+	// PublicURL is where clients reach the server, used in links sent out
+	// in notifications.
+	PublicURL string
+
+	// PaymentProvider and NotificationSender name the card processor and
+	// message gateway. They have no default: "fake" has to be chosen
+	// explicitly, and only in development.
+	PaymentProvider    string
+	NotificationSender string
+	// PubSub is the backend that carries real-time events between
+	// instances: "memory" or "postgres".
+	PubSub string
+
+	// CredentialsKey encrypts the credentials stored for providers, such
+	// as calendar passwords.
+	CredentialsKey string
+	// CalDAVAllowPrivate lets calendar sync reach loopback and private
+	// addresses, for a CalDAV server on the local network in development.
+	CalDAVAllowPrivate bool
 }
 
 func Load() *Config {
@@ -17,6 +36,14 @@ func Load() *Config {
 		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key"),
 		Port:        getEnv("PORT", "8080"),
 		Environment: getEnv("ENVIRONMENT", "development"),
+		PublicURL:   getEnv("PUBLIC_URL", "http://localhost:8080"),
+
+		PaymentProvider:    getEnv("PAYMENT_PROVIDER", ""),
+		NotificationSender: getEnv("NOTIFICATION_SENDER", ""),
+		PubSub:             getEnv("PUBSUB", "memory"),
+
+		CredentialsKey:     getEnv("CREDENTIALS_KEY", "your-credentials-key"),
+		CalDAVAllowPrivate: getEnv("CALDAV_ALLOW_PRIVATE", "false") == "true",
 	}
 }
 
@@ -69,6 +69,512 @@ func Migrate(db *sql.DB) error {
 		`CREATE INDEX IF NOT EXISTS idx_bookings_user_id ON bookings(user_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_bookings_provider_id ON bookings(provider_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_bookings_scheduled_time ON bookings(scheduled_time);`,
+
+		`CREATE TABLE IF NOT EXISTS booking_series (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			pet_id UUID NOT NULL REFERENCES pets(id) ON DELETE CASCADE,
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+			provider_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			rrule TEXT NOT NULL,
+			start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS series_id UUID REFERENCES booking_series(id) ON DELETE SET NULL;`,
+		`CREATE INDEX IF NOT EXISTS idx_bookings_series_id ON bookings(series_id);`,
+
+		`CREATE TABLE IF NOT EXISTS waitlist_entries (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			pet_id UUID NOT NULL REFERENCES pets(id) ON DELETE CASCADE,
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+			provider_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+			window_end TIMESTAMP WITH TIME ZONE NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'waiting',
+			offered_time TIMESTAMP WITH TIME ZONE,
+			hold_expires_at TIMESTAMP WITH TIME ZONE,
+			booking_id UUID REFERENCES bookings(id) ON DELETE SET NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_waitlist_entries_user_id ON waitlist_entries(user_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_waitlist_entries_provider_status ON waitlist_entries(provider_id, status);`,
+
+		`CREATE TABLE IF NOT EXISTS cancellation_policies (
+			provider_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+			free_cancellation_hours INTEGER NOT NULL DEFAULT 24,
+			late_cancel_fee_percent DECIMAL(5,2) NOT NULL DEFAULT 0,
+			no_show_fee_percent DECIMAL(5,2) NOT NULL DEFAULT 0,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS cancellation_reason TEXT;`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS cancelled_by UUID REFERENCES users(id) ON DELETE SET NULL;`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP WITH TIME ZONE;`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS cancellation_fee DECIMAL(10,2) NOT NULL DEFAULT 0;`,
+
+		`ALTER TABLE cancellation_policies ADD COLUMN IF NOT EXISTS no_show_threshold INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE cancellation_policies ADD COLUMN IF NOT EXISTS no_show_action VARCHAR(20) NOT NULL DEFAULT 'none';`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS no_show_fee DECIMAL(10,2) NOT NULL DEFAULT 0;`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS deposit_required BOOLEAN NOT NULL DEFAULT false;`,
+		`CREATE INDEX IF NOT EXISTS idx_bookings_user_status ON bookings(user_id, status);`,
+
+		`ALTER TABLE cancellation_policies ADD COLUMN IF NOT EXISTS deposit_percent DECIMAL(5,2) NOT NULL DEFAULT 0;`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS payment_status VARCHAR(20) NOT NULL DEFAULT 'unpaid';`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS deposit_amount DECIMAL(10,2) NOT NULL DEFAULT 0;`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS amount_paid DECIMAL(10,2) NOT NULL DEFAULT 0;`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS payment_method TEXT NOT NULL DEFAULT '';`,
+		`CREATE TABLE IF NOT EXISTS payments (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			booking_id UUID NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+			kind VARCHAR(20) NOT NULL,
+			transaction_id TEXT NOT NULL DEFAULT '',
+			amount DECIMAL(10,2) NOT NULL,
+			refunded_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+			status VARCHAR(20) NOT NULL,
+			failure_reason TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_payments_booking_id ON payments(booking_id);`,
+
+		// Money is stored as BIGINT minor units alongside an ISO 4217
+		// currency. Existing DECIMAL amounts were all in USD.
+		toMinorUnits("services", "price"),
+		toMinorUnits("bookings", "total_price"),
+		toMinorUnits("bookings", "cancellation_fee"),
+		toMinorUnits("bookings", "no_show_fee"),
+		toMinorUnits("bookings", "deposit_amount"),
+		toMinorUnits("bookings", "amount_paid"),
+		toMinorUnits("payments", "amount"),
+		toMinorUnits("payments", "refunded_amount"),
+		`ALTER TABLE services ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';`,
+		`ALTER TABLE payments ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';`,
+
+		// Invoices are numbered sequentially per provider from a counter row
+		// that is incremented inside the issuing transaction.
+		`CREATE TABLE IF NOT EXISTS invoice_counters (
+			provider_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+			last_number INTEGER NOT NULL DEFAULT 0
+		);`,
+		`CREATE TABLE IF NOT EXISTS invoices (
+			id UUID PRIMARY KEY,
+			booking_id UUID NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE CASCADE,
+			provider_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			number INTEGER NOT NULL,
+			invoice_number VARCHAR(32) NOT NULL,
+			issued_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			provider_name VARCHAR(255) NOT NULL DEFAULT '',
+			customer_name VARCHAR(255) NOT NULL DEFAULT '',
+			pet_name VARCHAR(255) NOT NULL DEFAULT '',
+			service_date TIMESTAMP WITH TIME ZONE NOT NULL,
+			currency CHAR(3) NOT NULL DEFAULT 'USD',
+			subtotal BIGINT NOT NULL DEFAULT 0,
+			tax_total BIGINT NOT NULL DEFAULT 0,
+			tip_total BIGINT NOT NULL DEFAULT 0,
+			total BIGINT NOT NULL DEFAULT 0,
+			amount_paid BIGINT NOT NULL DEFAULT 0,
+			UNIQUE (provider_id, number)
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_invoices_user_id ON invoices(user_id);`,
+		`CREATE TABLE IF NOT EXISTS invoice_lines (
+			invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			kind VARCHAR(20) NOT NULL,
+			description VARCHAR(255) NOT NULL,
+			quantity INTEGER NOT NULL DEFAULT 1,
+			unit_amount BIGINT NOT NULL DEFAULT 0,
+			amount BIGINT NOT NULL DEFAULT 0,
+			PRIMARY KEY (invoice_id, position)
+		);`,
+
+		// Tax is configured per provider and frozen onto each booking when it
+		// is priced, so later rate changes do not alter existing bookings.
+		`CREATE TABLE IF NOT EXISTS tax_settings (
+			provider_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+			rate DECIMAL(6,3) NOT NULL DEFAULT 0,
+			inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+			registration_number VARCHAR(64) NOT NULL DEFAULT '',
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		// Bookings made before tax were priced untaxed, so their subtotal is
+		// their total. That is filled in only when the column is added:
+		// later bookings can have a zero subtotal, when credits or points
+		// pay for all of it.
+		`DO $$
+			BEGIN
+				IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+					WHERE table_name = 'bookings' AND column_name = 'subtotal') THEN
+					ALTER TABLE bookings ADD COLUMN subtotal BIGINT NOT NULL DEFAULT 0;
+					UPDATE bookings SET subtotal = total_price;
+				END IF;
+			END $$;`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS tax_amount BIGINT NOT NULL DEFAULT 0;`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(6,3) NOT NULL DEFAULT 0;`,
+		`ALTER TABLE invoices ADD COLUMN IF NOT EXISTS tax_registration_number VARCHAR(64) NOT NULL DEFAULT '';`,
+
+		// One tip per booking, kept apart from the booking's price.
+		`CREATE TABLE IF NOT EXISTS tips (
+			id UUID PRIMARY KEY,
+			booking_id UUID NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			provider_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			staff_id UUID,
+			amount BIGINT NOT NULL,
+			currency CHAR(3) NOT NULL DEFAULT 'USD',
+			percent DECIMAL(5,2),
+			payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_tips_provider_id ON tips(provider_id, created_at);`,
+
+		// Prepaid packages and memberships. Each purchase becomes a credit
+		// row; credits_remaining is NULL for unlimited memberships.
+		`CREATE TABLE IF NOT EXISTS packages (
+			id UUID PRIMARY KEY,
+			provider_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			kind VARCHAR(20) NOT NULL,
+			category VARCHAR(50) NOT NULL DEFAULT '',
+			credits INTEGER NOT NULL DEFAULT 0,
+			price BIGINT NOT NULL,
+			currency CHAR(3) NOT NULL DEFAULT 'USD',
+			validity_days INTEGER NOT NULL,
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_packages_provider_id ON packages(provider_id);`,
+		`CREATE TABLE IF NOT EXISTS credits (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			package_id UUID NOT NULL REFERENCES packages(id),
+			provider_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			kind VARCHAR(20) NOT NULL,
+			category VARCHAR(50) NOT NULL DEFAULT '',
+			credits_remaining INTEGER CHECK (credits_remaining >= 0),
+			price_paid BIGINT NOT NULL,
+			currency CHAR(3) NOT NULL DEFAULT 'USD',
+			transaction_id VARCHAR(255) NOT NULL DEFAULT '',
+			purchased_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_credits_user_id ON credits(user_id);`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS credit_id UUID REFERENCES credits(id);`,
+
+		// Promo codes are unique per provider; uses is incremented with a
+		// conditional update so max_uses holds under concurrent bookings.
+		`CREATE TABLE IF NOT EXISTS promo_codes (
+			id UUID PRIMARY KEY,
+			provider_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			code VARCHAR(32) NOT NULL,
+			kind VARCHAR(20) NOT NULL,
+			percent DECIMAL(5,2) NOT NULL DEFAULT 0,
+			amount BIGINT NOT NULL DEFAULT 0,
+			min_spend BIGINT NOT NULL DEFAULT 0,
+			currency CHAR(3) NOT NULL DEFAULT 'USD',
+			valid_from TIMESTAMP WITH TIME ZONE,
+			valid_until TIMESTAMP WITH TIME ZONE,
+			max_uses INTEGER,
+			uses INTEGER NOT NULL DEFAULT 0,
+			first_booking_only BOOLEAN NOT NULL DEFAULT FALSE,
+			categories TEXT[] NOT NULL DEFAULT '{}',
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			UNIQUE (provider_id, code)
+		);`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS promo_code_id UUID REFERENCES promo_codes(id);`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS discount BIGINT NOT NULL DEFAULT 0;`,
+
+		// Loyalty points. Credits to the ledger keep a remaining balance that
+		// redemptions draw down, soonest expiry first; each source earns a
+		// reason at most once.
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS referral_code VARCHAR(16) UNIQUE;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS referred_by UUID REFERENCES users(id) ON DELETE SET NULL;`,
+		`CREATE TABLE IF NOT EXISTS points_ledger (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			kind VARCHAR(20) NOT NULL,
+			reason VARCHAR(30) NOT NULL,
+			points INTEGER NOT NULL,
+			remaining INTEGER NOT NULL DEFAULT 0 CHECK (remaining >= 0),
+			source_id UUID,
+			expires_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			UNIQUE (user_id, reason, source_id)
+		);`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS points_redeemed INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS points_discount BIGINT NOT NULL DEFAULT 0;`,
+		`CREATE TABLE IF NOT EXISTS reviews (
+			id UUID PRIMARY KEY,
+			booking_id UUID NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			provider_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			rating INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+			comment TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_reviews_provider_id ON reviews(provider_id, created_at);`,
+
+		// Gift cards hold a balance redeemable with one provider. The ledger
+		// records every movement; the CHECK backs up the conditional debit.
+		`CREATE TABLE IF NOT EXISTS gift_cards (
+			id UUID PRIMARY KEY,
+			code VARCHAR(32) NOT NULL UNIQUE,
+			provider_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			purchaser_id UUID REFERENCES users(id) ON DELETE SET NULL,
+			initial_amount BIGINT NOT NULL,
+			balance BIGINT NOT NULL CHECK (balance >= 0),
+			currency CHAR(3) NOT NULL DEFAULT 'USD',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_gift_cards_provider_id ON gift_cards(provider_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_gift_cards_purchaser_id ON gift_cards(purchaser_id);`,
+		`CREATE TABLE IF NOT EXISTS gift_card_transactions (
+			id UUID PRIMARY KEY,
+			gift_card_id UUID NOT NULL REFERENCES gift_cards(id) ON DELETE CASCADE,
+			kind VARCHAR(20) NOT NULL,
+			amount BIGINT NOT NULL,
+			balance_after BIGINT NOT NULL,
+			currency CHAR(3) NOT NULL DEFAULT 'USD',
+			booking_id UUID REFERENCES bookings(id) ON DELETE SET NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_gift_card_transactions_card ON gift_card_transactions(gift_card_id, created_at);`,
+
+		// Notification outbox, written in the transaction that makes the
+		// change and drained by the dispatcher.
+		`CREATE TABLE IF NOT EXISTS notifications (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			booking_id UUID REFERENCES bookings(id) ON DELETE CASCADE,
+			event VARCHAR(40) NOT NULL,
+			channel VARCHAR(10) NOT NULL,
+			recipient TEXT NOT NULL,
+			subject TEXT NOT NULL DEFAULT '',
+			body TEXT NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			sent_at TIMESTAMP WITH TIME ZONE
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_notifications_due ON notifications(next_attempt_at) WHERE status = 'pending';`,
+		`CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, created_at);`,
+
+		// Time zone and quiet hours for notifications, and a row per reminder
+		// sent; its primary key stops two instances sending the same one.
+		`CREATE TABLE IF NOT EXISTS notification_preferences (
+			user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+			time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+			quiet_hours_start SMALLINT CHECK (quiet_hours_start BETWEEN 0 AND 23),
+			quiet_hours_end SMALLINT CHECK (quiet_hours_end BETWEEN 0 AND 23),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE TABLE IF NOT EXISTS booking_reminders (
+			booking_id UUID NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+			kind VARCHAR(10) NOT NULL,
+			sent_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			PRIMARY KEY (booking_id, kind)
+		);`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS owner_confirmed_at TIMESTAMP WITH TIME ZONE;`,
+
+		// Which events and channels each user wants, and in what language.
+		// SMS becomes opt-in.
+		`ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS muted_events TEXT[] NOT NULL DEFAULT '{}';`,
+		`ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS channels TEXT[] NOT NULL DEFAULT '{email,push}';`,
+		`ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS language VARCHAR(10) NOT NULL DEFAULT 'en';`,
+
+		// Message threads between a booking's owner and provider.
+		// Attachments are small, so they are kept in the database.
+		`CREATE TABLE IF NOT EXISTS booking_messages (
+			id UUID PRIMARY KEY,
+			booking_id UUID NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+			sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			body TEXT NOT NULL DEFAULT '',
+			read_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_booking_messages_booking ON booking_messages(booking_id, created_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_booking_messages_unread ON booking_messages(booking_id) WHERE read_at IS NULL;`,
+		`CREATE TABLE IF NOT EXISTS message_attachments (
+			id UUID PRIMARY KEY,
+			message_id UUID NOT NULL REFERENCES booking_messages(id) ON DELETE CASCADE,
+			filename TEXT NOT NULL,
+			content_type VARCHAR(100) NOT NULL,
+			size BIGINT NOT NULL,
+			data BYTEA NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_message_attachments_message ON message_attachments(message_id);`,
+
+		// Outgoing webhooks for providers' other tools, and the log of every
+		// delivery made to them.
+		`CREATE TABLE IF NOT EXISTS webhooks (
+			id UUID PRIMARY KEY,
+			provider_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			url TEXT NOT NULL,
+			events TEXT[] NOT NULL,
+			secret TEXT NOT NULL,
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_webhooks_provider ON webhooks(provider_id) WHERE active;`,
+		`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id UUID PRIMARY KEY,
+			webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+			event VARCHAR(40) NOT NULL,
+			payload JSONB NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			response_status INTEGER,
+			last_error TEXT NOT NULL DEFAULT '',
+			next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			delivered_at TIMESTAMP WITH TIME ZONE
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';`,
+		`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at);`,
+
+		// Private iCalendar feed URLs, one per user.
+		`CREATE TABLE IF NOT EXISTS calendar_feeds (
+			user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+			token TEXT UNIQUE NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+
+		// Two-way sync with providers' CalDAV calendars: busy time imported
+		// from them, and which booking changes have been written back.
+		`CREATE TABLE IF NOT EXISTS calendar_connections (
+			provider_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+			calendar_url TEXT NOT NULL,
+			username TEXT NOT NULL DEFAULT '',
+			password TEXT NOT NULL DEFAULT '',
+			last_synced_at TIMESTAMP WITH TIME ZONE,
+			last_error TEXT NOT NULL DEFAULT '',
+			next_sync_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE TABLE IF NOT EXISTS busy_blocks (
+			id UUID PRIMARY KEY,
+			provider_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+			end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+			source VARCHAR(20) NOT NULL,
+			external_uid TEXT NOT NULL DEFAULT '',
+			imported_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_busy_blocks_provider_time ON busy_blocks(provider_id, start_time, end_time);`,
+		`CREATE TABLE IF NOT EXISTS calendar_pushed_bookings (
+			booking_id UUID PRIMARY KEY REFERENCES bookings(id) ON DELETE CASCADE,
+			pushed_at TIMESTAMP WITH TIME ZONE NOT NULL
+		);`,
+
+		// Salons: a provider's organization, the groomers working for it,
+		// what each of them offers and when they work.
+		`CREATE TABLE IF NOT EXISTS organizations (
+			id UUID PRIMARY KEY,
+			owner_id UUID UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE TABLE IF NOT EXISTS staff_members (
+			id UUID PRIMARY KEY,
+			organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			email VARCHAR(255) NOT NULL DEFAULT '',
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_staff_members_organization ON staff_members(organization_id);`,
+		`CREATE TABLE IF NOT EXISTS staff_services (
+			staff_id UUID NOT NULL REFERENCES staff_members(id) ON DELETE CASCADE,
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+			PRIMARY KEY (staff_id, service_id)
+		);`,
+		`CREATE TABLE IF NOT EXISTS staff_shifts (
+			staff_id UUID NOT NULL REFERENCES staff_members(id) ON DELETE CASCADE,
+			weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+			start_minute INTEGER NOT NULL,
+			end_minute INTEGER NOT NULL CHECK (end_minute > start_minute AND end_minute <= 1440)
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_staff_shifts_staff ON staff_shifts(staff_id);`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS staff_id UUID REFERENCES staff_members(id) ON DELETE SET NULL;`,
+		`CREATE INDEX IF NOT EXISTS idx_bookings_staff_time ON bookings(staff_id, scheduled_time) WHERE staff_id IS NOT NULL;`,
+
+		// Limited equipment and space, such as tubs and kennels, and how
+		// much of each a service holds and for which part of it.
+		`CREATE TABLE IF NOT EXISTS resources (
+			id UUID PRIMARY KEY,
+			provider_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			kind VARCHAR(20) NOT NULL,
+			capacity INTEGER NOT NULL CHECK (capacity > 0),
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_resources_provider ON resources(provider_id);`,
+		`CREATE TABLE IF NOT EXISTS service_resources (
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+			resource_id UUID NOT NULL REFERENCES resources(id) ON DELETE CASCADE,
+			quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+			start_offset_minutes INTEGER NOT NULL DEFAULT 0,
+			duration_minutes INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (service_id, resource_id)
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_service_resources_resource ON service_resources(resource_id);`,
+
+		// Boarding and sitting stays run from scheduled_time to
+		// check_out_time and are charged per night; care given during them
+		// is logged for owners.
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS check_out_time TIMESTAMP WITH TIME ZONE;`,
+		`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS nights INTEGER NOT NULL DEFAULT 0;`,
+		`CREATE TABLE IF NOT EXISTS care_logs (
+			id UUID PRIMARY KEY,
+			booking_id UUID NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+			author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			kind VARCHAR(20) NOT NULL,
+			notes TEXT NOT NULL DEFAULT '',
+			logged_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_care_logs_booking ON care_logs(booking_id, logged_at);`,
+
+		// Dog walks: the GPS track packed by geo.Encode, and what the dog did
+		// along the way.
+		`CREATE TABLE IF NOT EXISTS walks (
+			booking_id UUID PRIMARY KEY REFERENCES bookings(id) ON DELETE CASCADE,
+			started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			ended_at TIMESTAMP WITH TIME ZONE,
+			distance_meters DOUBLE PRECISION NOT NULL DEFAULT 0,
+			point_count INTEGER NOT NULL DEFAULT 0,
+			track BYTEA NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);`,
+		`CREATE TABLE IF NOT EXISTS walk_events (
+			id UUID PRIMARY KEY,
+			booking_id UUID NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+			kind VARCHAR(20) NOT NULL,
+			lat DOUBLE PRECISION NOT NULL,
+			lng DOUBLE PRECISION NOT NULL,
+			occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			UNIQUE (booking_id, kind, occurred_at)
+		);`,
+
+		// Messages are paged by a database-assigned sequence rather than
+		// their timestamps, which are not unique and not in commit order.
+		`ALTER TABLE booking_messages ADD COLUMN IF NOT EXISTS seq BIGSERIAL;`,
+		`CREATE INDEX IF NOT EXISTS idx_booking_messages_booking_seq ON booking_messages(booking_id, seq);`,
 	}
 
 	for _, migration := range migrations {
@@ -78,4 +584,17 @@ func Migrate(db *sql.DB) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// toMinorUnits converts a DECIMAL money column to BIGINT minor units. The
+// conversion only runs while the column is still numeric, so re-running
+// migrations leaves converted data alone.
+func toMinorUnits(table, column string) string {
+	return fmt.Sprintf(`DO $$
+		BEGIN
+			IF (SELECT data_type FROM information_schema.columns
+				WHERE table_name = '%[1]s' AND column_name = '%[2]s') = 'numeric' THEN
+				ALTER TABLE %[1]s ALTER COLUMN %[2]s TYPE BIGINT USING ROUND(%[2]s * 100);
+			END IF;
+		END $$;`, table, column)
+}
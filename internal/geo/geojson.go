@@ -0,0 +1,51 @@
+package geo
+
+// ContentType is the media type of GeoJSON documents (RFC 7946).
+const ContentType = "application/geo+json"
+
+// Geometry is a GeoJSON geometry. Positions are [longitude, latitude].
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// Feature is a GeoJSON feature.
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON feature collection.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+func NewFeatureCollection() *FeatureCollection {
+	return &FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+}
+
+// Add appends a feature with the given geometry and properties.
+func (fc *FeatureCollection) Add(geometry Geometry, properties map[string]any) {
+	fc.Features = append(fc.Features, Feature{Type: "Feature", Geometry: geometry, Properties: properties})
+}
+
+func position(p Point) []float64 {
+	return []float64{p.Lng, p.Lat}
+}
+
+// PointGeometry returns a Point geometry at p.
+func PointGeometry(p Point) Geometry {
+	return Geometry{Type: "Point", Coordinates: position(p)}
+}
+
+// LineString returns a LineString through points, which needs at least two
+// of them to be valid GeoJSON.
+func LineString(points []Point) Geometry {
+	coordinates := make([][]float64, len(points))
+	for i, p := range points {
+		coordinates[i] = position(p)
+	}
+	return Geometry{Type: "LineString", Coordinates: coordinates}
+}
@@ -0,0 +1,29 @@
+package geo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFeatureCollectionJSON(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.Add(LineString([]Point{{Lat: 51.5, Lng: -0.1}, {Lat: 51.6, Lng: -0.2}}), map[string]any{"kind": "track"})
+	fc.Add(PointGeometry(Point{Lat: 51.55, Lng: -0.15}), map[string]any{"kind": "pee"})
+
+	got, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Positions are longitude first.
+	want := `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[-0.1,51.5],[-0.2,51.6]]},"properties":{"kind":"track"}},` +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[-0.15,51.55]},"properties":{"kind":"pee"}}]}`
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	empty, _ := json.Marshal(NewFeatureCollection())
+	if string(empty) != `{"type":"FeatureCollection","features":[]}` {
+		t.Errorf("empty collection = %s", empty)
+	}
+}
@@ -0,0 +1,107 @@
+// Package geo stores GPS tracks compactly and measures and exports them.
+package geo
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+var ErrMalformedTrack = errors.New("malformed track data")
+
+// Point is one GPS fix.
+type Point struct {
+	Lat  float64
+	Lng  float64
+	Time time.Time
+}
+
+// trackVersion is the first byte of every encoded track.
+const trackVersion = 1
+
+// microdegrees is the resolution coordinates are stored at, about 11 cm.
+const microdegrees = 1e6
+
+// Encode packs points, which must be in time order, into the compact track
+// format: a version byte, then for each point the change in latitude and
+// longitude from the previous point as signed varints in microdegrees and
+// the seconds since it as an unsigned varint. The first point is stored
+// relative to 0,0 and the Unix epoch. A fix every few seconds takes around
+// five bytes.
+func Encode(points []Point) []byte {
+	b := make([]byte, 0, 1+6*len(points))
+	b = append(b, trackVersion)
+	var lat, lng, t int64
+	for _, p := range points {
+		pLat := int64(math.Round(p.Lat * microdegrees))
+		pLng := int64(math.Round(p.Lng * microdegrees))
+		pT := p.Time.Unix()
+		b = binary.AppendVarint(b, pLat-lat)
+		b = binary.AppendVarint(b, pLng-lng)
+		b = binary.AppendUvarint(b, uint64(pT-t))
+		lat, lng, t = pLat, pLng, pT
+	}
+	return b
+}
+
+// Decode unpacks a track written by Encode. Times come back in UTC to the
+// second.
+func Decode(data []byte) ([]Point, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if data[0] != trackVersion {
+		return nil, ErrMalformedTrack
+	}
+	data = data[1:]
+
+	var points []Point
+	var lat, lng, t int64
+	for len(data) > 0 {
+		dLat, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, ErrMalformedTrack
+		}
+		data = data[n:]
+		dLng, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, ErrMalformedTrack
+		}
+		data = data[n:]
+		dT, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, ErrMalformedTrack
+		}
+		data = data[n:]
+
+		lat, lng, t = lat+dLat, lng+dLng, t+int64(dT)
+		points = append(points, Point{
+			Lat:  float64(lat) / microdegrees,
+			Lng:  float64(lng) / microdegrees,
+			Time: time.Unix(t, 0).UTC(),
+		})
+	}
+	return points, nil
+}
+
+// earthRadius is the mean radius of the Earth in metres.
+const earthRadius = 6371008.8
+
+// Distance returns the length in metres of the path through points.
+func Distance(points []Point) float64 {
+	var total float64
+	for i := 1; i < len(points); i++ {
+		total += haversine(points[i-1], points[i])
+	}
+	return total
+}
+
+// haversine returns the great-circle distance in metres between a and b.
+func haversine(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
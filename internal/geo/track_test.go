@@ -0,0 +1,111 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	start := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	points := []Point{
+		{Lat: 51.501364, Lng: -0.14189, Time: start},
+		{Lat: 51.501412, Lng: -0.141702, Time: start.Add(4 * time.Second)},
+		{Lat: 51.50139, Lng: -0.141511, Time: start.Add(9 * time.Second)},
+		// A long pause between fixes and a jump across the equator and
+		// antimeridian still round-trip.
+		{Lat: -33.856784, Lng: 151.215297, Time: start.Add(3 * time.Hour)},
+		{Lat: -33.856784, Lng: -179.999999, Time: start.Add(3 * time.Hour)},
+	}
+
+	data := Encode(points)
+	got, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(points) {
+		t.Fatalf("decoded %d points, want %d", len(got), len(points))
+	}
+	for i, p := range points {
+		if math.Abs(got[i].Lat-p.Lat) > 1e-9 || math.Abs(got[i].Lng-p.Lng) > 1e-9 || !got[i].Time.Equal(p.Time) {
+			t.Errorf("point %d = %+v, want %+v", i, got[i], p)
+		}
+		if got[i].Time.Location() != time.UTC {
+			t.Errorf("point %d time in %v, want UTC", i, got[i].Time.Location())
+		}
+	}
+}
+
+func TestEncodeRounding(t *testing.T) {
+	at := time.Unix(1700000000, 900_000_000)
+	got, err := Decode(Encode([]Point{{Lat: 12.3456784, Lng: -12.3456786, Time: at}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Lat != 12.345678 || got[0].Lng != -12.345679 {
+		t.Errorf("coordinates = %v, %v; want 12.345678, -12.345679", got[0].Lat, got[0].Lng)
+	}
+	if got[0].Time.Unix() != at.Unix() {
+		t.Errorf("time = %v, want %v truncated to the second", got[0].Time, at)
+	}
+}
+
+func TestEncodeIsCompact(t *testing.T) {
+	start := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	points := make([]Point, 100)
+	for i := range points {
+		points[i] = Point{Lat: 51.5 + float64(i)*0.00003, Lng: -0.14 + float64(i)*0.00002, Time: start.Add(time.Duration(5*i) * time.Second)}
+	}
+	// The first point carries the absolute position and time; the rest
+	// should take about five bytes each.
+	if n := len(Encode(points)); n > 20+6*len(points) {
+		t.Errorf("encoded %d points in %d bytes", len(points), n)
+	}
+}
+
+func TestDecodeEmpty(t *testing.T) {
+	for _, data := range [][]byte{nil, Encode(nil)} {
+		points, err := Decode(data)
+		if err != nil || len(points) != 0 {
+			t.Errorf("Decode(%v) = %v, %v; want no points", data, points, err)
+		}
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	valid := Encode([]Point{{Lat: 1, Lng: 2, Time: time.Unix(1700000000, 0)}})
+	tests := map[string][]byte{
+		"unknown version": append([]byte{trackVersion + 1}, valid[1:]...),
+		"truncated":       valid[:len(valid)-1],
+		"overlong varint": {trackVersion, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	}
+	for name, data := range tests {
+		if _, err := Decode(data); !errors.Is(err, ErrMalformedTrack) {
+			t.Errorf("%s: %v, want ErrMalformedTrack", name, err)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []Point
+		want   float64 // metres
+		within float64
+	}{
+		{"no points", nil, 0, 0},
+		{"one point", []Point{{Lat: 10, Lng: 10}}, 0, 0},
+		// One degree of longitude on the equator is 2πR/360.
+		{"a degree on the equator", []Point{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}}, 2 * math.Pi * earthRadius / 360, 0.01},
+		{"there and back", []Point{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}, {Lat: 0, Lng: 0}}, 2 * 2 * math.Pi * earthRadius / 360, 0.01},
+		// London to Paris is about 343.5 km.
+		{"London to Paris", []Point{{Lat: 51.5074, Lng: -0.1278}, {Lat: 48.8566, Lng: 2.3522}}, 343_500, 1000},
+		{"antipodes", []Point{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 180}}, math.Pi * earthRadius, 0.01},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.points); math.Abs(got-tt.want) > tt.within {
+			t.Errorf("%s: Distance = %.2f m, want %.2f ± %.2f", tt.name, got, tt.want, tt.within)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Package ical writes iCalendar (RFC 5545) files with the handful of
+// properties calendar apps need to show appointments and keep them in sync.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event statuses understood by calendar apps.
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Event is one VEVENT. UID must stay the same for the life of the
+// appointment and Sequence must grow whenever it changes, so subscribed
+// calendars update the existing entry instead of adding another.
+type Event struct {
+	UID         string
+	Sequence    int
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	Status      string
+	// Transparent events do not make anyone busy.
+	Transparent bool
+	Created     time.Time
+	Updated     time.Time
+}
+
+// Calendar is a VCALENDAR holding events.
+type Calendar struct {
+	// Name is shown by clients that support X-WR-CALNAME for subscribed
+	// feeds.
+	Name   string
+	Events []Event
+}
+
+const dateTimeFormat = "20060102T150405Z"
+
+// Bytes serialises the calendar. Times are written in UTC, lines end in CRLF
+// and are folded at 75 octets as the RFC requires.
+func (c *Calendar) Bytes() []byte {
+	var b bytes.Buffer
+	line := func(name, value string) {
+		fold(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//pet-grooming-app//bookings//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+
+	now := time.Now()
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", escape(e.UID))
+		line("DTSTAMP", now.UTC().Format(dateTimeFormat))
+		line("DTSTART", e.Start.UTC().Format(dateTimeFormat))
+		line("DTEND", e.End.UTC().Format(dateTimeFormat))
+		line("SEQUENCE", fmt.Sprint(e.Sequence))
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escape(e.Location))
+		}
+		if e.Status != "" {
+			line("STATUS", e.Status)
+		}
+		if e.Transparent {
+			line("TRANSP", "TRANSPARENT")
+		}
+		if !e.Created.IsZero() {
+			line("CREATED", e.Created.UTC().Format(dateTimeFormat))
+		}
+		if !e.Updated.IsZero() {
+			line("LAST-MODIFIED", e.Updated.UTC().Format(dateTimeFormat))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return b.Bytes()
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// escape quotes the characters that are special in TEXT values.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// fold writes a content line, breaking it every 75 octets without splitting
+// a UTF-8 sequence. Continuation lines start with a space.
+func fold(b *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts towards the next line's length.
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
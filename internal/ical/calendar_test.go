@@ -0,0 +1,85 @@
+package ical
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// dtstamp matches the DTSTAMP lines, which hold the time of writing.
+var dtstamp = regexp.MustCompile(`(?m)^DTSTAMP:\d{8}T\d{6}Z\r$`)
+
+func TestCalendarBytesGolden(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("time zone data unavailable:", err)
+	}
+	created := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	cal := Calendar{
+		Name: "Grooming appointments; Ana's salon",
+		Events: []Event{
+			{
+				UID:      "0b9a3c1e-5d2f-4a7b-9c8d-1e2f3a4b5c6d@pet-grooming-app",
+				Sequence: 3,
+				// Written in UTC whatever zone the booking was made in.
+				Start:       time.Date(2026, 10, 20, 10, 0, 0, 0, paris),
+				End:         time.Date(2026, 10, 20, 11, 30, 0, 0, paris),
+				Summary:     "Full groom, bath & nails for Biscuit",
+				Description: "Owner: Zoë Müller\nGroomer: Ana\nStatus: confirmed\nNotes: Nervous around dryers; use the quiet room, please. Back\\side gate code is 1234.",
+				Location:    "12 Rue de la Paix, 75002 Paris",
+				Status:      StatusConfirmed,
+				Created:     created,
+				Updated:     created.Add(3 * time.Second),
+			},
+			{
+				UID:         "7f6e5d4c-3b2a-4190-8877-665544332211@pet-grooming-app",
+				Start:       time.Date(2026, 10, 22, 14, 0, 0, 0, time.UTC),
+				End:         time.Date(2026, 10, 22, 15, 0, 0, 0, time.UTC),
+				Summary:     "Nail trim for Rex",
+				Description: "Owner: Sam Lee\nGroomer: Ana\nStatus: cancelled",
+				Status:      StatusCancelled,
+				Created:     created,
+				Updated:     created.Add(time.Hour),
+			},
+		},
+	}
+
+	got := cal.Bytes()
+	if !dtstamp.Match(got) {
+		t.Fatal("no DTSTAMP written")
+	}
+	got = dtstamp.ReplaceAll(got, []byte("DTSTAMP:20260101T000000Z\r"))
+
+	golden := filepath.Join("testdata", "calendar.ics")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("calendar differs from %s:\n%s", golden, got)
+	}
+
+	for i, line := range bytes.Split(got, []byte("\r\n")) {
+		if len(line) > 75 {
+			t.Errorf("line %d is %d octets long", i+1, len(line))
+		}
+	}
+	events, err := Parse(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Description != cal.Events[0].Description || events[1].Status != StatusCancelled {
+		t.Errorf("written calendar parses back as %+v", events)
+	}
+}
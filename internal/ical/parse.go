@@ -0,0 +1,200 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrMalformed = errors.New("malformed iCalendar data")
+
+// Parse reads the VEVENTs in an iCalendar object into Events, ignoring the
+// properties Event has no field for. Recurrence rules are not expanded; ask
+// the server to expand them instead.
+func Parse(data []byte) ([]Event, error) {
+	var events []Event
+	var current *Event
+	var params map[string]map[string]string
+	var values map[string]string
+	depth := 0
+
+	for _, line := range unfold(data) {
+		name, lineParams, value, ok := splitLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current = &Event{}
+			params = make(map[string]map[string]string)
+			values = make(map[string]string)
+			depth = 0
+		case current == nil:
+		case name == "BEGIN":
+			// Nested components such as VALARM have their own properties.
+			depth++
+		case name == "END" && depth > 0:
+			depth--
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if err := fillEvent(current, params, values); err != nil {
+				return nil, err
+			}
+			events = append(events, *current)
+			current = nil
+		case depth == 0:
+			if _, seen := values[name]; !seen {
+				params[name] = lineParams
+				values[name] = value
+			}
+		}
+	}
+	if current != nil {
+		return nil, ErrMalformed
+	}
+	return events, nil
+}
+
+func fillEvent(e *Event, params map[string]map[string]string, values map[string]string) error {
+	e.UID = values["UID"]
+	e.Summary = unescape(values["SUMMARY"])
+	e.Description = unescape(values["DESCRIPTION"])
+	e.Location = unescape(values["LOCATION"])
+	e.Status = strings.ToUpper(values["STATUS"])
+	e.Transparent = strings.EqualFold(values["TRANSP"], "TRANSPARENT")
+
+	start, allDay, err := parseTime(values["DTSTART"], params["DTSTART"])
+	if err != nil {
+		return err
+	}
+	e.Start = start
+
+	switch {
+	case values["DTEND"] != "":
+		if e.End, _, err = parseTime(values["DTEND"], params["DTEND"]); err != nil {
+			return err
+		}
+	case values["DURATION"] != "":
+		d, err := parseDuration(values["DURATION"])
+		if err != nil {
+			return err
+		}
+		e.End = start.Add(d)
+	case allDay:
+		e.End = start.AddDate(0, 0, 1)
+	default:
+		e.End = start
+	}
+	if seq := values["SEQUENCE"]; seq != "" {
+		e.Sequence, _ = strconv.Atoi(seq)
+	}
+	return nil
+}
+
+// parseTime reads a DATE-TIME or DATE value. Floating and unknown zones are
+// taken as UTC. It reports whether the value was a whole day.
+func parseTime(value string, params map[string]string) (time.Time, bool, error) {
+	if value == "" {
+		return time.Time{}, false, ErrMalformed
+	}
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(strings.Trim(tzid, `"`)); err == nil {
+			loc = l
+		}
+	}
+
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, false, ErrMalformed
+		}
+		return t, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(dateTimeFormat, value)
+		if err != nil {
+			return time.Time{}, false, ErrMalformed
+		}
+		return t, false, nil
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, ErrMalformed
+	}
+	return t, false, nil
+}
+
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration reads a DURATION value such as PT1H30M or P1D.
+func parseDuration(value string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(strings.ToUpper(value))
+	if m == nil {
+		return 0, ErrMalformed
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] != "" {
+			n, _ := strconv.Atoi(m[i+2])
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// unfold joins continuation lines back onto the line they continue.
+func unfold(data []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// splitLine breaks a content line into its upper-cased name, parameters and
+// value. Colons inside quoted parameter values do not end the name.
+func splitLine(line string) (string, map[string]string, string, bool) {
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			params[strings.ToUpper(k)] = v
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+var unescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
@@ -0,0 +1,203 @@
+package ical
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// vcalendar wraps content lines in a VCALENDAR with CRLF line endings.
+func vcalendar(lines ...string) []byte {
+	all := append([]string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//test//EN"}, lines...)
+	all = append(all, "END:VCALENDAR", "")
+	return []byte(strings.Join(all, "\r\n"))
+}
+
+func parseOne(t *testing.T, data []byte) Event {
+	t.Helper()
+	events, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	return events[0]
+}
+
+func TestParseUTCEvent(t *testing.T) {
+	e := parseOne(t, vcalendar(
+		"BEGIN:VEVENT",
+		"UID:dentist",
+		"DTSTAMP:20260101T000000Z",
+		"DTSTART:20261020T100000Z",
+		"DTEND:20261020T113000Z",
+		"SEQUENCE:3",
+		"SUMMARY:Dentist",
+		"END:VEVENT",
+	))
+	if e.UID != "dentist" || e.Summary != "Dentist" || e.Sequence != 3 {
+		t.Errorf("got %+v", e)
+	}
+	if want := time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC); !e.Start.Equal(want) {
+		t.Errorf("Start = %v, want %v", e.Start, want)
+	}
+	if want := time.Date(2026, 10, 20, 11, 30, 0, 0, time.UTC); !e.End.Equal(want) {
+		t.Errorf("End = %v, want %v", e.End, want)
+	}
+}
+
+func TestParseTimes(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data unavailable:", err)
+	}
+	tests := []struct {
+		name       string
+		lines      []string
+		start, end time.Time
+	}{
+		{
+			"TZID",
+			[]string{"DTSTART;TZID=America/New_York:20261020T090000", "DTEND;TZID=\"America/New_York\":20261020T100000"},
+			time.Date(2026, 10, 20, 9, 0, 0, 0, ny), time.Date(2026, 10, 20, 10, 0, 0, 0, ny),
+		},
+		{
+			"unknown zone is UTC",
+			[]string{"DTSTART;TZID=Nowhere/Special:20261020T090000", "DTEND;TZID=Nowhere/Special:20261020T100000"},
+			time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC), time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			"all day without end",
+			[]string{"DTSTART;VALUE=DATE:20261020"},
+			time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"all day over several days",
+			[]string{"DTSTART;VALUE=DATE:20261020", "DTEND;VALUE=DATE:20261023"},
+			time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"duration",
+			[]string{"DTSTART:20261020T100000Z", "DURATION:PT1H30M"},
+			time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC), time.Date(2026, 10, 20, 11, 30, 0, 0, time.UTC),
+		},
+		{
+			"duration in days and weeks",
+			[]string{"DTSTART:20261020T100000Z", "DURATION:P1W2D"},
+			time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC), time.Date(2026, 10, 29, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			"no end or duration",
+			[]string{"DTSTART:20261020T100000Z"},
+			time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC), time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := append([]string{"BEGIN:VEVENT", "UID:x"}, tt.lines...)
+			e := parseOne(t, vcalendar(append(lines, "END:VEVENT")...))
+			if !e.Start.Equal(tt.start) || !e.End.Equal(tt.end) {
+				t.Errorf("got %v – %v, want %v – %v", e.Start, e.End, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestParseText(t *testing.T) {
+	e := parseOne(t, vcalendar(
+		"BEGIN:VEVENT",
+		"UID:x",
+		"DTSTART:20261020T100000Z",
+		"SUMMARY:Lunch\\, then the vet\\; bring",
+		"  the lead",
+		"DESCRIPTION:Line one\\nLine two\\\\",
+		"LOCATION:Room \"A:1\"",
+		"END:VEVENT",
+	))
+	if want := "Lunch, then the vet; bring the lead"; e.Summary != want {
+		t.Errorf("Summary = %q, want %q", e.Summary, want)
+	}
+	if want := "Line one\nLine two\\"; e.Description != want {
+		t.Errorf("Description = %q, want %q", e.Description, want)
+	}
+	if want := `Room "A:1"`; e.Location != want {
+		t.Errorf("Location = %q, want %q", e.Location, want)
+	}
+}
+
+func TestParseIgnoresNestedComponents(t *testing.T) {
+	e := parseOne(t, vcalendar(
+		"BEGIN:VEVENT",
+		"UID:x",
+		"DTSTART:20261020T100000Z",
+		"DTEND:20261020T110000Z",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:Reminder",
+		"TRIGGER;RELATED=END:-PT15M",
+		"END:VALARM",
+		"SUMMARY:Meeting",
+		"END:VEVENT",
+	))
+	if e.Description != "" || e.Summary != "Meeting" {
+		t.Errorf("alarm leaked into the event: %+v", e)
+	}
+	if !e.End.Equal(time.Date(2026, 10, 20, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("End = %v", e.End)
+	}
+}
+
+func TestParseStatusAndTransparency(t *testing.T) {
+	events, err := Parse(vcalendar(
+		"BEGIN:VEVENT", "UID:a", "DTSTART:20261020T100000Z", "STATUS:cancelled", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:b", "DTSTART:20261020T100000Z", "TRANSP:TRANSPARENT", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:c", "DTSTART:20261020T100000Z", "TRANSP:OPAQUE", "END:VEVENT",
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	if events[0].Status != "CANCELLED" {
+		t.Errorf("Status = %q, want CANCELLED", events[0].Status)
+	}
+	if !events[1].Transparent || events[2].Transparent {
+		t.Errorf("Transparent = %v, %v; want true, false", events[1].Transparent, events[2].Transparent)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tests := map[string][]byte{
+		"missing start": vcalendar("BEGIN:VEVENT", "UID:x", "END:VEVENT"),
+		"bad start":     vcalendar("BEGIN:VEVENT", "UID:x", "DTSTART:tomorrow", "END:VEVENT"),
+		"bad duration":  vcalendar("BEGIN:VEVENT", "UID:x", "DTSTART:20261020T100000Z", "DURATION:1 hour", "END:VEVENT"),
+		"unterminated":  vcalendar("BEGIN:VEVENT", "UID:x", "DTSTART:20261020T100000Z"),
+	}
+	for name, data := range tests {
+		if _, err := Parse(data); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s: %v, want ErrMalformed", name, err)
+		}
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	want := Event{
+		UID:         "booking-1",
+		Sequence:    2,
+		Start:       time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC),
+		End:         time.Date(2026, 10, 20, 11, 0, 0, 0, time.UTC),
+		Summary:     "Full groom, Biscuit",
+		Description: strings.Repeat("A long note; with, specials\n", 5),
+		Transparent: true,
+	}
+	cal := Calendar{Events: []Event{want}}
+	got := parseOne(t, cal.Bytes())
+	if got.UID != want.UID || got.Sequence != want.Sequence || got.Summary != want.Summary ||
+		got.Description != want.Description || got.Transparent != want.Transparent ||
+		!got.Start.Equal(want.Start) || !got.End.Equal(want.End) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -15,9 +15,70 @@ type Booking struct {
 	ScheduledTime time.Time     `json:"scheduled_time" db:"scheduled_time"`
 	Status        BookingStatus `json:"status" db:"status"`
 	Notes         string        `json:"notes" db:"notes"`
-	TotalPrice    float64       `json:"total_price" db:"total_price"`
+	TotalPrice    Money         `json:"total_price" db:"total_price"`
+	SeriesID      *uuid.UUID    `json:"series_id,omitempty" db:"series_id"`
 	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time     `json:"updated_at" db:"updated_at"`
+
+	CancellationReason string     `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
+	CancelledBy        *uuid.UUID `json:"cancelled_by,omitempty" db:"cancelled_by"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CancellationFee    Money      `json:"cancellation_fee" db:"cancellation_fee"`
+	NoShowFee          Money      `json:"no_show_fee" db:"no_show_fee"`
+	DepositRequired    bool       `json:"deposit_required" db:"deposit_required"`
+
+	PaymentStatus PaymentStatus `json:"payment_status" db:"payment_status"`
+	DepositAmount Money         `json:"deposit_amount" db:"deposit_amount"`
+	AmountPaid    Money         `json:"amount_paid" db:"amount_paid"`
+	PaymentMethod string        `json:"-" db:"payment_method"`
+
+	// Subtotal and TaxAmount break TotalPrice down using the provider's tax
+	// settings at the time of booking; TaxRate is the percentage applied.
+	Subtotal  Money   `json:"subtotal" db:"subtotal"`
+	TaxAmount Money   `json:"tax_amount" db:"tax_amount"`
+	TaxRate   float64 `json:"tax_rate" db:"tax_rate"`
+
+	// CreditID is the prepaid credit the booking was redeemed against.
+	CreditID *uuid.UUID `json:"credit_id,omitempty" db:"credit_id"`
+
+	// Discount is the pre-tax reduction given by PromoCodeID; Subtotal is
+	// already net of it.
+	PromoCodeID *uuid.UUID `json:"promo_code_id,omitempty" db:"promo_code_id"`
+	Discount    Money      `json:"discount" db:"discount"`
+
+	// PointsRedeemed loyalty points took PointsDiscount off the pre-tax
+	// price.
+	PointsRedeemed int   `json:"points_redeemed" db:"points_redeemed"`
+	PointsDiscount Money `json:"points_discount" db:"points_discount"`
+
+	// OwnerConfirmedAt is when the owner confirmed they are coming, usually
+	// from a reminder.
+	OwnerConfirmedAt *time.Time `json:"owner_confirmed_at,omitempty" db:"owner_confirmed_at"`
+
+	// StaffID is the groomer carrying out the booking, for providers with
+	// staff.
+	StaffID *uuid.UUID `json:"staff_id,omitempty" db:"staff_id"`
+
+	// CheckOutTime ends a boarding or sitting stay that starts at
+	// ScheduledTime. Nights is how many nights it spans and are charged
+	// for. A stay is checked in when it moves to in_progress and checked
+	// out when it is completed.
+	CheckOutTime *time.Time `json:"check_out_time,omitempty" db:"check_out_time"`
+	Nights       int        `json:"nights,omitempty" db:"nights"`
+}
+
+// SetCurrency stamps the booking's currency, stored once per row, onto each
+// of its amounts after they are scanned as minor units.
+func (b *Booking) SetCurrency(currency string) {
+	b.TotalPrice.Currency = currency
+	b.CancellationFee.Currency = currency
+	b.NoShowFee.Currency = currency
+	b.DepositAmount.Currency = currency
+	b.AmountPaid.Currency = currency
+	b.Subtotal.Currency = currency
+	b.TaxAmount.Currency = currency
+	b.Discount.Currency = currency
+	b.PointsDiscount.Currency = currency
 }
 
 type BookingStatus string
@@ -28,19 +89,89 @@ const (
 	StatusInProgress BookingStatus = "in_progress"
 	StatusCompleted  BookingStatus = "completed"
 	StatusCancelled  BookingStatus = "cancelled"
+	StatusNoShow     BookingStatus = "no_show"
 )
 
+// CanTransitionTo reports whether a booking may move from s to next.
+func (s BookingStatus) CanTransitionTo(next BookingStatus) bool {
+	switch s {
+	case StatusPending:
+		return next == StatusConfirmed || next == StatusCancelled || next == StatusNoShow
+	case StatusConfirmed:
+		return next == StatusInProgress || next == StatusCancelled || next == StatusNoShow
+	case StatusInProgress:
+		return next == StatusCompleted
+	}
+	return false
+}
+
+// IsActive reports whether a booking in this status still holds its slot.
+func (s BookingStatus) IsActive() bool {
+	return s == StatusPending || s == StatusConfirmed || s == StatusInProgress
+}
+
 type CreateBookingRequest struct {
 	PetID         uuid.UUID `json:"pet_id" binding:"required"`
 	ServiceID     uuid.UUID `json:"service_id" binding:"required"`
 	ScheduledTime time.Time `json:"scheduled_time" binding:"required"`
 	Notes         string    `json:"notes"`
+	// PaymentMethod is a processor token used for the deposit and, on
+	// completion, the remaining balance.
+	PaymentMethod string `json:"payment_method"`
+	// CreditID redeems a prepaid package or membership instead of charging
+	// the service price.
+	CreditID *uuid.UUID `json:"credit_id"`
+	// PromoCode applies one of the provider's discount codes.
+	PromoCode string `json:"promo_code"`
+	// RedeemPoints spends loyalty points against a single booking.
+	RedeemPoints int `json:"redeem_points" binding:"min=0"`
+	// GiftCardCode pays as much of the booking as the card's balance covers.
+	GiftCardCode string `json:"gift_card_code"`
+	// StaffID asks for a particular groomer at a salon; without it the
+	// first qualified groomer who is free is assigned.
+	StaffID *uuid.UUID `json:"staff_id"`
+	// CheckOutTime is required for boarding and sitting stays, which check
+	// in at ScheduledTime.
+	CheckOutTime *time.Time `json:"check_out_time"`
+
+	Recurrence    *RecurrenceRule `json:"recurrence"`
+	SkipConflicts bool            `json:"skip_conflicts"`
 }
 
 type UpdateBookingRequest struct {
+	// ScheduledTime moves the booking. A stay keeps its length, so its
+	// check-out moves by the same amount.
 	ScheduledTime *time.Time     `json:"scheduled_time"`
 	Status        *BookingStatus `json:"status"`
 	Notes         *string        `json:"notes"`
+	// StaffID reassigns the booking to another groomer; only the provider
+	// can change it.
+	StaffID *uuid.UUID      `json:"staff_id"`
+	Scope   RecurrenceScope `json:"scope"`
+}
+
+type BookingSeries struct {
+	ID         uuid.UUID      `json:"id" db:"id"`
+	UserID     uuid.UUID      `json:"user_id" db:"user_id"`
+	PetID      uuid.UUID      `json:"pet_id" db:"pet_id"`
+	ServiceID  uuid.UUID      `json:"service_id" db:"service_id"`
+	ProviderID uuid.UUID      `json:"provider_id" db:"provider_id"`
+	Rule       RecurrenceRule `json:"rule" db:"rrule"`
+	StartTime  time.Time      `json:"start_time" db:"start_time"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+}
+
+// BookingConflict describes an occurrence that could not be booked because
+// the provider is already busy at that time.
+type BookingConflict struct {
+	ScheduledTime time.Time `json:"scheduled_time"`
+	Reason        string    `json:"reason"`
+}
+
+type RecurringBookingResponse struct {
+	Series   BookingSeries     `json:"series"`
+	Bookings []Booking         `json:"bookings"`
+	Skipped  []BookingConflict `json:"skipped"`
 }
 
 type BookingWithDetails struct {
@@ -48,4 +179,4 @@ type BookingWithDetails struct {
 	Pet     Pet     `json:"pet"`
 	Service Service `json:"service"`
 	User    User    `json:"user"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarFeed is a user's private iCalendar subscription. Anyone with the
+// URL can read the feed, so it can be reset to a new one.
+type CalendarFeed struct {
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CalendarConnection links a provider's external CalDAV calendar. Busy time
+// in it is imported as BusyBlocks, and the provider's bookings are written
+// into it.
+type CalendarConnection struct {
+	ProviderID  uuid.UUID `json:"provider_id" db:"provider_id"`
+	CalendarURL string    `json:"calendar_url" db:"calendar_url"`
+	Username    string    `json:"username" db:"username"`
+	// Password is best an app-specific password. It is stored sealed with
+	// the credentials key and is never returned.
+	Password     string     `json:"-" db:"password"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty" db:"last_synced_at"`
+	LastError    string     `json:"last_error,omitempty" db:"last_error"`
+	NextSyncAt   time.Time  `json:"next_sync_at" db:"next_sync_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+type ConnectCalendarRequest struct {
+	CalendarURL string `json:"calendar_url" binding:"required"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+}
+
+// BusyBlock is time a provider is unavailable for reasons outside the app,
+// such as an event in their own calendar.
+type BusyBlock struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	ProviderID uuid.UUID `json:"provider_id" db:"provider_id"`
+	StartTime  time.Time `json:"start_time" db:"start_time"`
+	EndTime    time.Time `json:"end_time" db:"end_time"`
+	Source     string    `json:"source" db:"source"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+}
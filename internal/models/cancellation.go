@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Default policy applied to providers that have not configured their own:
+// free cancellation up to a day ahead and no fees.
+const DefaultFreeCancellationHours = 24
+
+type CancellationPolicy struct {
+	ProviderID            uuid.UUID `json:"provider_id" db:"provider_id"`
+	FreeCancellationHours int       `json:"free_cancellation_hours" db:"free_cancellation_hours"`
+	LateCancelFeePercent  float64   `json:"late_cancel_fee_percent" db:"late_cancel_fee_percent"`
+	NoShowFeePercent      float64   `json:"no_show_fee_percent" db:"no_show_fee_percent"`
+	// NoShowThreshold is the number of no-shows at which NoShowAction kicks
+	// in for an owner; zero disables the check.
+	NoShowThreshold int          `json:"no_show_threshold" db:"no_show_threshold"`
+	NoShowAction    NoShowAction `json:"no_show_action" db:"no_show_action"`
+	// DepositPercent of the total is collected when the booking is made.
+	DepositPercent float64   `json:"deposit_percent" db:"deposit_percent"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type NoShowAction string
+
+const (
+	NoShowActionNone           NoShowAction = "none"
+	NoShowActionRequireDeposit NoShowAction = "require_deposit"
+	NoShowActionBlock          NoShowAction = "block"
+)
+
+type UpdateCancellationPolicyRequest struct {
+	FreeCancellationHours *int          `json:"free_cancellation_hours" binding:"omitempty,min=0"`
+	LateCancelFeePercent  *float64      `json:"late_cancel_fee_percent" binding:"omitempty,min=0,max=100"`
+	NoShowFeePercent      *float64      `json:"no_show_fee_percent" binding:"omitempty,min=0,max=100"`
+	NoShowThreshold       *int          `json:"no_show_threshold" binding:"omitempty,min=0"`
+	NoShowAction          *NoShowAction `json:"no_show_action" binding:"omitempty,oneof=none require_deposit block"`
+	DepositPercent        *float64      `json:"deposit_percent" binding:"omitempty,min=0,max=100"`
+}
+
+type CancelBookingRequest struct {
+	Reason string          `json:"reason"`
+	Scope  RecurrenceScope `json:"scope"`
+}
+
+type CancellationResult struct {
+	Bookings []Booking `json:"bookings"`
+	TotalFee Money     `json:"total_fee"`
+}
+
+// IsLateCancellation reports whether cancelling at `at` falls inside the
+// policy's fee window before the scheduled time.
+func (p CancellationPolicy) IsLateCancellation(scheduled, at time.Time) bool {
+	return scheduled.Sub(at) < time.Duration(p.FreeCancellationHours)*time.Hour
+}
+
+// CancellationFee returns the fee an owner owes for cancelling a booking worth
+// total at `at`.
+func (p CancellationPolicy) CancellationFee(total Money, scheduled, at time.Time) Money {
+	if !p.IsLateCancellation(scheduled, at) {
+		return Zero(total.Currency)
+	}
+	return total.Percent(p.LateCancelFeePercent)
+}
+
+// ChargesLateFee reports whether an owner cancelling at `at` pays for the
+// booking. Prepaid credits are forfeited in the same circumstances.
+func (p CancellationPolicy) ChargesLateFee(scheduled, at time.Time) bool {
+	return p.LateCancelFeePercent > 0 && p.IsLateCancellation(scheduled, at)
+}
+
+// ActionFor returns what the policy does with an owner who has noShows
+// recorded no-shows.
+func (p CancellationPolicy) ActionFor(noShows int) NoShowAction {
+	if p.NoShowThreshold == 0 || noShows < p.NoShowThreshold {
+		return NoShowActionNone
+	}
+	return p.NoShowAction
+}
+
+// DepositFor returns the deposit due on a booking worth total. Owners flagged
+// by the no-show policy prepay in full when the provider takes no regular
+// deposit.
+func (p CancellationPolicy) DepositFor(total Money, required bool) Money {
+	if required && p.DepositPercent == 0 {
+		return total
+	}
+	return total.Percent(p.DepositPercent)
+}
+
+// NoShowFee returns the fee an owner owes for missing a booking worth total.
+func (p CancellationPolicy) NoShowFee(total Money) Money {
+	return total.Percent(p.NoShowFeePercent)
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLateCancellationCutoff(t *testing.T) {
+	scheduled := time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC)
+	policy := CancellationPolicy{FreeCancellationHours: 24, LateCancelFeePercent: 50}
+	tests := []struct {
+		name string
+		at   time.Time
+		late bool
+	}{
+		{"days ahead", scheduled.Add(-72 * time.Hour), false},
+		{"exactly at the cutoff", scheduled.Add(-24 * time.Hour), false},
+		{"a second past the cutoff", scheduled.Add(-24*time.Hour + time.Second), true},
+		{"an hour before", scheduled.Add(-time.Hour), true},
+		{"after the start", scheduled.Add(time.Hour), true},
+	}
+	for _, tt := range tests {
+		if got := policy.IsLateCancellation(scheduled, tt.at); got != tt.late {
+			t.Errorf("%s: IsLateCancellation = %v, want %v", tt.name, got, tt.late)
+		}
+		if got := policy.ChargesLateFee(scheduled, tt.at); got != tt.late {
+			t.Errorf("%s: ChargesLateFee = %v, want %v", tt.name, got, tt.late)
+		}
+	}
+
+	// With no free window only cancelling after the start is late.
+	none := CancellationPolicy{LateCancelFeePercent: 50}
+	if none.IsLateCancellation(scheduled, scheduled.Add(-time.Second)) || !none.IsLateCancellation(scheduled, scheduled.Add(time.Second)) {
+		t.Error("zero free hours should only make cancelling after the start late")
+	}
+	// A late cancellation without a fee charges nothing.
+	free := CancellationPolicy{FreeCancellationHours: 24}
+	if free.ChargesLateFee(scheduled, scheduled.Add(-time.Hour)) {
+		t.Error("a policy without a late fee charges one")
+	}
+}
+
+func TestCancellationFee(t *testing.T) {
+	scheduled := time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC)
+	late, early := scheduled.Add(-time.Hour), scheduled.Add(-48*time.Hour)
+	tests := []struct {
+		name    string
+		percent float64
+		total   Money
+		at      time.Time
+		want    Money
+	}{
+		{"early is free", 50, NewMoney(8000, "USD"), early, NewMoney(0, "USD")},
+		{"no fee", 0, NewMoney(8000, "USD"), late, NewMoney(0, "USD")},
+		{"part of the total", 25, NewMoney(8000, "USD"), late, NewMoney(2000, "USD")},
+		{"rounded to the minor unit", 33, NewMoney(1999, "USD"), late, NewMoney(660, "USD")},
+		{"the whole total", 100, NewMoney(8000, "USD"), late, NewMoney(8000, "USD")},
+		// The fee is always in the booking's currency, whatever its
+		// minor unit.
+		{"zero-decimal currency", 50, NewMoney(4999, "JPY"), late, NewMoney(2500, "JPY")},
+		{"three-decimal currency", 10, NewMoney(12345, "KWD"), late, NewMoney(1235, "KWD")},
+		{"early in another currency", 50, NewMoney(8000, "EUR"), early, NewMoney(0, "EUR")},
+	}
+	for _, tt := range tests {
+		policy := CancellationPolicy{FreeCancellationHours: 24, LateCancelFeePercent: tt.percent}
+		if got := policy.CancellationFee(tt.total, scheduled, tt.at); got != tt.want {
+			t.Errorf("%s: CancellationFee = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNoShowFee(t *testing.T) {
+	policy := CancellationPolicy{NoShowFeePercent: 100}
+	if got := policy.NoShowFee(NewMoney(4500, "GBP")); got != NewMoney(4500, "GBP") {
+		t.Errorf("NoShowFee = %s, want the whole total", got)
+	}
+	if got := (CancellationPolicy{}).NoShowFee(NewMoney(4500, "EUR")); got != NewMoney(0, "EUR") {
+		t.Errorf("NoShowFee without a fee = %s, want 0.00 EUR", got)
+	}
+}
+
+func TestNoShowActionFor(t *testing.T) {
+	policy := CancellationPolicy{NoShowThreshold: 2, NoShowAction: NoShowActionBlock}
+	tests := []struct {
+		noShows int
+		want    NoShowAction
+	}{
+		{0, NoShowActionNone},
+		{1, NoShowActionNone},
+		{2, NoShowActionBlock},
+		{5, NoShowActionBlock},
+	}
+	for _, tt := range tests {
+		if got := policy.ActionFor(tt.noShows); got != tt.want {
+			t.Errorf("ActionFor(%d) = %q, want %q", tt.noShows, got, tt.want)
+		}
+	}
+
+	disabled := CancellationPolicy{NoShowAction: NoShowActionBlock}
+	if got := disabled.ActionFor(10); got != NoShowActionNone {
+		t.Errorf("a zero threshold gave %q, want none", got)
+	}
+}
+
+func TestDepositFor(t *testing.T) {
+	total := NewMoney(8000, "EUR")
+	tests := []struct {
+		name     string
+		percent  float64
+		required bool
+		want     Money
+	}{
+		{"no deposit", 0, false, NewMoney(0, "EUR")},
+		{"regular deposit", 25, false, NewMoney(2000, "EUR")},
+		// Flagged owners prepay in full only when there is no regular
+		// deposit; otherwise they pay the regular one like everyone.
+		{"flagged without a regular deposit", 0, true, total},
+		{"flagged with a regular deposit", 25, true, NewMoney(2000, "EUR")},
+		{"full deposit", 100, false, total},
+	}
+	for _, tt := range tests {
+		policy := CancellationPolicy{DepositPercent: tt.percent}
+		if got := policy.DepositFor(total, tt.required); got != tt.want {
+			t.Errorf("%s: DepositFor = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
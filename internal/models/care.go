@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CareLogKind is what was done for a pet during a stay.
+type CareLogKind string
+
+const (
+	CareFeeding    CareLogKind = "feeding"
+	CareWalk       CareLogKind = "walk"
+	CareMedication CareLogKind = "medication"
+	CarePotty      CareLogKind = "potty"
+	CarePlay       CareLogKind = "play"
+	CareGrooming   CareLogKind = "grooming"
+	CareNote       CareLogKind = "note"
+)
+
+// CareLogEntry records one thing the provider did for a pet during a
+// boarding or sitting stay, such as a meal or a dose of medication.
+type CareLogEntry struct {
+	ID        uuid.UUID   `json:"id" db:"id"`
+	BookingID uuid.UUID   `json:"booking_id" db:"booking_id"`
+	AuthorID  uuid.UUID   `json:"author_id" db:"author_id"`
+	Kind      CareLogKind `json:"kind" db:"kind"`
+	Notes     string      `json:"notes" db:"notes"`
+	LoggedAt  time.Time   `json:"logged_at" db:"logged_at"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
+type CreateCareLogRequest struct {
+	Kind  CareLogKind `json:"kind" binding:"required,oneof=feeding walk medication potty play grooming note"`
+	Notes string      `json:"notes"`
+	// LoggedAt is when the care was given; it defaults to now.
+	LoggedAt *time.Time `json:"logged_at"`
+}
+
+// CareLogDay holds a stay's care log entries for one day, in the
+// provider's time zone. Date is formatted 2006-01-02.
+type CareLogDay struct {
+	Date    string         `json:"date"`
+	Entries []CareLogEntry `json:"entries"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// ProviderCustomer is an owner as seen by a provider they have booked with,
+// including their booking reliability.
+type ProviderCustomer struct {
+	User         User         `json:"user"`
+	BookingCount int          `json:"booking_count"`
+	NoShowCount  int          `json:"no_show_count"`
+	TotalNoShows int          `json:"total_no_shows"`
+	NoShowAction NoShowAction `json:"no_show_action"`
+}
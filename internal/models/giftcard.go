@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GiftCard is stored value redeemable with one provider. Its code is the
+// bearer credential, so it is only returned to the purchaser and provider.
+type GiftCard struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	Code          string     `json:"code" db:"code"`
+	ProviderID    uuid.UUID  `json:"provider_id" db:"provider_id"`
+	PurchaserID   *uuid.UUID `json:"purchaser_id,omitempty" db:"purchaser_id"`
+	InitialAmount Money      `json:"initial_amount" db:"initial_amount"`
+	Balance       Money      `json:"balance" db:"balance"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type GiftCardTransactionKind string
+
+const (
+	GiftCardIssue  GiftCardTransactionKind = "issue"
+	GiftCardRedeem GiftCardTransactionKind = "redeem"
+	GiftCardRefund GiftCardTransactionKind = "refund"
+)
+
+// GiftCardTransaction is one entry in a card's ledger. Amount is always
+// positive; Kind says which way it moved the balance.
+type GiftCardTransaction struct {
+	ID           uuid.UUID               `json:"id" db:"id"`
+	GiftCardID   uuid.UUID               `json:"gift_card_id" db:"gift_card_id"`
+	Kind         GiftCardTransactionKind `json:"kind" db:"kind"`
+	Amount       Money                   `json:"amount" db:"amount"`
+	BalanceAfter Money                   `json:"balance_after" db:"balance_after"`
+	BookingID    *uuid.UUID              `json:"booking_id,omitempty" db:"booking_id"`
+	CreatedAt    time.Time               `json:"created_at" db:"created_at"`
+}
+
+type GiftCardDetails struct {
+	GiftCard
+	Transactions []GiftCardTransaction `json:"transactions"`
+}
+
+type PurchaseGiftCardRequest struct {
+	ProviderID    uuid.UUID `json:"provider_id" binding:"required"`
+	Amount        Money     `json:"amount" binding:"required"`
+	PaymentMethod string    `json:"payment_method" binding:"required"`
+}
+
+type IssueGiftCardRequest struct {
+	Amount Money `json:"amount" binding:"required"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InvoiceLineKind classifies an invoice line so totals and renderers can
+// treat tax and tips separately from the goods and services sold.
+type InvoiceLineKind string
+
+const (
+	InvoiceLineService  InvoiceLineKind = "service"
+	InvoiceLineFee      InvoiceLineKind = "fee"
+	InvoiceLineDiscount InvoiceLineKind = "discount"
+	InvoiceLineTax      InvoiceLineKind = "tax"
+	InvoiceLineTip      InvoiceLineKind = "tip"
+)
+
+type InvoiceLine struct {
+	Kind        InvoiceLineKind `json:"kind"`
+	Description string          `json:"description"`
+	Quantity    int             `json:"quantity"`
+	UnitAmount  Money           `json:"unit_amount"`
+	Amount      Money           `json:"amount"`
+}
+
+// Invoice is the immutable record issued when a booking is completed.
+// Number is sequential per provider; InvoiceNumber is its display form.
+type Invoice struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	BookingID     uuid.UUID `json:"booking_id" db:"booking_id"`
+	ProviderID    uuid.UUID `json:"provider_id" db:"provider_id"`
+	UserID        uuid.UUID `json:"user_id" db:"user_id"`
+	Number        int       `json:"number" db:"number"`
+	InvoiceNumber string    `json:"invoice_number" db:"invoice_number"`
+	IssuedAt      time.Time `json:"issued_at" db:"issued_at"`
+	ProviderName  string    `json:"provider_name" db:"provider_name"`
+	// TaxRegistrationNumber is the provider's number when the invoice was
+	// issued.
+	TaxRegistrationNumber string        `json:"tax_registration_number,omitempty" db:"tax_registration_number"`
+	CustomerName          string        `json:"customer_name" db:"customer_name"`
+	PetName               string        `json:"pet_name" db:"pet_name"`
+	ServiceDate           time.Time     `json:"service_date" db:"service_date"`
+	Lines                 []InvoiceLine `json:"lines"`
+	Subtotal              Money         `json:"subtotal" db:"subtotal"`
+	TaxTotal              Money         `json:"tax_total" db:"tax_total"`
+	TipTotal              Money         `json:"tip_total" db:"tip_total"`
+	Total                 Money         `json:"total" db:"total"`
+	AmountPaid            Money         `json:"amount_paid" db:"amount_paid"`
+	BalanceDue            Money         `json:"balance_due"`
+}
+
+// SetCurrency stamps the invoice's currency onto each of its totals.
+func (inv *Invoice) SetCurrency(currency string) {
+	inv.Subtotal.Currency = currency
+	inv.TaxTotal.Currency = currency
+	inv.TipTotal.Currency = currency
+	inv.Total.Currency = currency
+	inv.AmountPaid.Currency = currency
+	inv.BalanceDue.Currency = currency
+}
+
+// Summarize recomputes the invoice totals from its lines: tax and tips are
+// reported separately from the subtotal and all three make up the total.
+// Every line must be in currency.
+func (inv *Invoice) Summarize(currency string) error {
+	inv.Subtotal = Zero(currency)
+	inv.TaxTotal = Zero(currency)
+	inv.TipTotal = Zero(currency)
+	for _, line := range inv.Lines {
+		total := &inv.Subtotal
+		switch line.Kind {
+		case InvoiceLineTax:
+			total = &inv.TaxTotal
+		case InvoiceLineTip:
+			total = &inv.TipTotal
+		}
+		sum, err := total.Add(line.Amount)
+		if err != nil {
+			return err
+		}
+		*total = sum
+	}
+	inv.Total = NewMoney(inv.Subtotal.Amount+inv.TaxTotal.Amount+inv.TipTotal.Amount, currency)
+	if inv.AmountPaid.Currency == "" {
+		inv.AmountPaid = Zero(currency)
+	}
+	var err error
+	inv.BalanceDue, err = inv.Total.Sub(inv.AmountPaid)
+	return err
+}
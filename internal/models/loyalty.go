@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Loyalty program rules. Points are worth PointValue minor units of the
+// booking's currency each and lapse PointsLifetime after they are earned.
+const (
+	PointsPerBooking  = 100
+	PointsPerReview   = 25
+	PointsPerReferral = 250
+	PointValue        = 1
+	PointsLifetime    = 365 * 24 * time.Hour
+)
+
+// PointsEntryKind classifies loyalty ledger entries. Earned and refunded
+// points carry an expiry; redemptions and expiries are negative.
+type PointsEntryKind string
+
+const (
+	PointsEarn   PointsEntryKind = "earn"
+	PointsRedeem PointsEntryKind = "redeem"
+	PointsExpire PointsEntryKind = "expire"
+	PointsRefund PointsEntryKind = "refund"
+)
+
+type PointsReason string
+
+const (
+	ReasonBookingCompleted PointsReason = "booking_completed"
+	ReasonReview           PointsReason = "review"
+	ReasonReferral         PointsReason = "referral"
+	ReasonRedemption       PointsReason = "redemption"
+	ReasonCancellation     PointsReason = "cancellation"
+	ReasonExpiry           PointsReason = "expiry"
+)
+
+// PointsEntry is one line of an owner's loyalty ledger. SourceID is the
+// booking, review or referred user the entry relates to.
+type PointsEntry struct {
+	ID        uuid.UUID       `json:"id" db:"id"`
+	UserID    uuid.UUID       `json:"user_id" db:"user_id"`
+	Kind      PointsEntryKind `json:"kind" db:"kind"`
+	Reason    PointsReason    `json:"reason" db:"reason"`
+	Points    int             `json:"points" db:"points"`
+	Remaining int             `json:"-" db:"remaining"`
+	SourceID  *uuid.UUID      `json:"source_id,omitempty" db:"source_id"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+type PointsBalance struct {
+	UserID       uuid.UUID  `json:"user_id"`
+	Points       int        `json:"points"`
+	NextExpiry   *time.Time `json:"next_expiry,omitempty"`
+	ExpiringNext int        `json:"expiring_next"`
+	ReferralCode string     `json:"referral_code"`
+}
+
+// PointsValue is what redeeming points is worth in currency.
+func PointsValue(points int, currency string) Money {
+	return NewMoney(int64(points)*PointValue, currency)
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Limits on what can be attached to a message.
+const (
+	MaxMessageAttachments = 5
+	MaxAttachmentSize     = 5 << 20
+)
+
+// Message is one entry in a booking's thread between its owner and
+// provider. ReadAt is when the other participant read it. Seq increases
+// with each message in a thread and is the cursor for reading new ones.
+type Message struct {
+	ID          uuid.UUID           `json:"id" db:"id"`
+	Seq         int64               `json:"seq" db:"seq"`
+	BookingID   uuid.UUID           `json:"booking_id" db:"booking_id"`
+	SenderID    uuid.UUID           `json:"sender_id" db:"sender_id"`
+	Body        string              `json:"body" db:"body"`
+	Attachments []MessageAttachment `json:"attachments"`
+	ReadAt      *time.Time          `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt   time.Time           `json:"created_at" db:"created_at"`
+}
+
+// MessageAttachment describes a file sent with a message. The content is
+// downloaded separately.
+type MessageAttachment struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	MessageID   uuid.UUID `json:"message_id" db:"message_id"`
+	Filename    string    `json:"filename" db:"filename"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size" db:"size"`
+}
+
+// NewAttachment is an uploaded file not yet stored.
+type NewAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type SendMessageRequest struct {
+	Body string `json:"body"`
+}
+
+type UnreadCount struct {
+	BookingID uuid.UUID `json:"booking_id"`
+	Unread    int       `json:"unread"`
+}
+
+type UnreadSummary struct {
+	Total    int           `json:"total"`
+	Bookings []UnreadCount `json:"bookings"`
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultCurrency is used for services created before prices carried a
+// currency.
+const DefaultCurrency = "USD"
+
+// currencyExponents lists ISO 4217 currencies whose minor unit is not a
+// hundredth of the major unit.
+var currencyExponents = map[string]int{
+	"BHD": 3, "CLP": 0, "ISK": 0, "JOD": 3, "JPY": 0,
+	"KRW": 0, "KWD": 3, "OMR": 3, "TND": 3, "VND": 0,
+}
+
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money is an exact amount in the minor units (e.g. cents) of an ISO 4217
+// currency. Arithmetic between different currencies fails with
+// ErrCurrencyMismatch rather than guessing at an exchange rate.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency" binding:"omitempty,len=3,uppercase"`
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Zero returns a zero amount in currency.
+func Zero(currency string) Money {
+	return Money{Currency: currency}
+}
+
+func (m Money) match(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.match(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.match(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}, nil
+}
+
+// Percent returns pct percent of m, rounded half away from zero to the
+// nearest minor unit.
+func (m Money) Percent(pct float64) Money {
+	return Money{Amount: int64(math.Round(float64(m.Amount) * pct / 100)), Currency: m.Currency}
+}
+
+// Times returns m multiplied by n, such as a nightly rate over a stay.
+func (m Money) Times(n int) Money {
+	return Money{Amount: m.Amount * int64(n), Currency: m.Currency}
+}
+
+func (m Money) Min(other Money) (Money, error) {
+	if err := m.match(other); err != nil {
+		return Money{}, err
+	}
+	if other.Amount < m.Amount {
+		return other, nil
+	}
+	return m, nil
+}
+
+func (m Money) IsZero() bool     { return m.Amount == 0 }
+func (m Money) IsPositive() bool { return m.Amount > 0 }
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+
+// SameCurrency reports whether m and other can be combined.
+func (m Money) SameCurrency(other Money) bool {
+	return m.Currency == other.Currency
+}
+
+// String formats the amount in major units, e.g. "12.50 USD".
+func (m Money) String() string {
+	exp, ok := currencyExponents[m.Currency]
+	if !ok {
+		exp = 2
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if exp == 0 {
+		return fmt.Sprintf("%s%d %s", sign, amount, m.Currency)
+	}
+
+	unit := int64(math.Pow10(exp))
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/unit, exp, amount%unit, m.Currency)
+}
+
+// NormalizeCurrency upper-cases a currency code, falling back to
+// DefaultCurrency when it is empty.
+func NormalizeCurrency(currency string) string {
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return strings.ToUpper(currency)
+}
+
+// CurrencyOr upper-cases a currency code, falling back to fallback when it is
+// empty. Amounts given against an existing booking default to its currency.
+func CurrencyOr(currency, fallback string) string {
+	if currency == "" {
+		return fallback
+	}
+	return strings.ToUpper(currency)
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMoneyArithmetic(t *testing.T) {
+	a, b := NewMoney(1250, "USD"), NewMoney(300, "USD")
+
+	if sum, err := a.Add(b); err != nil || sum != NewMoney(1550, "USD") {
+		t.Errorf("Add = %v, %v", sum, err)
+	}
+	if diff, err := b.Sub(a); err != nil || diff != NewMoney(-950, "USD") {
+		t.Errorf("Sub = %v, %v", diff, err)
+	}
+	if min, err := a.Min(b); err != nil || min != b {
+		t.Errorf("Min = %v, %v", min, err)
+	}
+	if got := a.Times(3); got != NewMoney(3750, "USD") {
+		t.Errorf("Times = %v", got)
+	}
+}
+
+func TestMoneyCurrencyMismatch(t *testing.T) {
+	usd, eur := NewMoney(100, "USD"), NewMoney(100, "EUR")
+
+	if _, err := usd.Add(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add: %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := usd.Sub(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sub: %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := usd.Min(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Min: %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestMoneyPercentRounding(t *testing.T) {
+	tests := []struct {
+		amount int64
+		pct    float64
+		want   int64
+	}{
+		{1000, 25, 250},
+		{999, 50, 500},   // 499.5 rounds away from zero
+		{-999, 50, -500}, // and so does -499.5
+		{1, 10, 0},
+		{12345, 8.875, 1096},
+	}
+	for _, tt := range tests {
+		if got := NewMoney(tt.amount, "USD").Percent(tt.pct); got.Amount != tt.want {
+			t.Errorf("%d × %v%% = %d, want %d", tt.amount, tt.pct, got.Amount, tt.want)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{NewMoney(1250, "USD"), "12.50 USD"},
+		{NewMoney(5, "EUR"), "0.05 EUR"},
+		{NewMoney(-1250, "GBP"), "-12.50 GBP"},
+		{NewMoney(1500, "JPY"), "1500 JPY"},
+		{NewMoney(12345, "KWD"), "12.345 KWD"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	if got := NormalizeCurrency(""); got != DefaultCurrency {
+		t.Errorf("empty currency = %q, want %q", got, DefaultCurrency)
+	}
+	if got := NormalizeCurrency("eur"); got != "EUR" {
+		t.Errorf("lower-case currency = %q, want EUR", got)
+	}
+}
+
+func TestCurrencyOr(t *testing.T) {
+	if got := CurrencyOr("", "EUR"); got != "EUR" {
+		t.Errorf("empty currency = %q, want the fallback EUR", got)
+	}
+	if got := CurrencyOr("gbp", "EUR"); got != "GBP" {
+		t.Errorf("given currency = %q, want GBP", got)
+	}
+}
+
+func TestInvoiceSummarize(t *testing.T) {
+	inv := Invoice{Lines: []InvoiceLine{
+		{Kind: InvoiceLineService, Amount: NewMoney(5000, "USD")},
+		{Kind: InvoiceLineDiscount, Amount: NewMoney(-500, "USD")},
+		{Kind: InvoiceLineTax, Amount: NewMoney(450, "USD")},
+		{Kind: InvoiceLineTip, Amount: NewMoney(1000, "USD")},
+	}, AmountPaid: NewMoney(2000, "USD")}
+
+	if err := inv.Summarize("USD"); err != nil {
+		t.Fatal(err)
+	}
+	if inv.Subtotal.Amount != 4500 || inv.TaxTotal.Amount != 450 || inv.TipTotal.Amount != 1000 {
+		t.Errorf("subtotal %s, tax %s, tips %s", inv.Subtotal, inv.TaxTotal, inv.TipTotal)
+	}
+	if inv.Total.Amount != 5950 || inv.BalanceDue.Amount != 3950 {
+		t.Errorf("total %s, balance due %s", inv.Total, inv.BalanceDue)
+	}
+
+	inv.Lines = append(inv.Lines, InvoiceLine{Kind: InvoiceLineFee, Amount: NewMoney(100, "EUR")})
+	if err := inv.Summarize("USD"); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("mixed currencies: %v, want ErrCurrencyMismatch", err)
+	}
+}
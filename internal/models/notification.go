@@ -0,0 +1,166 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationEvent string
+
+const (
+	EventBookingCreated     NotificationEvent = "booking_created"
+	EventBookingConfirmed   NotificationEvent = "booking_confirmed"
+	EventBookingRescheduled NotificationEvent = "booking_rescheduled"
+	EventBookingCancelled   NotificationEvent = "booking_cancelled"
+	EventBookingReminder    NotificationEvent = "booking_reminder"
+	EventWaitlistOffer      NotificationEvent = "waitlist_offer"
+)
+
+var NotificationEvents = []NotificationEvent{
+	EventBookingCreated, EventBookingConfirmed, EventBookingRescheduled,
+	EventBookingCancelled, EventBookingReminder, EventWaitlistOffer,
+}
+
+func (e NotificationEvent) IsValid() bool {
+	for _, known := range NotificationEvents {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+type NotificationChannel string
+
+const (
+	ChannelEmail NotificationChannel = "email"
+	ChannelSMS   NotificationChannel = "sms"
+	ChannelPush  NotificationChannel = "push"
+)
+
+func (c NotificationChannel) IsValid() bool {
+	return c == ChannelEmail || c == ChannelSMS || c == ChannelPush
+}
+
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending"
+	NotificationSent    NotificationStatus = "sent"
+	NotificationFailed  NotificationStatus = "failed"
+	// NotificationSkipped marks messages the recipient turned off while they
+	// were waiting to be sent.
+	NotificationSkipped NotificationStatus = "skipped"
+)
+
+// Notification is a rendered message in the outbox. It is written in the
+// same transaction as the change it reports and delivered afterwards, so
+// nothing is lost if the server stops in between.
+type Notification struct {
+	ID            uuid.UUID           `json:"id" db:"id"`
+	UserID        uuid.UUID           `json:"user_id" db:"user_id"`
+	BookingID     *uuid.UUID          `json:"booking_id,omitempty" db:"booking_id"`
+	Event         NotificationEvent   `json:"event" db:"event"`
+	Channel       NotificationChannel `json:"channel" db:"channel"`
+	Recipient     string              `json:"recipient" db:"recipient"`
+	Subject       string              `json:"subject" db:"subject"`
+	Body          string              `json:"body" db:"body"`
+	Status        NotificationStatus  `json:"status" db:"status"`
+	Attempts      int                 `json:"attempts" db:"attempts"`
+	LastError     string              `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt time.Time           `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt     time.Time           `json:"created_at" db:"created_at"`
+	SentAt        *time.Time          `json:"sent_at,omitempty" db:"sent_at"`
+}
+
+// NotificationPreferences control when and how a user is notified. Users
+// without a row get DefaultNotificationPreferences.
+type NotificationPreferences struct {
+	UserID uuid.UUID `json:"user_id" db:"user_id"`
+	// MutedEvents are never sent; everything else, including events added
+	// later, is.
+	MutedEvents []NotificationEvent `json:"muted_events" db:"muted_events"`
+	// Channels are the only channels used, so owners who want one message
+	// per event pick a single channel.
+	Channels []NotificationChannel `json:"channels" db:"channels"`
+	Language string                `json:"language" db:"language"`
+	TimeZone string                `json:"time_zone" db:"time_zone"`
+	// QuietHoursStart and QuietHoursEnd are local hours of the day between
+	// which SMS and push messages are held back; the period may wrap past
+	// midnight. Equal or unset values disable quiet hours.
+	QuietHoursStart *int      `json:"quiet_hours_start" db:"quiet_hours_start"`
+	QuietHoursEnd   *int      `json:"quiet_hours_end" db:"quiet_hours_end"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultNotificationPreferences send every event by email and push, in
+// English and UTC. SMS is opt-in.
+func DefaultNotificationPreferences(userID uuid.UUID) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:      userID,
+		MutedEvents: []NotificationEvent{},
+		Channels:    []NotificationChannel{ChannelEmail, ChannelPush},
+		Language:    "en",
+		TimeZone:    "UTC",
+	}
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	MutedEvents     *[]NotificationEvent   `json:"muted_events"`
+	Channels        *[]NotificationChannel `json:"channels"`
+	Language        *string                `json:"language"`
+	TimeZone        *string                `json:"time_zone"`
+	QuietHoursStart *int                   `json:"quiet_hours_start" binding:"omitempty,min=0,max=23"`
+	QuietHoursEnd   *int                   `json:"quiet_hours_end" binding:"omitempty,min=0,max=23"`
+}
+
+// Wants reports whether the user receives event on channel.
+func (p NotificationPreferences) Wants(event NotificationEvent, channel NotificationChannel) bool {
+	for _, muted := range p.MutedEvents {
+		if muted == event {
+			return false
+		}
+	}
+	for _, c := range p.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Location returns the user's time zone, falling back to UTC if it no longer
+// loads.
+func (p NotificationPreferences) Location() *time.Location {
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// QuietUntil returns when a message due at t may be delivered: t itself, or
+// the end of the quiet period t falls in.
+func (p NotificationPreferences) QuietUntil(t time.Time) time.Time {
+	if p.QuietHoursStart == nil || p.QuietHoursEnd == nil || *p.QuietHoursStart == *p.QuietHoursEnd {
+		return t
+	}
+
+	start, end := *p.QuietHoursStart, *p.QuietHoursEnd
+	local := t.In(p.Location())
+	hour := local.Hour()
+	quiet := hour >= start && hour < end
+	if start > end {
+		quiet = hour >= start || hour < end
+	}
+	if !quiet {
+		return t
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end, 0, 0, 0, local.Location())
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNotificationPreferencesWants(t *testing.T) {
+	prefs := DefaultNotificationPreferences(uuid.New())
+	if !prefs.Wants(EventBookingCreated, ChannelEmail) || !prefs.Wants(EventBookingCreated, ChannelPush) {
+		t.Error("defaults should send by email and push")
+	}
+	if prefs.Wants(EventBookingCreated, ChannelSMS) {
+		t.Error("defaults should not send SMS")
+	}
+
+	prefs.MutedEvents = []NotificationEvent{EventBookingReminder}
+	if prefs.Wants(EventBookingReminder, ChannelEmail) {
+		t.Error("a muted event was wanted")
+	}
+}
+
+func TestQuietUntil(t *testing.T) {
+	hour := func(h int) *int { return &h }
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	at := func(day, h, m int) time.Time { return time.Date(2026, 3, day, h, m, 0, 0, newYork) }
+
+	tests := []struct {
+		name       string
+		start, end *int
+		t, want    time.Time
+	}{
+		{"no quiet hours", nil, nil, at(10, 23, 0), at(10, 23, 0)},
+		{"outside overnight hours", hour(22), hour(7), at(10, 21, 59), at(10, 21, 59)},
+		{"late evening", hour(22), hour(7), at(10, 23, 30), at(11, 7, 0)},
+		{"early morning", hour(22), hour(7), at(11, 3, 0), at(11, 7, 0)},
+		{"daytime hours", hour(12), hour(14), at(10, 13, 15), at(10, 14, 0)},
+		{"end is not quiet", hour(12), hour(14), at(10, 14, 0), at(10, 14, 0)},
+		// Clocks go forward on 8 March 2026; the end is still 07:00 local.
+		{"across DST", hour(22), hour(7), at(7, 23, 0), at(8, 7, 0)},
+	}
+	for _, tt := range tests {
+		prefs := NotificationPreferences{TimeZone: "America/New_York", QuietHoursStart: tt.start, QuietHoursEnd: tt.end}
+		if got := prefs.QuietUntil(tt.t.UTC()); !got.Equal(tt.want) {
+			t.Errorf("%s: QuietUntil(%s) = %s, want %s", tt.name, tt.t, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Organization is the business behind a provider account, such as a salon.
+// It is owned by the provider user, whose ID stays the provider_id on the
+// salon's services and bookings. Without active staff the provider works
+// alone and is booked directly.
+type Organization struct {
+	ID      uuid.UUID `json:"id" db:"id"`
+	OwnerID uuid.UUID `json:"owner_id" db:"owner_id"`
+	Name    string    `json:"name" db:"name"`
+	// TimeZone is where staff schedules are read.
+	TimeZone  string    `json:"time_zone" db:"time_zone"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Location returns the organization's time zone, falling back to UTC if it
+// no longer loads.
+func (o Organization) Location() *time.Location {
+	loc, err := time.LoadLocation(o.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+type SaveOrganizationRequest struct {
+	Name     string `json:"name" binding:"required"`
+	TimeZone string `json:"time_zone"`
+}
+
+// StaffShift is a weekly period a staff member works, in the
+// organization's time zone. Start and End are "15:04" times on Weekday.
+type StaffShift struct {
+	Weekday time.Weekday `json:"weekday" binding:"min=0,max=6"`
+	Start   string       `json:"start" binding:"required"`
+	End     string       `json:"end" binding:"required"`
+}
+
+// StaffMember is a groomer working for an organization. They can be booked
+// for the services in ServiceIDs during their Schedule.
+type StaffMember struct {
+	ID             uuid.UUID    `json:"id" db:"id"`
+	OrganizationID uuid.UUID    `json:"organization_id" db:"organization_id"`
+	Name           string       `json:"name" db:"name"`
+	Email          string       `json:"email,omitempty" db:"email"`
+	Active         bool         `json:"active" db:"active"`
+	ServiceIDs     []uuid.UUID  `json:"service_ids"`
+	Schedule       []StaffShift `json:"schedule"`
+	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+}
+
+type CreateStaffRequest struct {
+	Name       string       `json:"name" binding:"required"`
+	Email      string       `json:"email"`
+	ServiceIDs []uuid.UUID  `json:"service_ids"`
+	Schedule   []StaffShift `json:"schedule" binding:"dive"`
+}
+
+// UpdateStaffRequest changes the fields that are set; ServiceIDs and
+// Schedule replace the existing lists.
+type UpdateStaffRequest struct {
+	Name       *string       `json:"name"`
+	Email      *string       `json:"email"`
+	ServiceIDs *[]uuid.UUID  `json:"service_ids"`
+	Schedule   *[]StaffShift `json:"schedule" binding:"omitempty,dive"`
+}
+
+// StaffAvailability describes a staff member who performs a service and,
+// when a time was asked about, whether they are free then.
+type StaffAvailability struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Available *bool     `json:"available,omitempty"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PackageKind distinguishes a bundle of a fixed number of visits from a
+// membership that covers unlimited visits while it is valid.
+type PackageKind string
+
+const (
+	PackageKindBundle     PackageKind = "package"
+	PackageKindMembership PackageKind = "membership"
+)
+
+// Package is a prepaid product a provider sells, such as "5 baths for the
+// price of 4" or a monthly nail-trim membership. Category limits it to the
+// provider's services in that category; empty covers all of them.
+type Package struct {
+	ID           uuid.UUID   `json:"id" db:"id"`
+	ProviderID   uuid.UUID   `json:"provider_id" db:"provider_id"`
+	Name         string      `json:"name" db:"name"`
+	Description  string      `json:"description" db:"description"`
+	Kind         PackageKind `json:"kind" db:"kind"`
+	Category     ServiceType `json:"category,omitempty" db:"category"`
+	Credits      int         `json:"credits" db:"credits"`
+	Price        Money       `json:"price" db:"price"`
+	ValidityDays int         `json:"validity_days" db:"validity_days"`
+	Active       bool        `json:"active" db:"active"`
+	CreatedAt    time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+type CreatePackageRequest struct {
+	Name        string      `json:"name" binding:"required"`
+	Description string      `json:"description"`
+	Kind        PackageKind `json:"kind" binding:"required,oneof=package membership"`
+	Category    ServiceType `json:"category"`
+	// Credits is the number of visits in a package; memberships leave it
+	// at zero.
+	Credits      int   `json:"credits" binding:"min=0"`
+	Price        Money `json:"price" binding:"required"`
+	ValidityDays int   `json:"validity_days" binding:"required,min=1"`
+}
+
+type PurchasePackageRequest struct {
+	PaymentMethod string `json:"payment_method" binding:"required"`
+}
+
+// Credit is an owner's purchased package. CreditsRemaining is nil for
+// memberships, which are unlimited until they expire.
+type Credit struct {
+	ID               uuid.UUID   `json:"id" db:"id"`
+	UserID           uuid.UUID   `json:"user_id" db:"user_id"`
+	PackageID        uuid.UUID   `json:"package_id" db:"package_id"`
+	ProviderID       uuid.UUID   `json:"provider_id" db:"provider_id"`
+	Name             string      `json:"name" db:"name"`
+	Kind             PackageKind `json:"kind" db:"kind"`
+	Category         ServiceType `json:"category,omitempty" db:"category"`
+	CreditsRemaining *int        `json:"credits_remaining,omitempty" db:"credits_remaining"`
+	PricePaid        Money       `json:"price_paid" db:"price_paid"`
+	TransactionID    string      `json:"-" db:"transaction_id"`
+	PurchasedAt      time.Time   `json:"purchased_at" db:"purchased_at"`
+	ExpiresAt        time.Time   `json:"expires_at" db:"expires_at"`
+}
+
+// Covers reports whether the credit can pay for a service in category
+// scheduled at `at`.
+func (c Credit) Covers(category ServiceType, at time.Time) bool {
+	if c.Category != "" && c.Category != category {
+		return false
+	}
+	return at.Before(c.ExpiresAt)
+}
+
+// Exhausted reports whether a package has no visits left.
+func (c Credit) Exhausted() bool {
+	return c.CreditsRemaining != nil && *c.CreditsRemaining <= 0
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentStatus summarises the money collected for a booking.
+type PaymentStatus string
+
+const (
+	PaymentUnpaid            PaymentStatus = "unpaid"
+	PaymentDepositPaid       PaymentStatus = "deposit_paid"
+	PaymentPaid              PaymentStatus = "paid"
+	PaymentPartiallyRefunded PaymentStatus = "partially_refunded"
+	PaymentRefunded          PaymentStatus = "refunded"
+	PaymentFailed            PaymentStatus = "failed"
+)
+
+type PaymentKind string
+
+const (
+	PaymentKindDeposit PaymentKind = "deposit"
+	PaymentKindBalance PaymentKind = "balance"
+	PaymentKindFee     PaymentKind = "fee"
+	// PaymentKindTip payments are gratuities and do not count towards the
+	// booking's price.
+	PaymentKindTip PaymentKind = "tip"
+	// PaymentKindGiftCard payments are drawn from a gift card; their
+	// transaction ID is the card's ID and refunds go back onto the card.
+	PaymentKindGiftCard PaymentKind = "gift_card"
+)
+
+type PaymentRecordStatus string
+
+const (
+	PaymentRecordCaptured          PaymentRecordStatus = "captured"
+	PaymentRecordPartiallyRefunded PaymentRecordStatus = "partially_refunded"
+	PaymentRecordRefunded          PaymentRecordStatus = "refunded"
+	PaymentRecordFailed            PaymentRecordStatus = "failed"
+)
+
+// Payment is one charge against a booking and the refunds made against it.
+type Payment struct {
+	ID             uuid.UUID           `json:"id" db:"id"`
+	BookingID      uuid.UUID           `json:"booking_id" db:"booking_id"`
+	Kind           PaymentKind         `json:"kind" db:"kind"`
+	TransactionID  string              `json:"transaction_id" db:"transaction_id"`
+	Amount         Money               `json:"amount" db:"amount"`
+	RefundedAmount Money               `json:"refunded_amount" db:"refunded_amount"`
+	Status         PaymentRecordStatus `json:"status" db:"status"`
+	FailureReason  string              `json:"failure_reason,omitempty" db:"failure_reason"`
+	CreatedAt      time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+type RefundRequest struct {
+	// Amount to refund; omitted refunds everything collected so far.
+	Amount *Money `json:"amount"`
+}
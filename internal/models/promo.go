@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DiscountKind string
+
+const (
+	DiscountPercentage DiscountKind = "percentage"
+	DiscountFixed      DiscountKind = "fixed"
+)
+
+// PromoCode is a discount a provider offers on their services. Uses counts
+// the bookings it has been applied to; MaxUses caps them when set. An empty
+// Categories list covers every category.
+type PromoCode struct {
+	ID               uuid.UUID     `json:"id" db:"id"`
+	ProviderID       uuid.UUID     `json:"provider_id" db:"provider_id"`
+	Code             string        `json:"code" db:"code"`
+	Kind             DiscountKind  `json:"kind" db:"kind"`
+	Percent          float64       `json:"percent,omitempty" db:"percent"`
+	Amount           Money         `json:"amount" db:"amount"`
+	MinSpend         Money         `json:"min_spend" db:"min_spend"`
+	ValidFrom        *time.Time    `json:"valid_from,omitempty" db:"valid_from"`
+	ValidUntil       *time.Time    `json:"valid_until,omitempty" db:"valid_until"`
+	MaxUses          *int          `json:"max_uses,omitempty" db:"max_uses"`
+	Uses             int           `json:"uses" db:"uses"`
+	FirstBookingOnly bool          `json:"first_booking_only" db:"first_booking_only"`
+	Categories       []ServiceType `json:"categories" db:"categories"`
+	Active           bool          `json:"active" db:"active"`
+	CreatedAt        time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+type CreatePromoCodeRequest struct {
+	Code             string        `json:"code" binding:"required,alphanum,max=32"`
+	Kind             DiscountKind  `json:"kind" binding:"required,oneof=percentage fixed"`
+	Percent          float64       `json:"percent" binding:"min=0,max=100"`
+	Amount           *Money        `json:"amount"`
+	MinSpend         *Money        `json:"min_spend"`
+	ValidFrom        *time.Time    `json:"valid_from"`
+	ValidUntil       *time.Time    `json:"valid_until"`
+	MaxUses          *int          `json:"max_uses" binding:"omitempty,min=1"`
+	FirstBookingOnly bool          `json:"first_booking_only"`
+	Categories       []ServiceType `json:"categories"`
+}
+
+// ValidAt reports whether the code can be redeemed at t.
+func (p PromoCode) ValidAt(t time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.ValidFrom != nil && t.Before(*p.ValidFrom) {
+		return false
+	}
+	return p.ValidUntil == nil || t.Before(*p.ValidUntil)
+}
+
+// AppliesTo reports whether the code covers a service priced at price in
+// category.
+func (p PromoCode) AppliesTo(category ServiceType, price Money) bool {
+	if p.Kind == DiscountFixed && !p.Amount.SameCurrency(price) {
+		return false
+	}
+	if p.MinSpend.IsPositive() && (!p.MinSpend.SameCurrency(price) || price.Amount < p.MinSpend.Amount) {
+		return false
+	}
+	if len(p.Categories) == 0 {
+		return true
+	}
+	for _, c := range p.Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// DiscountOn returns the reduction the code gives on price, never more than
+// the price itself. A fixed discount in another currency is an error.
+func (p PromoCode) DiscountOn(price Money) (Money, error) {
+	discount := price.Percent(p.Percent)
+	if p.Kind == DiscountFixed {
+		discount = p.Amount
+	}
+	return discount.Min(price)
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPromoDiscountOn(t *testing.T) {
+	tests := []struct {
+		name  string
+		promo PromoCode
+		price Money
+		want  Money
+	}{
+		{"percentage", PromoCode{Kind: DiscountPercentage, Percent: 15}, NewMoney(8000, "USD"), NewMoney(1200, "USD")},
+		{"percentage rounds", PromoCode{Kind: DiscountPercentage, Percent: 15}, NewMoney(999, "USD"), NewMoney(150, "USD")},
+		{"whole price", PromoCode{Kind: DiscountPercentage, Percent: 100}, NewMoney(8000, "EUR"), NewMoney(8000, "EUR")},
+		{"fixed", PromoCode{Kind: DiscountFixed, Amount: NewMoney(1000, "USD")}, NewMoney(8000, "USD"), NewMoney(1000, "USD")},
+		{"fixed above the price", PromoCode{Kind: DiscountFixed, Amount: NewMoney(5000, "USD")}, NewMoney(3000, "USD"), NewMoney(3000, "USD")},
+	}
+	for _, tt := range tests {
+		got, err := tt.promo.DiscountOn(tt.price)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: DiscountOn = %s, %v; want %s", tt.name, got, err, tt.want)
+		}
+	}
+
+	fixed := PromoCode{Kind: DiscountFixed, Amount: NewMoney(1000, "USD")}
+	if _, err := fixed.DiscountOn(NewMoney(8000, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("fixed discount in another currency: %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestPromoAppliesTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		promo    PromoCode
+		category ServiceType
+		price    Money
+		want     bool
+	}{
+		{"any category", PromoCode{Kind: DiscountPercentage, Percent: 10}, ServiceWalking, NewMoney(2000, "USD"), true},
+		{"listed category", PromoCode{Kind: DiscountPercentage, Percent: 10, Categories: []ServiceType{ServiceGrooming, ServiceBoarding}}, ServiceBoarding, NewMoney(2000, "USD"), true},
+		{"other category", PromoCode{Kind: DiscountPercentage, Percent: 10, Categories: []ServiceType{ServiceGrooming}}, ServiceWalking, NewMoney(2000, "USD"), false},
+		{"exactly the minimum spend", PromoCode{Kind: DiscountPercentage, Percent: 10, MinSpend: NewMoney(5000, "USD")}, ServiceGrooming, NewMoney(5000, "USD"), true},
+		{"below the minimum spend", PromoCode{Kind: DiscountPercentage, Percent: 10, MinSpend: NewMoney(5000, "USD")}, ServiceGrooming, NewMoney(4999, "USD"), false},
+		{"minimum spend in another currency", PromoCode{Kind: DiscountPercentage, Percent: 10, MinSpend: NewMoney(5000, "USD")}, ServiceGrooming, NewMoney(9000, "EUR"), false},
+		{"percentage in any currency", PromoCode{Kind: DiscountPercentage, Percent: 10}, ServiceGrooming, NewMoney(9000, "JPY"), true},
+		{"fixed in the same currency", PromoCode{Kind: DiscountFixed, Amount: NewMoney(1000, "EUR")}, ServiceGrooming, NewMoney(500, "EUR"), true},
+		{"fixed in another currency", PromoCode{Kind: DiscountFixed, Amount: NewMoney(1000, "USD")}, ServiceGrooming, NewMoney(9000, "EUR"), false},
+	}
+	for _, tt := range tests {
+		if got := tt.promo.AppliesTo(tt.category, tt.price); got != tt.want {
+			t.Errorf("%s: AppliesTo = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPromoValidAt(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	window := PromoCode{Active: true, ValidFrom: &from, ValidUntil: &until}
+	tests := []struct {
+		name  string
+		promo PromoCode
+		at    time.Time
+		want  bool
+	}{
+		{"open-ended", PromoCode{Active: true}, from, true},
+		{"inactive", PromoCode{}, from, false},
+		{"before the window", window, from.Add(-time.Second), false},
+		{"at the start", window, from, true},
+		{"inside the window", window, from.Add(10 * 24 * time.Hour), true},
+		{"at the end", window, until, false},
+		{"after the window", window, until.Add(time.Hour), false},
+	}
+	for _, tt := range tests {
+		if got := tt.promo.ValidAt(tt.at); got != tt.want {
+			t.Errorf("%s: ValidAt = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxOccurrences caps how many concrete bookings a single recurrence rule may
+// generate, so an open-ended rule cannot flood a provider's calendar.
+const MaxOccurrences = 52
+
+// RecurrenceRule is a small RRULE-style weekly rule: every IntervalWeeks weeks,
+// optionally pinned to a weekday, ending at Until or after Count occurrences.
+type RecurrenceRule struct {
+	IntervalWeeks int           `json:"interval_weeks" binding:"required,min=1,max=52"`
+	Weekday       *time.Weekday `json:"weekday"`
+	Until         *time.Time    `json:"until"`
+	Count         int           `json:"count" binding:"min=0"`
+}
+
+type RecurrenceScope string
+
+const (
+	ScopeThis      RecurrenceScope = "this"
+	ScopeFollowing RecurrenceScope = "following"
+)
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+func (r RecurrenceRule) Validate() error {
+	if r.IntervalWeeks < 1 {
+		return errors.New("interval_weeks must be at least 1")
+	}
+	if r.Weekday != nil && (*r.Weekday < time.Sunday || *r.Weekday > time.Saturday) {
+		return errors.New("weekday must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	if r.Until == nil && r.Count == 0 {
+		return errors.New("recurrence requires either until or count")
+	}
+	if r.Count > MaxOccurrences {
+		return fmt.Errorf("count cannot exceed %d", MaxOccurrences)
+	}
+	return nil
+}
+
+// Occurrences expands the rule from start on the calendar in loc, so a
+// weekly appointment keeps its local time of day across daylight saving
+// changes. When a weekday is set the first occurrence moves forward to that
+// weekday in loc. A rule ending at Until that would run past
+// MaxOccurrences is an error rather than being cut short.
+func (r RecurrenceRule) Occurrences(start time.Time, loc *time.Location) ([]time.Time, error) {
+	first := start.In(loc)
+	if r.Weekday != nil {
+		first = first.AddDate(0, 0, (int(*r.Weekday)-int(first.Weekday())+7)%7)
+	}
+
+	limit := MaxOccurrences
+	if r.Count > 0 && r.Count < limit {
+		limit = r.Count
+	}
+
+	var occurrences []time.Time
+	for i := 0; ; i++ {
+		next := first.AddDate(0, 0, 7*r.IntervalWeeks*i)
+		if r.Until != nil && next.After(*r.Until) {
+			break
+		}
+		if i == limit {
+			if r.Count == 0 {
+				return nil, fmt.Errorf("until allows more than %d occurrences", MaxOccurrences)
+			}
+			break
+		}
+		occurrences = append(occurrences, next)
+	}
+	return occurrences, nil
+}
+
+// String renders the rule as an RFC 5545 RRULE value.
+func (r RecurrenceRule) String() string {
+	parts := []string{"FREQ=WEEKLY", "INTERVAL=" + strconv.Itoa(r.IntervalWeeks)}
+	if r.Weekday != nil {
+		parts = append(parts, "BYDAY="+weekdayCodes[*r.Weekday])
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// ParseRecurrenceRule parses the subset of RRULE produced by String.
+func ParseRecurrenceRule(value string) (RecurrenceRule, error) {
+	rule := RecurrenceRule{IntervalWeeks: 1}
+	for _, part := range strings.Split(strings.TrimPrefix(value, "RRULE:"), ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return rule, fmt.Errorf("invalid rrule part %q", part)
+		}
+		switch key {
+		case "FREQ":
+			if val != "WEEKLY" {
+				return rule, fmt.Errorf("unsupported frequency %q", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return rule, fmt.Errorf("invalid interval %q", val)
+			}
+			rule.IntervalWeeks = n
+		case "BYDAY":
+			found := false
+			for i, code := range weekdayCodes {
+				if code == val {
+					weekday := time.Weekday(i)
+					rule.Weekday = &weekday
+					found = true
+				}
+			}
+			if !found {
+				return rule, fmt.Errorf("invalid weekday %q", val)
+			}
+		case "UNTIL":
+			until, err := time.Parse("20060102T150405Z", val)
+			if err != nil {
+				return rule, fmt.Errorf("invalid until %q", val)
+			}
+			rule.Until = &until
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return rule, fmt.Errorf("invalid count %q", val)
+			}
+			rule.Count = n
+		default:
+			return rule, fmt.Errorf("unsupported rrule part %q", key)
+		}
+	}
+	return rule, rule.Validate()
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOccurrencesKeepLocalTimeAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip("time zone data unavailable:", err)
+	}
+	// 10:00 BST on the Saturday before the clocks go back, sent with the
+	// client's fixed +01:00 offset.
+	start := time.Date(2024, 10, 19, 10, 0, 0, 0, time.FixedZone("", 3600))
+	rule := RecurrenceRule{IntervalWeeks: 1, Count: 3}
+
+	got, err := rule.Occurrences(start, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d occurrences, want 3", len(got))
+	}
+	for i, at := range got {
+		local := at.In(loc)
+		if local.Hour() != 10 || local.Minute() != 0 {
+			t.Errorf("occurrence %d at %s, want 10:00 local", i, local)
+		}
+	}
+	if want := time.Date(2024, 10, 26, 9, 0, 0, 0, time.UTC); !got[1].Equal(want) {
+		t.Errorf("second occurrence %s, want %s", got[1].UTC(), want)
+	}
+}
+
+func TestOccurrencesWeekdayInProviderZone(t *testing.T) {
+	loc, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Skip("time zone data unavailable:", err)
+	}
+	// Monday 23:00 UTC is already Tuesday in Sydney.
+	start := time.Date(2024, 6, 3, 23, 0, 0, 0, time.UTC)
+	tuesday := time.Tuesday
+	rule := RecurrenceRule{IntervalWeeks: 1, Weekday: &tuesday, Count: 1}
+
+	got, err := rule.Occurrences(start, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !got[0].Equal(start) {
+		t.Errorf("got %v, want [%s]", got, start)
+	}
+}
+
+func TestOccurrencesWeekdayMovesForward(t *testing.T) {
+	start := time.Date(2024, 6, 5, 9, 30, 0, 0, time.UTC) // Wednesday
+	monday := time.Monday
+	rule := RecurrenceRule{IntervalWeeks: 2, Weekday: &monday, Count: 2}
+
+	got, err := rule.Occurrences(start, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{
+		time.Date(2024, 6, 10, 9, 30, 0, 0, time.UTC),
+		time.Date(2024, 6, 24, 9, 30, 0, 0, time.UTC),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("occurrence %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestOccurrencesUntil(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	until := start.AddDate(0, 0, 14)
+	got, err := RecurrenceRule{IntervalWeeks: 1, Until: &until}.Occurrences(start, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Errorf("got %d occurrences up to and including until, want 3", len(got))
+	}
+
+	// Count stops a rule before until does.
+	got, err = RecurrenceRule{IntervalWeeks: 1, Until: &until, Count: 2}.Occurrences(start, time.UTC)
+	if err != nil || len(got) != 2 {
+		t.Errorf("got %d occurrences, %v; want 2", len(got), err)
+	}
+
+	far := start.AddDate(2, 0, 0)
+	if _, err := (RecurrenceRule{IntervalWeeks: 1, Until: &far}).Occurrences(start, time.UTC); err == nil {
+		t.Error("until beyond the occurrence limit was accepted")
+	}
+
+	exact := start.AddDate(0, 0, 7*(MaxOccurrences-1))
+	got, err = RecurrenceRule{IntervalWeeks: 1, Until: &exact}.Occurrences(start, time.UTC)
+	if err != nil || len(got) != MaxOccurrences {
+		t.Errorf("got %d occurrences, %v; want %d", len(got), err, MaxOccurrences)
+	}
+}
+
+func TestRecurrenceRuleRoundTrip(t *testing.T) {
+	friday := time.Friday
+	until := time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC)
+	rule := RecurrenceRule{IntervalWeeks: 2, Weekday: &friday, Until: &until, Count: 5}
+
+	parsed, err := ParseRecurrenceRule(rule.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.String() != rule.String() {
+		t.Errorf("round trip gave %q, want %q", parsed.String(), rule.String())
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReminderKind string
+
+const (
+	Reminder48Hours ReminderKind = "48h"
+	Reminder2Hours  ReminderKind = "2h"
+)
+
+// ReminderSchedule lists the reminders sent before a confirmed booking and
+// how far ahead of its scheduled time each goes out, furthest first.
+var ReminderSchedule = []struct {
+	Kind ReminderKind
+	Lead time.Duration
+}{
+	{Reminder48Hours, 48 * time.Hour},
+	{Reminder2Hours, 2 * time.Hour},
+}
+
+// BookingLinkAction is what a signed link in a reminder does.
+type BookingLinkAction string
+
+const (
+	LinkConfirmAttendance BookingLinkAction = "confirm"
+	LinkCancelBooking     BookingLinkAction = "cancel"
+)
+
+// BookingLink describes a signed reminder link. It is returned both when
+// the link is inspected and after it has been followed.
+type BookingLink struct {
+	Action    BookingLinkAction `json:"action"`
+	BookingID uuid.UUID         `json:"booking_id"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	Booking   *Booking          `json:"booking"`
+	// PetName, ServiceName and ProviderName describe the booking for the
+	// page the link opens.
+	PetName      string `json:"pet_name"`
+	ServiceName  string `json:"service_name"`
+	ProviderName string `json:"provider_name"`
+	// LocalTime is the booking's time in the provider's time zone.
+	LocalTime time.Time `json:"local_time"`
+}
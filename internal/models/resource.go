@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResourceKind describes what a resource is. It is informational; every
+// kind is scheduled the same way.
+type ResourceKind string
+
+const (
+	ResourceTable  ResourceKind = "table"
+	ResourceTub    ResourceKind = "tub"
+	ResourceKennel ResourceKind = "kennel"
+	ResourceOther  ResourceKind = "other"
+)
+
+// Resource is equipment or space a provider has a limited amount of, such
+// as two bathing tubs. Capacity is how many bookings can use it at once.
+type Resource struct {
+	ID         uuid.UUID    `json:"id" db:"id"`
+	ProviderID uuid.UUID    `json:"provider_id" db:"provider_id"`
+	Name       string       `json:"name" db:"name"`
+	Kind       ResourceKind `json:"kind" db:"kind"`
+	Capacity   int          `json:"capacity" db:"capacity"`
+	Active     bool         `json:"active" db:"active"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+type CreateResourceRequest struct {
+	Name     string       `json:"name" binding:"required"`
+	Kind     ResourceKind `json:"kind" binding:"required,oneof=table tub kennel other"`
+	Capacity int          `json:"capacity" binding:"required,min=1"`
+}
+
+// UpdateResourceRequest changes the fields that are set. Lowering capacity
+// does not affect bookings already made.
+type UpdateResourceRequest struct {
+	Name     *string       `json:"name"`
+	Kind     *ResourceKind `json:"kind" binding:"omitempty,oneof=table tub kennel other"`
+	Capacity *int          `json:"capacity" binding:"omitempty,min=1"`
+}
+
+// ServiceResource is a resource a service uses. A booking holds Quantity
+// units of it, one if unset, from StartOffset minutes after the booking
+// starts, for Duration minutes or, when Duration is zero, until the service
+// ends.
+type ServiceResource struct {
+	ResourceID  uuid.UUID `json:"resource_id" binding:"required"`
+	Quantity    int       `json:"quantity" binding:"min=0"`
+	StartOffset int       `json:"start_offset_minutes" binding:"min=0"`
+	Duration    int       `json:"duration_minutes" binding:"min=0"`
+}
+
+type SetServiceResourcesRequest struct {
+	Resources []ServiceResource `json:"resources" binding:"dive"`
+}
+
+// NightOccupancy is how much of a resource is booked on one night, the
+// night starting on Date.
+type NightOccupancy struct {
+	Date     string `json:"date"`
+	Booked   int    `json:"booked"`
+	Capacity int    `json:"capacity"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Review struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	BookingID  uuid.UUID `json:"booking_id" db:"booking_id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	ProviderID uuid.UUID `json:"provider_id" db:"provider_id"`
+	Rating     int       `json:"rating" db:"rating"`
+	Comment    string    `json:"comment" db:"comment"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateReviewRequest struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Comment string `json:"comment" binding:"max=2000"`
+}
@@ -12,7 +12,7 @@ type Service struct {
 	Name        string      `json:"name" db:"name"`
 	Description string      `json:"description" db:"description"`
 	Category    ServiceType `json:"category" db:"category"`
-	Price       float64     `json:"price" db:"price"`
+	Price       Money       `json:"price" db:"price"`
 	Duration    int         `json:"duration" db:"duration_minutes"`
 	Available   bool        `json:"available" db:"available"`
 	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
@@ -29,11 +29,34 @@ const (
 	ServiceBoarding ServiceType = "boarding"
 )
 
+// IsValid reports whether t is one of the known service types.
+func (t ServiceType) IsValid() bool {
+	switch t {
+	case ServiceGrooming, ServiceSitting, ServiceWalking, ServiceTraining, ServiceBoarding:
+		return true
+	}
+	return false
+}
+
+// IsStay reports whether services of this type are booked as stays from a
+// check-in to a check-out, priced per night, rather than as one
+// appointment.
+func (t ServiceType) IsStay() bool {
+	return t == ServiceBoarding || t == ServiceSitting
+}
+
+// OccupiesProvider reports whether a stay of this type keeps the provider
+// busy until check-out. A sitter stays with the pets; boarding is limited
+// by kennels instead.
+func (t ServiceType) OccupiesProvider() bool {
+	return t == ServiceSitting
+}
+
 type CreateServiceRequest struct {
 	Name        string      `json:"name" binding:"required"`
 	Description string      `json:"description"`
 	Category    ServiceType `json:"category" binding:"required"`
-	Price       float64     `json:"price" binding:"required,min=0"`
+	Price       Money       `json:"price" binding:"required"`
 	Duration    int         `json:"duration" binding:"required,min=1"`
 	Available   bool        `json:"available"`
 }
@@ -42,7 +65,7 @@ type UpdateServiceRequest struct {
 	Name        *string      `json:"name"`
 	Description *string      `json:"description"`
 	Category    *ServiceType `json:"category"`
-	Price       *float64     `json:"price"`
+	Price       *Money       `json:"price"`
 	Duration    *int         `json:"duration"`
 	Available   *bool        `json:"available"`
 }
\ No newline at end of file
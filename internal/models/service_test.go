@@ -0,0 +1,29 @@
+package models
+
+import "testing"
+
+func TestServiceType(t *testing.T) {
+	tests := []struct {
+		category              ServiceType
+		valid, stay, occupies bool
+	}{
+		{ServiceGrooming, true, false, false},
+		{ServiceWalking, true, false, false},
+		{ServiceTraining, true, false, false},
+		{ServiceBoarding, true, true, false},
+		{ServiceSitting, true, true, true},
+		{"Grooming", false, false, false},
+		{"", false, false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.category.IsValid(); got != tt.valid {
+			t.Errorf("%q.IsValid() = %v, want %v", tt.category, got, tt.valid)
+		}
+		if got := tt.category.IsStay(); got != tt.stay {
+			t.Errorf("%q.IsStay() = %v, want %v", tt.category, got, tt.stay)
+		}
+		if got := tt.category.OccupiesProvider(); got != tt.occupies {
+			t.Errorf("%q.OccupiesProvider() = %v, want %v", tt.category, got, tt.occupies)
+		}
+	}
+}
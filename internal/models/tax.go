@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaxSettings describe how a provider charges sales tax. Rate is a
+// percentage; when Inclusive is set, service prices already include tax and
+// the tax is carved out of them rather than added on top.
+type TaxSettings struct {
+	ProviderID         uuid.UUID `json:"provider_id" db:"provider_id"`
+	Rate               float64   `json:"rate" db:"rate"`
+	Inclusive          bool      `json:"inclusive" db:"inclusive"`
+	RegistrationNumber string    `json:"registration_number" db:"registration_number"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type UpdateTaxSettingsRequest struct {
+	Rate               *float64 `json:"rate" binding:"omitempty,min=0,max=100"`
+	Inclusive          *bool    `json:"inclusive"`
+	RegistrationNumber *string  `json:"registration_number" binding:"omitempty,max=64"`
+}
+
+// TaxBreakdown splits a charge into its pre-tax subtotal and the tax on it.
+type TaxBreakdown struct {
+	Subtotal Money
+	Tax      Money
+	Total    Money
+}
+
+// Apply works out the tax on a price under these settings. Exclusive tax is
+// added to the price; inclusive tax is extracted from it so the total stays
+// at the listed price.
+func (t TaxSettings) Apply(price Money) TaxBreakdown {
+	if t.Rate <= 0 {
+		return TaxBreakdown{Subtotal: price, Tax: Zero(price.Currency), Total: price}
+	}
+	if t.Inclusive {
+		subtotal := price.Percent(100 * 100 / (100 + t.Rate))
+		tax := NewMoney(price.Amount-subtotal.Amount, price.Currency)
+		return TaxBreakdown{Subtotal: subtotal, Tax: tax, Total: price}
+	}
+	tax := price.Percent(t.Rate)
+	return TaxBreakdown{Subtotal: price, Tax: tax, Total: NewMoney(price.Amount+tax.Amount, price.Currency)}
+}
@@ -0,0 +1,39 @@
+package models
+
+import "testing"
+
+func TestTaxApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings TaxSettings
+		price    Money
+		subtotal int64
+		tax      int64
+		total    int64
+	}{
+		{"no tax", TaxSettings{}, NewMoney(5000, "USD"), 5000, 0, 5000},
+		{"no tax, inclusive", TaxSettings{Inclusive: true}, NewMoney(5000, "USD"), 5000, 0, 5000},
+		{"exclusive", TaxSettings{Rate: 20}, NewMoney(5000, "USD"), 5000, 1000, 6000},
+		{"exclusive rounds half away from zero", TaxSettings{Rate: 8.875}, NewMoney(10000, "USD"), 10000, 888, 10888},
+		{"inclusive", TaxSettings{Rate: 20, Inclusive: true}, NewMoney(6000, "USD"), 5000, 1000, 6000},
+		// 1000 / 1.2 = 833.33: the subtotal is rounded and the tax takes
+		// the remainder, so the two always add up to the listed price.
+		{"inclusive rounding", TaxSettings{Rate: 20, Inclusive: true}, NewMoney(1000, "USD"), 833, 167, 1000},
+		{"inclusive fractional rate", TaxSettings{Rate: 8.875, Inclusive: true}, NewMoney(10000, "USD"), 9185, 815, 10000},
+		{"inclusive on a single cent", TaxSettings{Rate: 20, Inclusive: true}, NewMoney(1, "USD"), 1, 0, 1},
+		{"zero-decimal currency", TaxSettings{Rate: 10, Inclusive: true}, NewMoney(1100, "JPY"), 1000, 100, 1100},
+		{"free", TaxSettings{Rate: 20}, NewMoney(0, "EUR"), 0, 0, 0},
+	}
+	for _, tt := range tests {
+		got := tt.settings.Apply(tt.price)
+		if got.Subtotal.Amount != tt.subtotal || got.Tax.Amount != tt.tax || got.Total.Amount != tt.total {
+			t.Errorf("%s: got %s + %s = %s, want %d + %d = %d", tt.name,
+				got.Subtotal, got.Tax, got.Total, tt.subtotal, tt.tax, tt.total)
+		}
+		for _, m := range []Money{got.Subtotal, got.Tax, got.Total} {
+			if m.Currency != tt.price.Currency {
+				t.Errorf("%s: %s not in the price's currency", tt.name, m)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tip is a gratuity an owner adds to a completed booking. It is kept apart
+// from the booking's price and attributed to the provider, or to the staff
+// groomer who carried out the booking when there is one.
+type Tip struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	BookingID  uuid.UUID  `json:"booking_id" db:"booking_id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	ProviderID uuid.UUID  `json:"provider_id" db:"provider_id"`
+	StaffID    *uuid.UUID `json:"staff_id,omitempty" db:"staff_id"`
+	Amount     Money      `json:"amount" db:"amount"`
+	// Percent is set when the tip was given as a share of the booking total.
+	Percent *float64 `json:"percent,omitempty" db:"percent"`
+	// PaymentID links the charge when the tip was paid through the app
+	// rather than in person.
+	PaymentID *uuid.UUID `json:"payment_id,omitempty" db:"payment_id"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// AddTipRequest gives a tip as either a fixed amount or a percentage of the
+// booking's total price. StaffID picks the groomer to thank when the booking
+// was not assigned to one.
+type AddTipRequest struct {
+	Amount  *Money     `json:"amount"`
+	Percent *float64   `json:"percent" binding:"omitempty,gt=0,max=100"`
+	StaffID *uuid.UUID `json:"staff_id"`
+}
+
+// TipStaffTotal is what one groomer received in a TipReport. StaffID is nil
+// for tips that went to the provider rather than a staff member.
+type TipStaffTotal struct {
+	StaffID   *uuid.UUID `json:"staff_id"`
+	StaffName string     `json:"staff_name,omitempty"`
+	Count     int        `json:"count"`
+	Totals    []Money    `json:"totals"`
+}
+
+// TipReport summarises the tips a provider received over a period, overall
+// and per groomer.
+type TipReport struct {
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	Count   int             `json:"count"`
+	Totals  []Money         `json:"totals"`
+	ByStaff []TipStaffTotal `json:"by_staff"`
+	Tips    []Tip           `json:"tips"`
+}
@@ -35,6 +35,8 @@ type CreateUserRequest struct {
 	Phone     string   `json:"phone"`
 	Address   string   `json:"address"`
 	Role      UserRole `json:"role" binding:"required"`
+	// ReferralCode credits the existing user who referred this one.
+	ReferralCode string `json:"referral_code"`
 }
 
 type LoginRequest struct {
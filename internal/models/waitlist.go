@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WaitlistEntry struct {
+	ID            uuid.UUID      `json:"id" db:"id"`
+	UserID        uuid.UUID      `json:"user_id" db:"user_id"`
+	PetID         uuid.UUID      `json:"pet_id" db:"pet_id"`
+	ServiceID     uuid.UUID      `json:"service_id" db:"service_id"`
+	ProviderID    uuid.UUID      `json:"provider_id" db:"provider_id"`
+	WindowStart   time.Time      `json:"window_start" db:"window_start"`
+	WindowEnd     time.Time      `json:"window_end" db:"window_end"`
+	Status        WaitlistStatus `json:"status" db:"status"`
+	OfferedTime   *time.Time     `json:"offered_time,omitempty" db:"offered_time"`
+	HoldExpiresAt *time.Time     `json:"hold_expires_at,omitempty" db:"hold_expires_at"`
+	BookingID     *uuid.UUID     `json:"booking_id,omitempty" db:"booking_id"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting   WaitlistStatus = "waiting"
+	WaitlistOffered   WaitlistStatus = "offered"
+	WaitlistBooked    WaitlistStatus = "booked"
+	WaitlistExpired   WaitlistStatus = "expired"
+	WaitlistWithdrawn WaitlistStatus = "withdrawn"
+)
+
+// HoldActive reports whether the entry currently holds an offered slot.
+func (e WaitlistEntry) HoldActive(now time.Time) bool {
+	return e.Status == WaitlistOffered && e.HoldExpiresAt != nil && e.HoldExpiresAt.After(now)
+}
+
+type CreateWaitlistEntryRequest struct {
+	PetID       uuid.UUID `json:"pet_id" binding:"required"`
+	ServiceID   uuid.UUID `json:"service_id" binding:"required"`
+	WindowStart time.Time `json:"window_start" binding:"required"`
+	WindowEnd   time.Time `json:"window_end" binding:"required"`
+}
+
+type AcceptWaitlistOfferRequest struct {
+	PaymentMethod string `json:"payment_method"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WalkEventKind is something that happened on a walk worth telling the
+// owner about.
+type WalkEventKind string
+
+const (
+	WalkPee   WalkEventKind = "pee"
+	WalkPoo   WalkEventKind = "poo"
+	WalkWater WalkEventKind = "water"
+)
+
+// TrackPoint is one GPS fix recorded during a walk.
+type TrackPoint struct {
+	Lat  float64   `json:"lat" binding:"min=-90,max=90"`
+	Lng  float64   `json:"lng" binding:"min=-180,max=180"`
+	Time time.Time `json:"time" binding:"required"`
+}
+
+// WalkEvent is an event recorded during a walk, where and when it happened.
+type WalkEvent struct {
+	ID        uuid.UUID     `json:"id" db:"id"`
+	BookingID uuid.UUID     `json:"booking_id" db:"booking_id"`
+	Kind      WalkEventKind `json:"kind" db:"kind"`
+	Lat       float64       `json:"lat" db:"lat"`
+	Lng       float64       `json:"lng" db:"lng"`
+	Time      time.Time     `json:"time" db:"occurred_at"`
+}
+
+type WalkEventInput struct {
+	Kind WalkEventKind `json:"kind" binding:"required,oneof=pee poo water"`
+	Lat  float64       `json:"lat" binding:"min=-90,max=90"`
+	Lng  float64       `json:"lng" binding:"min=-180,max=180"`
+	Time time.Time     `json:"time" binding:"required"`
+}
+
+// RecordWalkRequest uploads part of a walk's track and the events since the
+// last upload. Points at or before the last one already recorded are
+// ignored, as are repeated events, so a failed upload can be retried as is.
+type RecordWalkRequest struct {
+	Points []TrackPoint     `json:"points" binding:"max=5000,dive"`
+	Events []WalkEventInput `json:"events" binding:"max=500,dive"`
+}
+
+// Walk is the report of a walk on a booking. Duration runs to now while the
+// walk is still going.
+type Walk struct {
+	BookingID       uuid.UUID             `json:"booking_id" db:"booking_id"`
+	StartedAt       time.Time             `json:"started_at" db:"started_at"`
+	EndedAt         *time.Time            `json:"ended_at,omitempty" db:"ended_at"`
+	DurationSeconds int64                 `json:"duration_seconds"`
+	DistanceMeters  float64               `json:"distance_meters" db:"distance_meters"`
+	PointCount      int                   `json:"point_count" db:"point_count"`
+	EventCounts     map[WalkEventKind]int `json:"event_counts"`
+	Events          []WalkEvent           `json:"events"`
+}
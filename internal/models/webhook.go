@@ -0,0 +1,105 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookEvent string
+
+const (
+	WebhookBookingCreated     WebhookEvent = "booking.created"
+	WebhookBookingConfirmed   WebhookEvent = "booking.confirmed"
+	WebhookBookingRescheduled WebhookEvent = "booking.rescheduled"
+	WebhookBookingStarted     WebhookEvent = "booking.started"
+	WebhookBookingCompleted   WebhookEvent = "booking.completed"
+	WebhookBookingCancelled   WebhookEvent = "booking.cancelled"
+	WebhookBookingNoShow      WebhookEvent = "booking.no_show"
+	WebhookPaymentSucceeded   WebhookEvent = "payment.succeeded"
+	WebhookPaymentFailed      WebhookEvent = "payment.failed"
+	WebhookPaymentRefunded    WebhookEvent = "payment.refunded"
+	WebhookReviewCreated      WebhookEvent = "review.created"
+	// WebhookPing is only sent by test deliveries, whatever the
+	// subscription's events.
+	WebhookPing WebhookEvent = "ping"
+)
+
+var WebhookEvents = []WebhookEvent{
+	WebhookBookingCreated, WebhookBookingConfirmed, WebhookBookingRescheduled,
+	WebhookBookingStarted, WebhookBookingCompleted, WebhookBookingCancelled,
+	WebhookBookingNoShow, WebhookPaymentSucceeded, WebhookPaymentFailed,
+	WebhookPaymentRefunded, WebhookReviewCreated,
+}
+
+func (e WebhookEvent) IsValid() bool {
+	for _, known := range WebhookEvents {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook is a provider's subscription to events, delivered by POST to URL.
+// Each payload is signed with Secret, which is only shown when the
+// subscription is created.
+type Webhook struct {
+	ID         uuid.UUID      `json:"id" db:"id"`
+	ProviderID uuid.UUID      `json:"provider_id" db:"provider_id"`
+	URL        string         `json:"url" db:"url"`
+	Events     []WebhookEvent `json:"events" db:"events"`
+	Secret     string         `json:"secret,omitempty" db:"secret"`
+	Active     bool           `json:"active" db:"active"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+}
+
+// Subscribes reports whether the webhook wants event.
+func (w Webhook) Subscribes(event WebhookEvent) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateWebhookRequest struct {
+	URL    string         `json:"url" binding:"required"`
+	Events []WebhookEvent `json:"events" binding:"required,min=1"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event queued for a webhook and the outcome of its
+// latest attempt. Like notifications, deliveries are written in the same
+// transaction as the change they report.
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id" db:"id"`
+	WebhookID      uuid.UUID             `json:"webhook_id" db:"webhook_id"`
+	Event          WebhookEvent          `json:"event" db:"event"`
+	Payload        json.RawMessage       `json:"payload" db:"payload"`
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	ResponseStatus *int                  `json:"response_status,omitempty" db:"response_status"`
+	LastError      string                `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// WebhookPayload is the body POSTed to a webhook. ID is the delivery's ID and
+// stays the same across retries, so receivers can discard duplicates.
+type WebhookPayload struct {
+	ID        uuid.UUID       `json:"id"`
+	Event     WebhookEvent    `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
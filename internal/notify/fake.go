@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// UndeliverableRecipient is an address the fake always rejects.
+const UndeliverableRecipient = "undeliverable@example.com"
+
+// SentMessage is a message the fake accepted, with the channel it came in on.
+type SentMessage struct {
+	Channel string
+	Message
+}
+
+// FakeSender is an in-memory sender for every channel, for local development
+// and tests. It logs each message and keeps it for inspection.
+type FakeSender struct {
+	mu   sync.Mutex
+	sent []SentMessage
+}
+
+func NewFakeSender() *FakeSender {
+	return &FakeSender{}
+}
+
+func (s *FakeSender) SendEmail(ctx context.Context, msg Message) error {
+	return s.send("email", msg)
+}
+
+func (s *FakeSender) SendSMS(ctx context.Context, msg Message) error {
+	return s.send("sms", msg)
+}
+
+func (s *FakeSender) SendPush(ctx context.Context, msg Message) error {
+	return s.send("push", msg)
+}
+
+func (s *FakeSender) send(channel string, msg Message) error {
+	if msg.To == "" || msg.To == UndeliverableRecipient {
+		return fmt.Errorf("%w: %q", ErrUndeliverable, msg.To)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, SentMessage{Channel: channel, Message: msg})
+	log.Printf("[notify:%s] to=%s subject=%q", channel, msg.To, msg.Subject)
+	return nil
+}
+
+// Sent returns the messages accepted so far, oldest first.
+func (s *FakeSender) Sent() []SentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SentMessage(nil), s.sent...)
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFakeSender(t *testing.T) {
+	ctx := context.Background()
+	s := NewFakeSender()
+
+	if err := s.SendEmail(ctx, Message{To: "owner@example.com", Subject: "Hi", Body: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SendSMS(ctx, Message{To: "+15550100", Body: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SendPush(ctx, Message{To: "user-id", Subject: "Hi"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := s.Sent()
+	want := []string{"email", "sms", "push"}
+	if len(sent) != len(want) {
+		t.Fatalf("sent %d messages, want %d", len(sent), len(want))
+	}
+	for i, channel := range want {
+		if sent[i].Channel != channel {
+			t.Errorf("message %d went by %s, want %s", i, sent[i].Channel, channel)
+		}
+	}
+	if sent[0].To != "owner@example.com" || sent[0].Subject != "Hi" {
+		t.Errorf("email = %+v", sent[0])
+	}
+
+	// Sent returns a copy.
+	sent[0].To = "changed"
+	if s.Sent()[0].To != "owner@example.com" {
+		t.Error("changing Sent's result changed the recorded message")
+	}
+}
+
+func TestFakeSenderUndeliverable(t *testing.T) {
+	ctx := context.Background()
+	s := NewFakeSender()
+
+	for _, to := range []string{"", UndeliverableRecipient} {
+		if err := s.SendEmail(ctx, Message{To: to}); !errors.Is(err, ErrUndeliverable) {
+			t.Errorf("SendEmail(%q): %v, want ErrUndeliverable", to, err)
+		}
+	}
+	if len(s.Sent()) != 0 {
+		t.Errorf("rejected messages were recorded: %+v", s.Sent())
+	}
+}
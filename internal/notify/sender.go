@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUndeliverable is returned by senders for recipients that can never be
+// reached, such as a malformed address. Such messages are not retried.
+var ErrUndeliverable = errors.New("recipient cannot be reached")
+
+// Message is a notification rendered for a single recipient on one channel.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// EmailSender delivers messages to an email address.
+type EmailSender interface {
+	SendEmail(ctx context.Context, msg Message) error
+}
+
+// SMSSender delivers messages to a phone number. Subject is not used.
+type SMSSender interface {
+	SendSMS(ctx context.Context, msg Message) error
+}
+
+// PushSender delivers messages to a user's devices. To is the user's ID;
+// implementations resolve it to device tokens with their push gateway.
+type PushSender interface {
+	SendPush(ctx context.Context, msg Message) error
+}
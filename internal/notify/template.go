@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// BookingData is what booking templates can refer to. Times are shown in
+// whatever location they carry.
+type BookingData struct {
+	RecipientName string
+	OwnerName     string
+	ProviderName  string
+	PetName       string
+	ServiceName   string
+	ScheduledTime time.Time
+	// PreviousTime is set for reschedules.
+	PreviousTime time.Time
+	// Occurrences counts the bookings a change applied to when it covered
+	// several occurrences of a series.
+	Occurrences int
+	// Reason is the cancellation reason, if one was given.
+	Reason string
+	// ConfirmURL and CancelURL are the signed links in reminders.
+	ConfirmURL string
+	CancelURL  string
+	// HoldExpiresAt is when a waitlist offer lapses.
+	HoldExpiresAt time.Time
+	// Language is set by Render.
+	Language string
+}
+
+func (d BookingData) When() string       { return formatTime(d.ScheduledTime, d.Language) }
+func (d BookingData) Previously() string { return formatTime(d.PreviousTime, d.Language) }
+func (d BookingData) HoldUntil() string  { return formatTime(d.HoldExpiresAt, d.Language) }
+
+// Rendered is a template's output. Subject and Body are used for email and
+// push; Short is the single line sent by SMS.
+type Rendered struct {
+	Subject string
+	Body    string
+	Short   string
+}
+
+// DefaultLanguage is used for users who have not chosen one, and for any
+// template a language does not translate.
+const DefaultLanguage = "en"
+
+var templates = map[string]map[string]string{
+	"en": englishTemplates,
+	"es": spanishTemplates,
+}
+
+var parsed = func() map[string]map[string]*template.Template {
+	m := make(map[string]map[string]*template.Template, len(templates))
+	for lang, set := range templates {
+		m[lang] = make(map[string]*template.Template, len(set))
+		for name, text := range set {
+			m[lang][name] = template.Must(template.New(lang + "/" + name).Parse(text))
+		}
+	}
+	return m
+}()
+
+// SupportsLanguage reports whether lang has templates.
+func SupportsLanguage(lang string) bool {
+	_, ok := templates[lang]
+	return ok
+}
+
+// Render fills in the templates for the named event in lang.
+func Render(event, lang string, data BookingData) (Rendered, error) {
+	tmpl, ok := parsed[lang][event]
+	if !ok {
+		lang = DefaultLanguage
+		if tmpl, ok = parsed[lang][event]; !ok {
+			return Rendered{}, fmt.Errorf("no template for %q", event)
+		}
+	}
+	data.Language = lang
+
+	var out Rendered
+	for name, dst := range map[string]*string{"subject": &out.Subject, "body": &out.Body, "short": &out.Short} {
+		var b strings.Builder
+		if err := tmpl.ExecuteTemplate(&b, name, data); err != nil {
+			return Rendered{}, err
+		}
+		*dst = strings.TrimSpace(b.String())
+	}
+	return out, nil
+}
+
+var (
+	spanishDays   = [...]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"}
+	spanishMonths = [...]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sep", "oct", "nov", "dic"}
+)
+
+// formatTime writes out an appointment time in lang's conventions.
+func formatTime(t time.Time, lang string) string {
+	if lang == "es" {
+		return fmt.Sprintf("%s %d %s %d a las %s", spanishDays[t.Weekday()], t.Day(),
+			spanishMonths[t.Month()-1], t.Year(), t.Format("15:04 MST"))
+	}
+	return t.Format("Mon 2 Jan 2006 at 15:04 MST")
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pet-grooming-app/internal/models"
+)
+
+func TestRenderEveryEvent(t *testing.T) {
+	data := BookingData{
+		RecipientName: "Sam",
+		OwnerName:     "Sam",
+		ProviderName:  "Happy Paws",
+		PetName:       "Rex",
+		ServiceName:   "Full groom",
+		ScheduledTime: time.Date(2026, 3, 14, 10, 30, 0, 0, time.UTC),
+		PreviousTime:  time.Date(2026, 3, 13, 9, 0, 0, 0, time.UTC),
+		HoldExpiresAt: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+	}
+	for lang := range templates {
+		for _, event := range models.NotificationEvents {
+			out, err := Render(string(event), lang, data)
+			if err != nil {
+				t.Errorf("%s/%s: %v", lang, event, err)
+				continue
+			}
+			if out.Subject == "" || out.Body == "" || out.Short == "" {
+				t.Errorf("%s/%s rendered an empty part: %+v", lang, event, out)
+			}
+			if !strings.Contains(out.Body, "Rex") {
+				t.Errorf("%s/%s body does not name the pet: %q", lang, event, out.Body)
+			}
+		}
+	}
+}
+
+func TestRenderFallsBackToDefaultLanguage(t *testing.T) {
+	out, err := Render(string(models.EventBookingCreated), "fr", BookingData{PetName: "Rex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := Render(string(models.EventBookingCreated), DefaultLanguage, BookingData{PetName: "Rex"})
+	if out != want {
+		t.Errorf("unsupported language rendered %+v, want the %s templates", out, DefaultLanguage)
+	}
+
+	if _, err := Render("no_such_event", DefaultLanguage, BookingData{}); err == nil {
+		t.Error("rendering an unknown event succeeded")
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	at := time.Date(2026, 3, 14, 10, 30, 0, 0, time.UTC)
+	if got, want := formatTime(at, "en"), "Sat 14 Mar 2026 at 10:30 UTC"; got != want {
+		t.Errorf("en: %q, want %q", got, want)
+	}
+	if got, want := formatTime(at, "es"), "sáb 14 mar 2026 a las 10:30 UTC"; got != want {
+		t.Errorf("es: %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package notify
+
+// englishTemplates are the default wording. Each defines "subject", "body"
+// and "short".
+var englishTemplates = map[string]string{
+	"booking_created": `
+{{define "subject"}}Booking requested: {{.PetName}}'s {{.ServiceName}}{{end}}
+{{define "body"}}Hi {{.RecipientName}},
+
+{{.OwnerName}} has requested {{.ServiceName}} for {{.PetName}} with {{.ProviderName}} on {{.When}}.
+{{- if gt .Occurrences 1}} This is the first of {{.Occurrences}} recurring appointments.{{end}}
+
+You will hear from us again once it is confirmed.{{end}}
+{{define "short"}}Booking requested: {{.PetName}}'s {{.ServiceName}} with {{.ProviderName}}, {{.When}}.{{end}}`,
+
+	"booking_confirmed": `
+{{define "subject"}}Booking confirmed: {{.PetName}}'s {{.ServiceName}}{{end}}
+{{define "body"}}Hi {{.RecipientName}},
+
+{{.ProviderName}} has confirmed {{.ServiceName}} for {{.PetName}} on {{.When}}.
+{{- if gt .Occurrences 1}} {{.Occurrences}} appointments in the series are confirmed.{{end}}{{end}}
+{{define "short"}}Confirmed: {{.PetName}}'s {{.ServiceName}} with {{.ProviderName}}, {{.When}}.{{end}}`,
+
+	"booking_rescheduled": `
+{{define "subject"}}Booking moved: {{.PetName}}'s {{.ServiceName}}{{end}}
+{{define "body"}}Hi {{.RecipientName}},
+
+{{.PetName}}'s {{.ServiceName}} with {{.ProviderName}} has moved from {{.Previously}} to {{.When}}.
+{{- if gt .Occurrences 1}} {{.Occurrences}} appointments in the series moved by the same amount.{{end}}{{end}}
+{{define "short"}}Moved: {{.PetName}}'s {{.ServiceName}} with {{.ProviderName}} is now {{.When}}.{{end}}`,
+
+	"booking_cancelled": `
+{{define "subject"}}Booking cancelled: {{.PetName}}'s {{.ServiceName}}{{end}}
+{{define "body"}}Hi {{.RecipientName}},
+
+{{.PetName}}'s {{.ServiceName}} with {{.ProviderName}} on {{.When}} has been cancelled.
+{{- if gt .Occurrences 1}} {{.Occurrences}} appointments in the series were cancelled.{{end}}
+{{- if .Reason}}
+
+Reason: {{.Reason}}{{end}}{{end}}
+{{define "short"}}Cancelled: {{.PetName}}'s {{.ServiceName}} with {{.ProviderName}}, {{.When}}.{{end}}`,
+
+	"booking_reminder": `
+{{define "subject"}}Reminder: {{.PetName}}'s {{.ServiceName}} on {{.When}}{{end}}
+{{define "body"}}Hi {{.RecipientName}},
+
+This is a reminder that {{.PetName}} is booked in for {{.ServiceName}} with {{.ProviderName}} on {{.When}}.
+
+Let {{.ProviderName}} know you're coming: {{.ConfirmURL}}
+
+Can't make it? Cancel here: {{.CancelURL}}
+Cancellation fees may apply under the provider's policy.{{end}}
+{{define "short"}}Reminder: {{.PetName}}'s {{.ServiceName}} with {{.ProviderName}}, {{.When}}. Confirm: {{.ConfirmURL}} Cancel: {{.CancelURL}}{{end}}`,
+
+	"waitlist_offer": `
+{{define "subject"}}A slot opened up: {{.PetName}}'s {{.ServiceName}} on {{.When}}{{end}}
+{{define "body"}}Hi {{.RecipientName}},
+
+A slot you are waiting for has opened up with {{.ProviderName}}: {{.ServiceName}} for {{.PetName}} on {{.When}}.
+
+We are holding it for you until {{.HoldUntil}}. Accept it from your waitlist in the app before then, or it goes to the next person in line.{{end}}
+{{define "short"}}Slot open: {{.PetName}}'s {{.ServiceName}} with {{.ProviderName}}, {{.When}}. Held for you until {{.HoldUntil}}.{{end}}`,
+}
@@ -0,0 +1,62 @@
+package notify
+
+// spanishTemplates translate englishTemplates.
+var spanishTemplates = map[string]string{
+	"booking_created": `
+{{define "subject"}}Reserva solicitada: {{.ServiceName}} para {{.PetName}}{{end}}
+{{define "body"}}Hola {{.RecipientName}}:
+
+{{.OwnerName}} ha solicitado {{.ServiceName}} para {{.PetName}} con {{.ProviderName}} el {{.When}}.
+{{- if gt .Occurrences 1}} Es la primera de {{.Occurrences}} citas periódicas.{{end}}
+
+Te avisaremos de nuevo cuando se confirme.{{end}}
+{{define "short"}}Reserva solicitada: {{.ServiceName}} para {{.PetName}} con {{.ProviderName}}, {{.When}}.{{end}}`,
+
+	"booking_confirmed": `
+{{define "subject"}}Reserva confirmada: {{.ServiceName}} para {{.PetName}}{{end}}
+{{define "body"}}Hola {{.RecipientName}}:
+
+{{.ProviderName}} ha confirmado {{.ServiceName}} para {{.PetName}} el {{.When}}.
+{{- if gt .Occurrences 1}} Se han confirmado {{.Occurrences}} citas de la serie.{{end}}{{end}}
+{{define "short"}}Confirmada: {{.ServiceName}} para {{.PetName}} con {{.ProviderName}}, {{.When}}.{{end}}`,
+
+	"booking_rescheduled": `
+{{define "subject"}}Reserva cambiada: {{.ServiceName}} para {{.PetName}}{{end}}
+{{define "body"}}Hola {{.RecipientName}}:
+
+{{.ServiceName}} para {{.PetName}} con {{.ProviderName}} ha pasado del {{.Previously}} al {{.When}}.
+{{- if gt .Occurrences 1}} {{.Occurrences}} citas de la serie se han movido lo mismo.{{end}}{{end}}
+{{define "short"}}Cambio: {{.ServiceName}} para {{.PetName}} con {{.ProviderName}} es ahora el {{.When}}.{{end}}`,
+
+	"booking_cancelled": `
+{{define "subject"}}Reserva cancelada: {{.ServiceName}} para {{.PetName}}{{end}}
+{{define "body"}}Hola {{.RecipientName}}:
+
+Se ha cancelado {{.ServiceName}} para {{.PetName}} con {{.ProviderName}} del {{.When}}.
+{{- if gt .Occurrences 1}} Se han cancelado {{.Occurrences}} citas de la serie.{{end}}
+{{- if .Reason}}
+
+Motivo: {{.Reason}}{{end}}{{end}}
+{{define "short"}}Cancelada: {{.ServiceName}} para {{.PetName}} con {{.ProviderName}}, {{.When}}.{{end}}`,
+
+	"booking_reminder": `
+{{define "subject"}}Recordatorio: {{.ServiceName}} para {{.PetName}} el {{.When}}{{end}}
+{{define "body"}}Hola {{.RecipientName}}:
+
+Te recordamos que {{.PetName}} tiene cita para {{.ServiceName}} con {{.ProviderName}} el {{.When}}.
+
+Confirma a {{.ProviderName}} que vendrás: {{.ConfirmURL}}
+
+¿No puedes venir? Cancela aquí: {{.CancelURL}}
+Pueden aplicarse cargos según la política de cancelación del proveedor.{{end}}
+{{define "short"}}Recordatorio: {{.ServiceName}} para {{.PetName}} con {{.ProviderName}}, {{.When}}. Confirmar: {{.ConfirmURL}} Cancelar: {{.CancelURL}}{{end}}`,
+
+	"waitlist_offer": `
+{{define "subject"}}Hay un hueco libre: {{.ServiceName}} para {{.PetName}} el {{.When}}{{end}}
+{{define "body"}}Hola {{.RecipientName}}:
+
+Se ha liberado un hueco que esperabas con {{.ProviderName}}: {{.ServiceName}} para {{.PetName}} el {{.When}}.
+
+Te lo guardamos hasta el {{.HoldUntil}}. Acéptalo desde tu lista de espera en la app antes de esa hora o pasará a la siguiente persona.{{end}}
+{{define "short"}}Hueco libre: {{.ServiceName}} para {{.PetName}} con {{.ProviderName}}, {{.When}}. Te lo guardamos hasta el {{.HoldUntil}}.{{end}}`,
+}
@@ -0,0 +1,123 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeclinedPaymentMethod is a payment method token the fake always declines.
+const DeclinedPaymentMethod = "tok_declined"
+
+// FakeProvider is an in-memory Provider for local development and tests. It
+// accepts any payment method except DeclinedPaymentMethod.
+type FakeProvider struct {
+	mu           sync.Mutex
+	transactions map[string]*Transaction
+	idempotency  map[string]string
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		transactions: make(map[string]*Transaction),
+		idempotency:  make(map[string]string),
+	}
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Transaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if req.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if req.PaymentMethod == DeclinedPaymentMethod {
+		return nil, ErrCardDeclined
+	}
+	if id, ok := p.idempotency[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		txn := *p.transactions[id]
+		return &txn, nil
+	}
+
+	txn := &Transaction{
+		ID:        fmt.Sprintf("fake_%s", uuid.New()),
+		Status:    TransactionAuthorized,
+		Currency:  req.Currency,
+		Amount:    req.Amount,
+		CreatedAt: time.Now(),
+	}
+	p.transactions[txn.ID] = txn
+	if req.IdempotencyKey != "" {
+		p.idempotency[req.IdempotencyKey] = txn.ID
+	}
+
+	result := *txn
+	return &result, nil
+}
+
+func (p *FakeProvider) Capture(ctx context.Context, transactionID string, amount int64) (*Transaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	txn, ok := p.transactions[transactionID]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	if txn.Status != TransactionAuthorized {
+		return nil, ErrTransactionNotActive
+	}
+	if amount <= 0 || amount > txn.Amount {
+		return nil, ErrInvalidAmount
+	}
+
+	txn.Status = TransactionCaptured
+	txn.CapturedAmount = amount
+
+	result := *txn
+	return &result, nil
+}
+
+func (p *FakeProvider) Void(ctx context.Context, transactionID string) (*Transaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	txn, ok := p.transactions[transactionID]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	if txn.Status != TransactionAuthorized {
+		return nil, ErrTransactionNotActive
+	}
+
+	txn.Status = TransactionVoided
+
+	result := *txn
+	return &result, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, transactionID string, amount int64) (*Transaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	txn, ok := p.transactions[transactionID]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	if txn.Status != TransactionCaptured {
+		return nil, ErrTransactionNotActive
+	}
+	if amount <= 0 || amount > txn.CapturedAmount-txn.RefundedAmount {
+		return nil, ErrInvalidAmount
+	}
+
+	txn.RefundedAmount += amount
+	if txn.RefundedAmount == txn.CapturedAmount {
+		txn.Status = TransactionRefunded
+	}
+
+	result := *txn
+	return &result, nil
+}
@@ -0,0 +1,117 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func authorize(t *testing.T, p *FakeProvider, amount int64, key string) *Transaction {
+	t.Helper()
+	txn, err := p.Authorize(context.Background(), AuthorizeRequest{
+		Amount:         amount,
+		Currency:       "USD",
+		PaymentMethod:  "tok_visa",
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		t.Fatalf("Authorize(%d): %v", amount, err)
+	}
+	return txn
+}
+
+func TestFakeDepositThenBalance(t *testing.T) {
+	ctx := context.Background()
+	p := NewFakeProvider()
+
+	deposit := authorize(t, p, 2000, "deposit")
+	captured, err := p.Capture(ctx, deposit.ID, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if captured.Status != TransactionCaptured || captured.CapturedAmount != 2000 {
+		t.Fatalf("deposit = %+v, want captured 2000", captured)
+	}
+	if _, err := p.Capture(ctx, deposit.ID, 2000); !errors.Is(err, ErrTransactionNotActive) {
+		t.Errorf("second capture: %v, want ErrTransactionNotActive", err)
+	}
+
+	balance := authorize(t, p, 6000, "balance")
+	if balance.ID == deposit.ID {
+		t.Fatal("balance reused the deposit's transaction")
+	}
+	if _, err := p.Capture(ctx, balance.ID, 6001); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("over-capture: %v, want ErrInvalidAmount", err)
+	}
+	if _, err := p.Capture(ctx, balance.ID, 6000); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFakeRefund(t *testing.T) {
+	ctx := context.Background()
+	p := NewFakeProvider()
+
+	txn := authorize(t, p, 5000, "")
+	if _, err := p.Refund(ctx, txn.ID, 100); !errors.Is(err, ErrTransactionNotActive) {
+		t.Errorf("refund before capture: %v, want ErrTransactionNotActive", err)
+	}
+	if _, err := p.Capture(ctx, txn.ID, 5000); err != nil {
+		t.Fatal(err)
+	}
+
+	partial, err := p.Refund(ctx, txn.ID, 1500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if partial.Status != TransactionCaptured || partial.RefundedAmount != 1500 {
+		t.Errorf("after partial refund = %+v", partial)
+	}
+	if _, err := p.Refund(ctx, txn.ID, 3501); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("refund beyond captured: %v, want ErrInvalidAmount", err)
+	}
+
+	full, err := p.Refund(ctx, txn.ID, 3500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if full.Status != TransactionRefunded || full.RefundedAmount != 5000 {
+		t.Errorf("after full refund = %+v", full)
+	}
+}
+
+func TestFakeVoid(t *testing.T) {
+	ctx := context.Background()
+	p := NewFakeProvider()
+
+	txn := authorize(t, p, 1000, "")
+	voided, err := p.Void(ctx, txn.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if voided.Status != TransactionVoided {
+		t.Errorf("status = %s, want voided", voided.Status)
+	}
+	if _, err := p.Capture(ctx, txn.ID, 1000); !errors.Is(err, ErrTransactionNotActive) {
+		t.Errorf("capture after void: %v, want ErrTransactionNotActive", err)
+	}
+}
+
+func TestFakeDeclineAndIdempotency(t *testing.T) {
+	ctx := context.Background()
+	p := NewFakeProvider()
+
+	_, err := p.Authorize(ctx, AuthorizeRequest{Amount: 1000, Currency: "USD", PaymentMethod: DeclinedPaymentMethod})
+	if !errors.Is(err, ErrCardDeclined) {
+		t.Errorf("declined card: %v, want ErrCardDeclined", err)
+	}
+	if _, err := p.Authorize(ctx, AuthorizeRequest{Amount: 0, Currency: "USD", PaymentMethod: "tok_visa"}); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("zero amount: %v, want ErrInvalidAmount", err)
+	}
+
+	first := authorize(t, p, 1000, "key")
+	again := authorize(t, p, 1000, "key")
+	if first.ID != again.ID {
+		t.Errorf("repeated idempotency key made a second transaction: %s, %s", first.ID, again.ID)
+	}
+}
@@ -0,0 +1,56 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrCardDeclined         = errors.New("card declined")
+	ErrTransactionNotFound  = errors.New("transaction not found")
+	ErrInvalidAmount        = errors.New("invalid amount")
+	ErrTransactionNotActive = errors.New("transaction cannot be modified in its current state")
+)
+
+type TransactionStatus string
+
+const (
+	TransactionAuthorized TransactionStatus = "authorized"
+	TransactionCaptured   TransactionStatus = "captured"
+	TransactionVoided     TransactionStatus = "voided"
+	TransactionRefunded   TransactionStatus = "refunded"
+)
+
+// Transaction is the processor's view of a single authorization.
+type Transaction struct {
+	ID             string            `json:"id"`
+	Status         TransactionStatus `json:"status"`
+	Currency       string            `json:"currency"`
+	Amount         int64             `json:"amount"`
+	CapturedAmount int64             `json:"captured_amount"`
+	RefundedAmount int64             `json:"refunded_amount"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+type AuthorizeRequest struct {
+	Amount         int64
+	Currency       string
+	PaymentMethod  string
+	Description    string
+	IdempotencyKey string
+}
+
+// Provider is implemented by card processors. Amounts are in the minor units
+// of the transaction's ISO 4217 currency. Implementations must treat a repeated IdempotencyKey as the same
+// authorization rather than charging twice.
+type Provider interface {
+	// Authorize places a hold for req.Amount on the payment method.
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Transaction, error)
+	// Capture collects up to the authorized amount; any remainder is released.
+	Capture(ctx context.Context, transactionID string, amount int64) (*Transaction, error)
+	// Void releases an authorization that has not been captured.
+	Void(ctx context.Context, transactionID string) (*Transaction, error)
+	// Refund returns up to the captured amount to the payment method.
+	Refund(ctx context.Context, transactionID string, amount int64) (*Transaction, error)
+}
@@ -0,0 +1,143 @@
+// Package pdf writes simple single-font, text-only PDF documents, enough for
+// invoices and receipts without pulling in a layout engine.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.0
+	PageHeight = 842.0
+)
+
+type textItem struct {
+	x, y float64
+	size float64
+	bold bool
+	text string
+}
+
+// Document collects text placed on pages and serialises it as PDF 1.4 using
+// the standard Helvetica fonts, so no font data needs to be embedded.
+type Document struct {
+	pages [][]textItem
+}
+
+func New() *Document {
+	return &Document{pages: [][]textItem{{}}}
+}
+
+// AddPage starts a new page; subsequent text goes onto it.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, []textItem{})
+}
+
+// Text places text with its baseline at (x, y), measured in points from the
+// top-left corner of the current page.
+func (d *Document) Text(x, y, size float64, bold bool, text string) {
+	last := len(d.pages) - 1
+	d.pages[last] = append(d.pages[last], textItem{x: x, y: y, size: size, bold: bold, text: text})
+}
+
+// TextRight places text so that it ends at x, using an approximate average
+// Helvetica glyph width.
+func (d *Document) TextRight(x, y, size float64, bold bool, text string) {
+	d.Text(x-approxWidth(text, size), y, size, bold, text)
+}
+
+func approxWidth(text string, size float64) float64 {
+	return float64(len([]rune(text))) * size * 0.5
+}
+
+// winAnsi maps the characters WinAnsiEncoding places in 0x80–0x9F, where
+// Latin-1 has control codes, to their bytes.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// escape encodes text as a PDF literal string in WinAnsiEncoding: Latin-1
+// plus the characters in winAnsi. Anything else, including the C1 control
+// codes whose bytes WinAnsiEncoding gives to other characters, is replaced
+// with '?'.
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32:
+			b.WriteByte(' ')
+		case r < 0x7F || (r >= 0xA0 && r < 256):
+			b.WriteByte(byte(r))
+		default:
+			if c, ok := winAnsi[r]; ok {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+// Bytes renders the document.
+func (d *Document) Bytes() []byte {
+	var objects []string
+
+	// Objects 1 and 2 are the catalog and page tree, 3 and 4 the fonts; each
+	// page then takes a page object followed by its content stream.
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	)
+
+	for i, items := range d.pages {
+		var content bytes.Buffer
+		for _, item := range items {
+			font := "F1"
+			if item.bold {
+				font = "F2"
+			}
+			fmt.Fprintf(&content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+				font, item.size, item.x, PageHeight-item.y, escape(item.text))
+		}
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+				"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				PageWidth, PageHeight, 6+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return out.Bytes()
+}
@@ -0,0 +1,26 @@
+package pdf
+
+import "testing"
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"Full groom (large)", `Full groom \(large\)`},
+		{`C:\pets`, `C:\\pets`},
+		{"tab\there", "tab here"},
+		{"Café Bärli", "Caf\xe9 B\xe4rli"},
+		// Characters WinAnsiEncoding keeps in 0x80–0x9F.
+		{"€12 – “Rex”", "\x8012 \x96 \x93Rex\x94"},
+		{"It’s Šarik…", "It\x92s \x8aarik\x85"},
+		// C1 control codes would show as the characters above, and there
+		// is no glyph for the rest.
+		{"\u0080\u0093\u007f", "???"},
+		{"犬 🐕", "? ?"},
+	}
+	for _, tt := range tests {
+		if got := escape(tt.text); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package realtime
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// clientBuffer is how many events a slow client may fall behind by before
+// further events are dropped for it.
+const clientBuffer = 32
+
+// Hub tracks the clients connected to this instance and hands each event to
+// the ones belonging to its users.
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[uuid.UUID]map[chan Event]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{clients: make(map[uuid.UUID]map[chan Event]struct{})}
+}
+
+// Register adds a client for userID. The returned function removes it and
+// must be called when the client disconnects.
+func (h *Hub) Register(userID uuid.UUID) (<-chan Event, func()) {
+	ch := make(chan Event, clientBuffer)
+
+	h.mu.Lock()
+	if h.clients[userID] == nil {
+		h.clients[userID] = make(map[chan Event]struct{})
+	}
+	h.clients[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.clients[userID], ch)
+		if len(h.clients[userID]) == 0 {
+			delete(h.clients, userID)
+		}
+	}
+}
+
+// Deliver passes event to every connected client of its users without
+// blocking.
+func (h *Hub) Deliver(event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, userID := range event.UserIDs {
+		for ch := range h.clients[userID] {
+			select {
+			case ch <- event:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package realtime
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// postgresChannel is the LISTEN/NOTIFY channel events travel on.
+const postgresChannel = "realtime_events"
+
+// PostgresPubSub fans events out between instances with LISTEN/NOTIFY on
+// the database they already share. Payloads are limited to 8000 bytes by
+// Postgres.
+type PostgresPubSub struct {
+	db  *sql.DB
+	dsn string
+}
+
+func NewPostgresPubSub(db *sql.DB, dsn string) *PostgresPubSub {
+	return &PostgresPubSub{db: db, dsn: dsn}
+}
+
+func (p *PostgresPubSub) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = p.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, postgresChannel, string(payload))
+	return err
+}
+
+func (p *PostgresPubSub) Subscribe(ctx context.Context, handle func(Event)) error {
+	listener := pq.NewListener(p.dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Realtime listener: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(postgresChannel); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case n := <-listener.Notify:
+			// A nil notification means the connection was re-established;
+			// anything sent meanwhile is lost, as with a dropped client.
+			if n == nil {
+				continue
+			}
+			var event Event
+			if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+				log.Printf("Realtime listener: bad payload: %v", err)
+				continue
+			}
+			handle(event)
+		case <-time.After(time.Minute):
+			go listener.Ping()
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types pushed to clients.
+const (
+	BookingCreated       = "booking.created"
+	BookingStatusChanged = "booking.status_changed"
+	BookingRescheduled   = "booking.rescheduled"
+	BookingUpdated       = "booking.updated"
+	WalkStarted          = "walk.started"
+	WalkUpdated          = "walk.updated"
+	WalkEnded            = "walk.ended"
+)
+
+// Event is a change pushed to the users it concerns.
+type Event struct {
+	Type    string          `json:"type"`
+	UserIDs []uuid.UUID     `json:"user_ids"`
+	Data    json.RawMessage `json:"data"`
+	At      time.Time       `json:"at"`
+}
+
+// PubSub carries events between server instances. Every instance subscribes
+// and delivers what it receives to its own connected clients, so an event
+// published anywhere reaches the user wherever they are connected.
+type PubSub interface {
+	Publish(ctx context.Context, event Event) error
+	// Subscribe calls handle with every event published by any instance
+	// until ctx is cancelled. handle must not block.
+	Subscribe(ctx context.Context, handle func(Event)) error
+}
+
+// MemoryPubSub delivers events within a single instance.
+type MemoryPubSub struct {
+	mu       sync.RWMutex
+	next     int
+	handlers map[int]func(Event)
+}
+
+func NewMemoryPubSub() *MemoryPubSub {
+	return &MemoryPubSub{handlers: make(map[int]func(Event))}
+}
+
+func (p *MemoryPubSub) Publish(ctx context.Context, event Event) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, handle := range p.handlers {
+		handle(event)
+	}
+	return nil
+}
+
+func (p *MemoryPubSub) Subscribe(ctx context.Context, handle func(Event)) error {
+	p.mu.Lock()
+	id := p.next
+	p.next++
+	p.handlers[id] = handle
+	p.mu.Unlock()
+
+	<-ctx.Done()
+
+	p.mu.Lock()
+	delete(p.handlers, id)
+	p.mu.Unlock()
+	return ctx.Err()
+}
+
+// Listen keeps a subscription to p running until ctx is cancelled. Whenever
+// Subscribe fails, for example because the database is unreachable at boot,
+// it tries again after a delay that doubles from minBackoff up to
+// maxBackoff. A subscription that held for maxBackoff resets the delay.
+func Listen(ctx context.Context, p PubSub, handle func(Event), minBackoff, maxBackoff time.Duration) {
+	backoff := minBackoff
+	for {
+		started := time.Now()
+		err := p.Subscribe(ctx, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) >= maxBackoff {
+			backoff = minBackoff
+		}
+		log.Printf("Event subscription ended, retrying in %s: %v", backoff, err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
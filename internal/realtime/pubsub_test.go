@@ -0,0 +1,62 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyPubSub fails its first failures subscriptions, then subscribes like
+// MemoryPubSub.
+type flakyPubSub struct {
+	*MemoryPubSub
+	failures int32
+	attempts atomic.Int32
+}
+
+func (p *flakyPubSub) Subscribe(ctx context.Context, handle func(Event)) error {
+	if p.attempts.Add(1) <= p.failures {
+		return errors.New("listen failed")
+	}
+	return p.MemoryPubSub.Subscribe(ctx, handle)
+}
+
+func TestListenRetries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &flakyPubSub{MemoryPubSub: NewMemoryPubSub(), failures: 3}
+	received := make(chan Event, 1)
+	done := make(chan struct{})
+	go func() {
+		Listen(ctx, p, func(e Event) { received <- e }, time.Millisecond, 5*time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.After(time.Second)
+	for p.attempts.Load() <= p.failures {
+		select {
+		case <-deadline:
+			t.Fatalf("gave up after %d attempts", p.attempts.Load())
+		case <-time.After(time.Millisecond):
+		}
+	}
+	// The fourth attempt subscribes; publish until it is registered.
+	for delivered := false; !delivered; {
+		p.Publish(ctx, Event{Type: BookingCreated})
+		select {
+		case <-received:
+			delivered = true
+		case <-deadline:
+			t.Fatal("no event delivered after resubscribing")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Listen did not return after cancel")
+	}
+}
@@ -0,0 +1,107 @@
+// Package safehttp makes HTTP requests to URLs that users supply, such as
+// webhook endpoints and calendar servers, without letting them reach the
+// server's own network. Addresses are checked when a URL is saved, so users
+// get an error straight away, and again when each connection is made, since
+// DNS can change in between.
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrDisallowedAddress is returned for hosts that resolve to loopback,
+// private, link-local or other non-public addresses.
+var ErrDisallowedAddress = errors.New("address is not publicly routable")
+
+// blockedPrefixes are ranges not covered by the netip.Addr predicates that
+// still must not be reached.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, which can map to anything
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+}
+
+// Allowed reports whether ip is a public unicast address.
+func Allowed(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL resolves rawURL's host and returns ErrDisallowedAddress if any
+// address it resolves to is not allowed.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if !Allowed(addr) {
+			return fmt.Errorf("%w: %s", ErrDisallowedAddress, addr)
+		}
+	}
+	return nil
+}
+
+// NewClient returns a client with the given timeout that refuses to connect
+// to disallowed addresses and does not follow redirects, which could
+// otherwise lead it anywhere. Proxies from the environment are not used, as
+// they would connect on the client's behalf.
+func NewClient(timeout time.Duration) *http.Client {
+	return newClient(timeout, Allowed)
+}
+
+func newClient(timeout time.Duration, allowed func(netip.Addr) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		// Control runs after name resolution with the address actually
+		// being connected to.
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !allowed(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrDisallowedAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false}, // cloud metadata
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"64:ff9b::a9fe:a9fe", false},
+	}
+	for _, tt := range tests {
+		if got := Allowed(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("Allowed(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+	for _, u := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"https://[::1]/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.5/",
+	} {
+		if err := CheckURL(ctx, u); !errors.Is(err, ErrDisallowedAddress) {
+			t.Errorf("CheckURL(%s): %v, want ErrDisallowedAddress", u, err)
+		}
+	}
+	if err := CheckURL(ctx, "https://93.184.216.34/hook"); err != nil {
+		t.Errorf("public address: %v", err)
+	}
+}
+
+func TestClientRefusesLocalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := NewClient(time.Second).Get(server.URL)
+	if !errors.Is(err, ErrDisallowedAddress) {
+		t.Errorf("request to %s: %v, want ErrDisallowedAddress", server.URL, err)
+	}
+}
+
+func TestClientDoesNotFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/start" {
+			http.Redirect(w, r, "/elsewhere", http.StatusFound)
+			return
+		}
+		t.Errorf("redirect to %s was followed", r.URL.Path)
+	}))
+	defer server.Close()
+
+	// Allow the test server's loopback address, to see what happens next.
+	client := newClient(time.Second, func(netip.Addr) bool { return true })
+	resp, err := client.Get(server.URL + "/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("status = %d, want the redirect itself", resp.StatusCode)
+	}
+}
@@ -0,0 +1,77 @@
+// Package secrets encrypts credentials the server has to store and use
+// again, such as calendar passwords, so a copy of the database alone does
+// not reveal them.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// ErrUnreadable is returned for sealed values that were tampered with or
+// sealed under a different key.
+var ErrUnreadable = errors.New("secret cannot be decrypted")
+
+// sealedPrefix marks values sealed by a Box, versioned so the scheme can
+// change later.
+const sealedPrefix = "v1:"
+
+// Box seals values with AES-256-GCM under a key derived from a configured
+// secret.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// NewBox derives the encryption key from secret, which should be long and
+// random and kept out of the database.
+func NewBox(secret string) *Box {
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		panic(err) // a 32-byte key is always valid
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return &Box{aead: aead}
+}
+
+// Seal encrypts plaintext into a printable value for storage.
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return sealedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value from Seal. Values stored before encryption was
+// introduced have no prefix and are returned as they are; IsSealed tells
+// them apart so they can be sealed on their next write.
+func (b *Box) Open(value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, sealedPrefix))
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return "", ErrUnreadable
+	}
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrUnreadable
+	}
+	return string(plaintext), nil
+}
+
+// IsSealed reports whether value was produced by Seal.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
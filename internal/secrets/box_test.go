@@ -0,0 +1,58 @@
+package secrets
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBoxRoundTrip(t *testing.T) {
+	box := NewBox("test key")
+
+	sealed, err := box.Seal("app-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) || strings.Contains(sealed, "app-password") {
+		t.Fatalf("sealed value %q", sealed)
+	}
+	if again, _ := box.Seal("app-password"); again == sealed {
+		t.Error("sealing twice gave the same value; nonces are not random")
+	}
+
+	opened, err := box.Open(sealed)
+	if err != nil || opened != "app-password" {
+		t.Errorf("Open = %q, %v", opened, err)
+	}
+}
+
+func TestBoxRejectsTamperingAndOtherKeys(t *testing.T) {
+	box := NewBox("test key")
+	sealed, err := box.Seal("app-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewBox("other key").Open(sealed); !errors.Is(err, ErrUnreadable) {
+		t.Errorf("other key: %v, want ErrUnreadable", err)
+	}
+	tampered := sealed[:len(sealed)-2] + "AA"
+	if tampered == sealed {
+		tampered = sealed[:len(sealed)-2] + "BB"
+	}
+	if _, err := box.Open(tampered); !errors.Is(err, ErrUnreadable) {
+		t.Errorf("tampered: %v, want ErrUnreadable", err)
+	}
+	if _, err := box.Open(sealedPrefix + "not base64!"); !errors.Is(err, ErrUnreadable) {
+		t.Errorf("garbage: %v, want ErrUnreadable", err)
+	}
+}
+
+func TestBoxOpensLegacyPlaintext(t *testing.T) {
+	if got, err := NewBox("test key").Open("stored before encryption"); err != nil || got != "stored before encryption" {
+		t.Errorf("Open(plaintext) = %q, %v", got, err)
+	}
+	if IsSealed("stored before encryption") {
+		t.Error("plaintext reported as sealed")
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	"pet-grooming-app/internal/models"
@@ -47,13 +48,32 @@ func (s *AuthService) Register(req models.CreateUserRequest) (*models.User, erro
 		UpdatedAt: time.Now(),
 	}
 
+	var referredBy uuid.NullUUID
+	if req.ReferralCode != "" {
+		err := s.db.QueryRow(`SELECT id FROM users WHERE referral_code = $1`,
+			strings.ToUpper(req.ReferralCode)).Scan(&referredBy)
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidReferralCode
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	referralCode, err := newReferralCode()
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		INSERT INTO users (id, email, password_hash, first_name, last_name, phone, address, role, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO users (id, email, password_hash, first_name, last_name, phone, address, role, created_at, updated_at,
+			referral_code, referred_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at, updated_at`
 
 	err = s.db.QueryRow(query, user.ID, user.Email, user.Password, user.FirstName,
-		user.LastName, user.Phone, user.Address, user.Role, user.CreatedAt, user.UpdatedAt).
+		user.LastName, user.Phone, user.Address, user.Role, user.CreatedAt, user.UpdatedAt,
+		referralCode, referredBy).
 		Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
@@ -121,6 +141,11 @@ func (s *AuthService) ValidateToken(tokenString string) (uuid.UUID, error) {
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		// Single-purpose tokens, such as stream tokens, are not sessions
+		if _, scoped := claims["purpose"]; scoped {
+			return uuid.Nil, errors.New("invalid token")
+		}
+
 		userIDStr, ok := claims["user_id"].(string)
 		if !ok {
 			return uuid.Nil, errors.New("invalid user_id in token")
@@ -0,0 +1,1293 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/notify"
+	"pet-grooming-app/internal/realtime"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrBookingNotFound   = errors.New("booking not found")
+	ErrPetNotFound       = errors.New("pet not found")
+	ErrServiceNotFound   = errors.New("service not found")
+	ErrSlotUnavailable   = errors.New("provider is not available at the requested time")
+	ErrForbidden         = errors.New("not allowed to modify this booking")
+	ErrInvalidTransition = errors.New("invalid booking status transition")
+	ErrBookingInactive   = errors.New("booking is no longer active")
+	ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+	ErrBookingInPast     = errors.New("cannot book a time in the past")
+)
+
+// SlotConflictError lists the occurrences of a recurring request that clash
+// with existing bookings.
+type SlotConflictError struct {
+	Conflicts []models.BookingConflict
+}
+
+func (e *SlotConflictError) Error() string {
+	return ErrSlotUnavailable.Error()
+}
+
+func (e *SlotConflictError) Unwrap() error {
+	return ErrSlotUnavailable
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx so helpers can run inside
+// or outside a transaction.
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+const bookingColumns = `id, user_id, pet_id, service_id, provider_id, scheduled_time, status, notes, total_price, series_id, created_at, updated_at,
+	cancellation_reason, cancelled_by, cancelled_at, cancellation_fee, no_show_fee, deposit_required,
+	payment_status, deposit_amount, amount_paid, payment_method, currency,
+	subtotal, tax_amount, tax_rate, credit_id, promo_code_id, discount,
+	points_redeemed, points_discount, owner_confirmed_at, staff_id,
+	check_out_time, nights`
+
+func scanBooking(row rowScanner) (*models.Booking, error) {
+	var booking models.Booking
+	var seriesID, cancelledBy, creditID, promoCodeID, staffID uuid.NullUUID
+	var cancellationReason sql.NullString
+	var cancelledAt, ownerConfirmedAt, checkOutTime sql.NullTime
+	var currency string
+	err := row.Scan(
+		&booking.ID, &booking.UserID, &booking.PetID, &booking.ServiceID, &booking.ProviderID,
+		&booking.ScheduledTime, &booking.Status, &booking.Notes, &booking.TotalPrice.Amount,
+		&seriesID, &booking.CreatedAt, &booking.UpdatedAt,
+		&cancellationReason, &cancelledBy, &cancelledAt, &booking.CancellationFee.Amount,
+		&booking.NoShowFee.Amount, &booking.DepositRequired,
+		&booking.PaymentStatus, &booking.DepositAmount.Amount, &booking.AmountPaid.Amount, &booking.PaymentMethod,
+		&currency,
+		&booking.Subtotal.Amount, &booking.TaxAmount.Amount, &booking.TaxRate, &creditID,
+		&promoCodeID, &booking.Discount.Amount,
+		&booking.PointsRedeemed, &booking.PointsDiscount.Amount, &ownerConfirmedAt, &staffID,
+		&checkOutTime, &booking.Nights,
+	)
+	if err != nil {
+		return nil, err
+	}
+	booking.SetCurrency(currency)
+	if seriesID.Valid {
+		booking.SeriesID = &seriesID.UUID
+	}
+	booking.CancellationReason = cancellationReason.String
+	if cancelledBy.Valid {
+		booking.CancelledBy = &cancelledBy.UUID
+	}
+	if cancelledAt.Valid {
+		booking.CancelledAt = &cancelledAt.Time
+	}
+	if creditID.Valid {
+		booking.CreditID = &creditID.UUID
+	}
+	if promoCodeID.Valid {
+		booking.PromoCodeID = &promoCodeID.UUID
+	}
+	if ownerConfirmedAt.Valid {
+		booking.OwnerConfirmedAt = &ownerConfirmedAt.Time
+	}
+	if staffID.Valid {
+		booking.StaffID = &staffID.UUID
+	}
+	if checkOutTime.Valid {
+		booking.CheckOutTime = &checkOutTime.Time
+	}
+	return &booking, nil
+}
+
+func scanBookings(rows *sql.Rows) ([]models.Booking, error) {
+	defer rows.Close()
+
+	bookings := []models.Booking{}
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, *booking)
+	}
+	return bookings, rows.Err()
+}
+
+func insertBooking(q queryer, b *models.Booking) error {
+	query := `
+		INSERT INTO bookings (` + bookingColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35)`
+
+	_, err := q.Exec(query, b.ID, b.UserID, b.PetID, b.ServiceID, b.ProviderID,
+		b.ScheduledTime, b.Status, b.Notes, b.TotalPrice.Amount, b.SeriesID, b.CreatedAt, b.UpdatedAt,
+		b.CancellationReason, b.CancelledBy, b.CancelledAt, b.CancellationFee.Amount,
+		b.NoShowFee.Amount, b.DepositRequired,
+		b.PaymentStatus, b.DepositAmount.Amount, b.AmountPaid.Amount, b.PaymentMethod,
+		b.TotalPrice.Currency,
+		b.Subtotal.Amount, b.TaxAmount.Amount, b.TaxRate, b.CreditID,
+		b.PromoCodeID, b.Discount.Amount,
+		b.PointsRedeemed, b.PointsDiscount.Amount, b.OwnerConfirmedAt, b.StaffID,
+		b.CheckOutTime, b.Nights)
+	return err
+}
+
+type BookingService struct {
+	db       *sql.DB
+	payments *PaymentService
+	invoices *InvoiceService
+	loyalty  *LoyaltyService
+	events   realtime.PubSub
+}
+
+func NewBookingService(db *sql.DB, payments *PaymentService, invoices *InvoiceService, loyalty *LoyaltyService, events realtime.PubSub) *BookingService {
+	return &BookingService{db: db, payments: payments, invoices: invoices, loyalty: loyalty, events: events}
+}
+
+// discardBookings deletes bookings that never became valid, such as those
+// whose deposit could not be collected, giving back any promo code use,
+// loyalty points and gift card balance and withdrawing their webhooks.
+func discardBookings(db *sql.DB, ids ...uuid.UUID) {
+	for _, id := range ids {
+		if booking, err := getBookingRow(db, id); err == nil {
+			if err := refundBookingPoints(db, booking); err != nil {
+				log.Printf("Failed to refund points for booking %s: %v", id, err)
+			}
+			if err := restoreGiftCardPayments(db, booking); err != nil {
+				log.Printf("Failed to restore gift card balance for booking %s: %v", id, err)
+			}
+		}
+		if _, err := db.Exec(`
+			UPDATE promo_codes SET uses = uses - 1
+			WHERE id = (SELECT promo_code_id FROM bookings WHERE id = $1)`, id); err != nil {
+			log.Printf("Failed to release promo code use for booking %s: %v", id, err)
+		}
+		if _, err := db.Exec(`
+			UPDATE webhook_deliveries SET status = $1, last_error = 'booking was discarded'
+			WHERE status = 'pending' AND event LIKE 'booking.%' AND payload->'data'->>'id' = $2`,
+			models.WebhookDeliveryFailed, id.String()); err != nil {
+			log.Printf("Failed to withdraw webhooks for booking %s: %v", id, err)
+		}
+		if _, err := db.Exec(`DELETE FROM bookings WHERE id = $1`, id); err != nil {
+			log.Printf("Failed to discard booking %s: %v", id, err)
+		}
+	}
+}
+
+func (s *BookingService) getBooking(q queryer, id uuid.UUID, forUpdate bool) (*models.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	booking, err := scanBooking(q.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrBookingNotFound
+	}
+	return booking, err
+}
+
+// Get returns a booking visible to userID, either as its owner or provider.
+func (s *BookingService) Get(id, userID uuid.UUID) (*models.Booking, error) {
+	booking, err := s.getBooking(s.db, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID && booking.ProviderID != userID {
+		return nil, ErrBookingNotFound
+	}
+	return booking, nil
+}
+
+// List returns every booking where userID is the owner or the provider.
+func (s *BookingService) List(userID uuid.UUID) ([]models.Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings WHERE user_id = $1 OR provider_id = $1
+		ORDER BY scheduled_time`
+
+	rows, err := s.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookings(rows)
+}
+
+// Details loads the pet, service and owner attached to a booking.
+func (s *BookingService) Details(booking models.Booking) (*models.BookingWithDetails, error) {
+	details := models.BookingWithDetails{Booking: booking}
+
+	err := s.db.QueryRow(`
+		SELECT id, owner_id, name, species, breed, age, weight, color, notes, photo_url, created_at, updated_at
+		FROM pets WHERE id = $1`, booking.PetID).Scan(
+		&details.Pet.ID, &details.Pet.OwnerID, &details.Pet.Name, &details.Pet.Species, &details.Pet.Breed,
+		&details.Pet.Age, &details.Pet.Weight, &details.Pet.Color, &details.Pet.Notes, &details.Pet.PhotoURL,
+		&details.Pet.CreatedAt, &details.Pet.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := getService(s.db, booking.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	details.Service = *service
+
+	err = s.db.QueryRow(`
+		SELECT id, email, first_name, last_name, phone, address, role, created_at, updated_at
+		FROM users WHERE id = $1`, booking.UserID).Scan(
+		&details.User.ID, &details.User.Email, &details.User.FirstName, &details.User.LastName,
+		&details.User.Phone, &details.User.Address, &details.User.Role, &details.User.CreatedAt, &details.User.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &details, nil
+}
+
+func getService(q queryer, id uuid.UUID) (*models.Service, error) {
+	var service models.Service
+	query := `
+		SELECT id, provider_id, name, description, category, price, currency, duration_minutes, available, created_at, updated_at
+		FROM services WHERE id = $1`
+
+	err := q.QueryRow(query, id).Scan(
+		&service.ID, &service.ProviderID, &service.Name, &service.Description, &service.Category,
+		&service.Price.Amount, &service.Price.Currency, &service.Duration, &service.Available,
+		&service.CreatedAt, &service.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrServiceNotFound
+	}
+	return &service, err
+}
+
+func checkPetOwner(q queryer, petID, ownerID uuid.UUID) error {
+	var id uuid.UUID
+	err := q.QueryRow(`SELECT id FROM pets WHERE id = $1 AND owner_id = $2`, petID, ownerID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrPetNotFound
+	}
+	return err
+}
+
+// occupyingCategories are the stay categories whose bookings keep the
+// provider busy until check-out; see ServiceType.OccupiesProvider.
+var occupyingCategories = []string{string(models.ServiceSitting)}
+
+// lockProvider serialises booking writes for one provider so that concurrent
+// requests cannot both pass the availability check for the same slot.
+func lockProvider(q queryer, providerID uuid.UUID) error {
+	var id uuid.UUID
+	return q.QueryRow(`SELECT id FROM users WHERE id = $1 FOR UPDATE`, providerID).Scan(&id)
+}
+
+// checkAvailability returns ErrSlotUnavailable if service cannot be booked
+// at [start, start+duration). Busy time from the provider's own calendar
+// always blocks the slot, as does running out of a resource the service
+// uses. A provider working alone is also unavailable
+// during any active booking or unexpired waitlist hold; at a salon the slot
+// needs a free groomer instead, and the one chosen is returned. staffID asks
+// for a particular groomer. checkOut is the end of a stay; stays that occupy
+// the provider, here and among existing bookings, hold them until check-out.
+// Bookings listed in exclude are ignored, which lets a booking be moved
+// without clashing with itself.
+func checkAvailability(q queryer, service *models.Service, staffID *uuid.UUID, start time.Time, checkOut *time.Time, exclude ...uuid.UUID) (*uuid.UUID, error) {
+	visitEnd := start.Add(time.Duration(service.Duration) * time.Minute)
+	end := visitEnd
+	if checkOut != nil && service.Category.OccupiesProvider() {
+		end = *checkOut
+	}
+	excluded := make([]string, len(exclude))
+	for i, id := range exclude {
+		excluded[i] = id.String()
+	}
+
+	query := `
+		SELECT b.staff_id FROM bookings b
+		JOIN services s ON s.id = b.service_id
+		WHERE b.provider_id = $1
+			AND b.status IN ('pending', 'confirmed', 'in_progress')
+			AND b.scheduled_time < $3
+			AND CASE WHEN s.category = ANY($5::text[])
+				THEN COALESCE(b.check_out_time, b.scheduled_time + s.duration_minutes * INTERVAL '1 minute')
+				ELSE b.scheduled_time + s.duration_minutes * INTERVAL '1 minute' END > $2
+			AND NOT (b.id = ANY($4::uuid[]))`
+
+	holdQuery := `
+		SELECT COUNT(*) FROM waitlist_entries w
+		JOIN services s ON s.id = w.service_id
+		WHERE w.provider_id = $1
+			AND w.status = 'offered'
+			AND w.hold_expires_at > NOW()
+			AND w.offered_time < $3
+			AND w.offered_time + s.duration_minutes * INTERVAL '1 minute' > $2`
+
+	busyQuery := `
+		SELECT COUNT(*) FROM busy_blocks
+		WHERE provider_id = $1 AND start_time < $3 AND end_time > $2`
+
+	var count int
+	if err := q.QueryRow(busyQuery, service.ProviderID, start, end).Scan(&count); err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrSlotUnavailable
+	}
+	if err := checkResources(q, service, start, excluded); err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(query, service.ProviderID, start, end, pq.Array(excluded), pq.Array(occupyingCategories))
+	if err != nil {
+		return nil, err
+	}
+	booked := make(map[uuid.UUID]bool)
+	unassigned := 0
+	for rows.Next() {
+		var bookedStaff uuid.NullUUID
+		if err := rows.Scan(&bookedStaff); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if bookedStaff.Valid {
+			booked[bookedStaff.UUID] = true
+		} else {
+			unassigned++
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var holds int
+	if err := q.QueryRow(holdQuery, service.ProviderID, start, end).Scan(&holds); err != nil {
+		return nil, err
+	}
+
+	org, staff, err := activeStaff(q, service.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	if staff == nil {
+		if staffID != nil {
+			return nil, ErrStaffNotFound
+		}
+		if len(booked) > 0 || unassigned > 0 || holds > 0 {
+			return nil, ErrSlotUnavailable
+		}
+		return nil, nil
+	}
+	// A sitter stays with the pets until check-out, well outside their
+	// rostered hours, so only the check-in visit has to fit a shift; the
+	// whole stay still counts against their other bookings above.
+	return assignStaff(org, staff, service, staffID, start, visitEnd, booked, unassigned+holds)
+}
+
+// Create books a single appointment for the pet's owner and collects its
+// deposit, if any. A booking whose deposit fails is discarded.
+func (s *BookingService) Create(userID uuid.UUID, req models.CreateBookingRequest) (*models.Booking, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	booking, err := createBooking(tx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if err := s.payments.CollectDeposit(booking); err != nil {
+		discardBookings(s.db, booking.ID)
+		return nil, err
+	}
+	publishBookingsAfterCommit(s.events, realtime.BookingCreated, *booking)
+	return booking, nil
+}
+
+func createBooking(tx *sql.Tx, userID uuid.UUID, req models.CreateBookingRequest) (*models.Booking, error) {
+	terms, err := prepareCreate(tx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	staffID, err := checkAvailability(tx, terms.service, req.StaffID, req.ScheduledTime, req.CheckOutTime)
+	if err != nil {
+		return nil, err
+	}
+	if terms.nights > 0 {
+		if err := checkNights(tx, terms.service, terms.loc, req.ScheduledTime, *req.CheckOutTime, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	booking := newBooking(userID, req, terms, req.ScheduledTime)
+	booking.StaffID = staffID
+	if err := insertBooking(tx, booking); err != nil {
+		return nil, err
+	}
+	if err := terms.redeem(tx, booking); err != nil {
+		return nil, err
+	}
+	if err := notifyBooking(tx, models.EventBookingCreated, booking, notify.BookingData{}); err != nil {
+		return nil, err
+	}
+	if err := webhookBookings(tx, models.WebhookBookingCreated, *booking); err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// CreateRecurring expands req.Recurrence into concrete bookings. Each
+// occurrence is checked against the provider's calendar; conflicting
+// occurrences either abort the whole series or, with SkipConflicts, are left
+// out and reported back.
+func (s *BookingService) CreateRecurring(userID uuid.UUID, req models.CreateBookingRequest) (*models.RecurringBookingResponse, error) {
+	if err := req.Recurrence.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	terms, err := prepareCreate(tx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	service := terms.service
+
+	loc, err := providerLocation(tx, service.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	occurrences, err := req.Recurrence.Occurrences(req.ScheduledTime, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+	if len(occurrences) == 0 {
+		return nil, fmt.Errorf("%w: no occurrences", ErrInvalidRecurrence)
+	}
+
+	series := models.BookingSeries{
+		ID:         uuid.New(),
+		UserID:     userID,
+		PetID:      req.PetID,
+		ServiceID:  req.ServiceID,
+		ProviderID: service.ProviderID,
+		Rule:       *req.Recurrence,
+		StartTime:  occurrences[0],
+		CreatedAt:  time.Now(),
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO booking_series (id, user_id, pet_id, service_id, provider_id, rrule, start_time, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		series.ID, series.UserID, series.PetID, series.ServiceID, series.ProviderID,
+		series.Rule.String(), series.StartTime, series.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.RecurringBookingResponse{
+		Series:   series,
+		Bookings: []models.Booking{},
+		Skipped:  []models.BookingConflict{},
+	}
+
+	for _, at := range occurrences {
+		staffID, err := checkAvailability(tx, service, req.StaffID, at, nil)
+		if errors.Is(err, ErrSlotUnavailable) || errors.Is(err, ErrStaffUnavailable) {
+			response.Skipped = append(response.Skipped, models.BookingConflict{
+				ScheduledTime: at,
+				Reason:        err.Error(),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		booking := newBooking(userID, req, terms, at)
+		booking.SeriesID = &series.ID
+		booking.StaffID = staffID
+		if err := insertBooking(tx, booking); err != nil {
+			return nil, err
+		}
+		if err := terms.redeem(tx, booking); err != nil {
+			return nil, err
+		}
+		response.Bookings = append(response.Bookings, *booking)
+	}
+
+	if len(response.Skipped) > 0 && (!req.SkipConflicts || len(response.Bookings) == 0) {
+		return nil, &SlotConflictError{Conflicts: response.Skipped}
+	}
+
+	// The series is announced once, by its first booking.
+	if err := notifyBooking(tx, models.EventBookingCreated, &response.Bookings[0],
+		notify.BookingData{Occurrences: len(response.Bookings)}); err != nil {
+		return nil, err
+	}
+	if err := webhookBookings(tx, models.WebhookBookingCreated, response.Bookings...); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// Deposits are taken per occurrence. If any fails, the deposits already
+	// taken are refunded and the whole series is discarded.
+	for i := range response.Bookings {
+		if err := s.payments.CollectDeposit(&response.Bookings[i]); err != nil {
+			ids := make([]uuid.UUID, len(response.Bookings))
+			for j := range response.Bookings {
+				ids[j] = response.Bookings[j].ID
+				if j < i {
+					s.payments.settleAfterCommit(&response.Bookings[j], models.Zero(response.Bookings[j].TotalPrice.Currency))
+				}
+			}
+			discardBookings(s.db, ids...)
+			if _, dbErr := s.db.Exec(`DELETE FROM booking_series WHERE id = $1`, series.ID); dbErr != nil {
+				log.Printf("Failed to discard booking series %s: %v", series.ID, dbErr)
+			}
+			return nil, err
+		}
+	}
+	publishBookingsAfterCommit(s.events, realtime.BookingCreated, response.Bookings...)
+	return response, nil
+}
+
+// bookingTerms collects what prepareCreate decided about a new booking
+// before any occurrence is inserted.
+type bookingTerms struct {
+	service         *models.Service
+	tax             *models.TaxSettings
+	price           models.TaxBreakdown
+	credit          *models.Credit
+	promo           *models.PromoCode
+	discount        models.Money
+	points          int
+	pointsDiscount  models.Money
+	giftCard        *models.GiftCard
+	giftAmount      models.Money
+	depositRequired bool
+	depositAmount   models.Money
+	// nights is the length of a stay, charged at the service's price per
+	// night, and loc the time zone they are counted in.
+	nights int
+	loc    *time.Location
+}
+
+// redeem spends the prepaid credit, promo code use, loyalty points and gift
+// card balance the terms call for on a booking that has just been inserted.
+func (t *bookingTerms) redeem(tx *sql.Tx, booking *models.Booking) error {
+	if t.credit != nil {
+		if err := redeemCredit(tx, t.credit, t.service, booking.ScheduledTime); err != nil {
+			return err
+		}
+	}
+	if t.promo != nil {
+		if err := consumePromoCode(tx, t.promo); err != nil {
+			return err
+		}
+	}
+	if t.points > 0 {
+		if err := spendPoints(tx, booking.UserID, t.points, booking.ID); err != nil {
+			return err
+		}
+	}
+	if t.giftCard != nil {
+		if err := payWithGiftCard(tx, booking, t.giftCard.ID, t.giftAmount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func prepareCreate(tx *sql.Tx, userID uuid.UUID, req models.CreateBookingRequest) (*bookingTerms, error) {
+	if !req.ScheduledTime.After(time.Now()) {
+		return nil, ErrBookingInPast
+	}
+	if err := checkPetOwner(tx, req.PetID, userID); err != nil {
+		return nil, err
+	}
+
+	service, err := getService(tx, req.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	if !service.Available {
+		return nil, ErrServiceNotFound
+	}
+
+	if err := lockProvider(tx, service.ProviderID); err != nil {
+		return nil, err
+	}
+
+	policy, err := getCancellationPolicy(tx, service.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	tax, err := getTaxSettings(tx, service.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stays are priced per night, everything else per visit.
+	listed := service.Price
+	terms := &bookingTerms{service: service, tax: tax}
+	if service.Category.IsStay() {
+		if req.Recurrence != nil || req.CreditID != nil {
+			return nil, ErrStayNotRepeatable
+		}
+		if req.CheckOutTime == nil {
+			return nil, ErrInvalidStay
+		}
+		if terms.loc, err = providerLocation(tx, service.ProviderID); err != nil {
+			return nil, err
+		}
+		if terms.nights = countNights(terms.loc, req.ScheduledTime, *req.CheckOutTime); terms.nights < 1 {
+			return nil, ErrInvalidStay
+		}
+		listed = service.Price.Times(terms.nights)
+	} else if req.CheckOutTime != nil {
+		return nil, ErrInvalidStay
+	}
+	terms.price = tax.Apply(listed)
+
+	// Bookings paid for with a prepaid credit cost nothing further; each
+	// occurrence spends a visit when it is inserted.
+	if req.CreditID != nil {
+		if terms.credit, err = lockCredit(tx, *req.CreditID, userID); err != nil {
+			return nil, err
+		}
+		terms.price = tax.Apply(models.Zero(service.Price.Currency))
+	}
+
+	// Discounts and points come off the listed price before tax. The
+	// reductions recorded are their pre-tax values so invoices can show them
+	// against the subtotal.
+	terms.discount = models.Zero(service.Price.Currency)
+	if req.PromoCode != "" {
+		if req.CreditID != nil {
+			return nil, ErrPromoNotApplicable
+		}
+		if terms.promo, err = applyPromoCode(tx, req.PromoCode, userID, service, listed, req.Recurrence != nil); err != nil {
+			return nil, err
+		}
+		before := terms.price
+		off, err := terms.promo.DiscountOn(listed)
+		if err != nil {
+			return nil, err
+		}
+		if listed, err = listed.Sub(off); err != nil {
+			return nil, err
+		}
+		terms.price = tax.Apply(listed)
+		if terms.discount, err = before.Subtotal.Sub(terms.price.Subtotal); err != nil {
+			return nil, err
+		}
+	}
+
+	terms.pointsDiscount = models.Zero(service.Price.Currency)
+	if req.RedeemPoints > 0 {
+		if req.Recurrence != nil || req.CreditID != nil {
+			return nil, ErrPointsNotApplicable
+		}
+		value := models.PointsValue(req.RedeemPoints, service.Price.Currency)
+		if value.Amount > listed.Amount {
+			return nil, ErrTooManyPoints
+		}
+		before := terms.price
+		terms.points = req.RedeemPoints
+		remaining, err := listed.Sub(value)
+		if err != nil {
+			return nil, err
+		}
+		terms.price = tax.Apply(remaining)
+		if terms.pointsDiscount, err = before.Subtotal.Sub(terms.price.Subtotal); err != nil {
+			return nil, err
+		}
+	}
+
+	if terms.depositRequired, err = checkOwnerReliability(tx, policy, userID); err != nil {
+		return nil, err
+	}
+
+	terms.depositAmount = policy.DepositFor(terms.price.Total, terms.depositRequired)
+
+	// A gift card pays up front, counting towards the deposit first.
+	terms.giftAmount = models.Zero(service.Price.Currency)
+	if req.GiftCardCode != "" {
+		if req.Recurrence != nil || req.CreditID != nil {
+			return nil, ErrGiftCardNotApplicable
+		}
+		if terms.giftCard, err = lockGiftCard(tx, req.GiftCardCode, service.ProviderID); err != nil {
+			return nil, err
+		}
+		if !terms.giftCard.Balance.SameCurrency(terms.price.Total) {
+			return nil, ErrGiftCardNotApplicable
+		}
+		if !terms.giftCard.Balance.IsPositive() {
+			return nil, ErrGiftCardEmpty
+		}
+		if terms.giftAmount, err = terms.giftCard.Balance.Min(terms.price.Total); err != nil {
+			return nil, err
+		}
+		covered, err := terms.depositAmount.Min(terms.giftAmount)
+		if err != nil {
+			return nil, err
+		}
+		if terms.depositAmount, err = terms.depositAmount.Sub(covered); err != nil {
+			return nil, err
+		}
+	}
+
+	if terms.depositAmount.IsPositive() && req.PaymentMethod == "" {
+		return nil, ErrPaymentMethodRequired
+	}
+	return terms, nil
+}
+
+func newBooking(userID uuid.UUID, req models.CreateBookingRequest, terms *bookingTerms, at time.Time) *models.Booking {
+	now := time.Now()
+	booking := &models.Booking{
+		ID:              uuid.New(),
+		UserID:          userID,
+		PetID:           req.PetID,
+		ServiceID:       terms.service.ID,
+		ProviderID:      terms.service.ProviderID,
+		ScheduledTime:   at,
+		Status:          models.StatusPending,
+		Notes:           req.Notes,
+		TotalPrice:      terms.price.Total,
+		Subtotal:        terms.price.Subtotal,
+		TaxAmount:       terms.price.Tax,
+		TaxRate:         terms.tax.Rate,
+		DepositRequired: terms.depositRequired,
+		PaymentStatus:   models.PaymentUnpaid,
+		DepositAmount:   terms.depositAmount,
+		CancellationFee: models.Zero(terms.service.Price.Currency),
+		NoShowFee:       models.Zero(terms.service.Price.Currency),
+		AmountPaid:      models.Zero(terms.service.Price.Currency),
+		PaymentMethod:   req.PaymentMethod,
+		CreditID:        req.CreditID,
+		Discount:        terms.discount,
+		PointsRedeemed:  terms.points,
+		PointsDiscount:  terms.pointsDiscount,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if terms.promo != nil {
+		booking.PromoCodeID = &terms.promo.ID
+	}
+	if terms.nights > 0 {
+		booking.CheckOutTime = req.CheckOutTime
+		booking.Nights = terms.nights
+	}
+	return booking
+}
+
+// GetSeries returns a recurring series and its bookings.
+func (s *BookingService) GetSeries(id, userID uuid.UUID) (*models.RecurringBookingResponse, error) {
+	var series models.BookingSeries
+	var rrule string
+	err := s.db.QueryRow(`
+		SELECT id, user_id, pet_id, service_id, provider_id, rrule, start_time, created_at
+		FROM booking_series WHERE id = $1 AND (user_id = $2 OR provider_id = $2)`, id, userID).Scan(
+		&series.ID, &series.UserID, &series.PetID, &series.ServiceID, &series.ProviderID,
+		&rrule, &series.StartTime, &series.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrBookingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if series.Rule, err = models.ParseRecurrenceRule(rrule); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+bookingColumns+` FROM bookings
+		WHERE series_id = $1 ORDER BY scheduled_time`, series.ID)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := scanBookings(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RecurringBookingResponse{
+		Series:   series,
+		Bookings: bookings,
+		Skipped:  []models.BookingConflict{},
+	}, nil
+}
+
+// scopeTargets returns the booking itself or, for ScopeFollowing on a
+// recurring booking, it together with every later active occurrence in the
+// same series. Rows are locked for the rest of the transaction.
+func scopeTargets(tx *sql.Tx, booking *models.Booking, scope models.RecurrenceScope) ([]models.Booking, error) {
+	if scope != models.ScopeFollowing || booking.SeriesID == nil {
+		return []models.Booking{*booking}, nil
+	}
+
+	rows, err := tx.Query(`
+		SELECT `+bookingColumns+` FROM bookings
+		WHERE series_id = $1 AND scheduled_time >= $2
+			AND status IN ('pending', 'confirmed', 'in_progress')
+		ORDER BY scheduled_time
+		FOR UPDATE`, *booking.SeriesID, booking.ScheduledTime)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookings(rows)
+}
+
+// Update changes a booking, or with ScopeFollowing also every later
+// occurrence of its series. A new scheduled time is applied to following
+// occurrences as the same change of date and local time of day. Only the provider may change status; the
+// remaining balance is charged, an invoice issued and loyalty points awarded
+// when a booking is completed. Reschedules and confirmations are notified
+// once per request, however many occurrences they cover.
+func (s *BookingService) Update(id, userID uuid.UUID, req models.UpdateBookingRequest) (*models.Booking, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	booking, err := s.getBooking(tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID && booking.ProviderID != userID {
+		return nil, ErrBookingNotFound
+	}
+	if req.Status != nil && booking.ProviderID != userID {
+		return nil, ErrForbidden
+	}
+	if req.Status != nil && !booking.Status.CanTransitionTo(*req.Status) {
+		return nil, ErrInvalidTransition
+	}
+	// Cancellations and no-shows carry fees and go through Cancel and
+	// MarkNoShow instead.
+	if req.Status != nil && (*req.Status == models.StatusCancelled || *req.Status == models.StatusNoShow) {
+		return nil, ErrInvalidTransition
+	}
+	if req.StaffID != nil && booking.ProviderID != userID {
+		return nil, ErrForbidden
+	}
+	if (req.ScheduledTime != nil || req.StaffID != nil) && !booking.Status.IsActive() {
+		return nil, ErrBookingInactive
+	}
+	if req.ScheduledTime != nil && !req.ScheduledTime.After(time.Now()) {
+		return nil, ErrBookingInPast
+	}
+
+	if err := lockProvider(tx, booking.ProviderID); err != nil {
+		return nil, err
+	}
+
+	targets, err := scopeTargets(tx, booking, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(targets))
+	for i, target := range targets {
+		ids[i] = target.ID
+	}
+
+	var move shift
+	if req.ScheduledTime != nil {
+		loc, err := providerLocation(tx, booking.ProviderID)
+		if err != nil {
+			return nil, err
+		}
+		move = newShift(loc, booking.ScheduledTime, *req.ScheduledTime)
+	}
+	staffFor := make(map[uuid.UUID]*uuid.UUID, len(targets))
+	for _, target := range targets {
+		staffFor[target.ID] = target.StaffID
+	}
+	if req.ScheduledTime != nil || req.StaffID != nil {
+		service, err := getService(tx, booking.ServiceID)
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range targets {
+			staffID, err := rescheduleStaff(tx, service, target, req.StaffID, move.apply(target.ScheduledTime), ids)
+			if err != nil {
+				return nil, err
+			}
+			staffFor[target.ID] = staffID
+			if target.CheckOutTime != nil && !move.none() {
+				if err := rescheduleStay(tx, service, target, move, ids); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	now := time.Now()
+	var completed []models.Booking
+	confirmed := 0
+	for _, target := range targets {
+		status := target.Status
+		if req.Status != nil && target.Status.CanTransitionTo(*req.Status) {
+			status = *req.Status
+		}
+		notes := target.Notes
+		if req.Notes != nil {
+			notes = *req.Notes
+		}
+
+		checkOut := target.CheckOutTime
+		if checkOut != nil {
+			moved := move.apply(*checkOut)
+			checkOut = &moved
+		}
+
+		_, err := tx.Exec(`
+			UPDATE bookings SET scheduled_time = $1, status = $2, notes = $3, staff_id = $4, check_out_time = $5, updated_at = $6
+			WHERE id = $7`,
+			move.apply(target.ScheduledTime), status, notes, staffFor[target.ID], checkOut, now, target.ID)
+		if err != nil {
+			return nil, err
+		}
+		if status == models.StatusConfirmed && target.Status != status {
+			confirmed++
+		}
+		if err := webhookUpdatedBooking(tx, target, status, notes, move, now); err != nil {
+			return nil, err
+		}
+		if status == models.StatusCompleted && target.Status != status {
+			target.Status = status
+			completed = append(completed, target)
+		}
+	}
+
+	changed := *booking
+	changed.ScheduledTime = move.apply(booking.ScheduledTime)
+	if !move.none() {
+		if err := notifyBooking(tx, models.EventBookingRescheduled, &changed, notify.BookingData{
+			PreviousTime: booking.ScheduledTime,
+			Occurrences:  len(targets),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if confirmed > 0 {
+		if err := notifyBooking(tx, models.EventBookingConfirmed, &changed,
+			notify.BookingData{Occurrences: confirmed}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	for i := range completed {
+		s.payments.settleAfterCommit(&completed[i], completed[i].TotalPrice)
+	}
+	s.invoices.issueAfterCommit(completed)
+	s.loyalty.awardCompletedAfterCommit(completed)
+
+	kind := realtime.BookingUpdated
+	switch {
+	case !move.none():
+		kind = realtime.BookingRescheduled
+	case req.Status != nil:
+		kind = realtime.BookingStatusChanged
+	}
+	s.publishChangedAfterCommit(kind, ids)
+
+	return s.getBooking(s.db, id, false)
+}
+
+// rescheduleStaff checks that target can move to at and returns the groomer
+// who will take it there: the requested one if given, otherwise its current
+// groomer while they are still free, and failing that whoever is.
+func rescheduleStaff(q queryer, service *models.Service, target models.Booking, requested *uuid.UUID, at time.Time, exclude []uuid.UUID) (*uuid.UUID, error) {
+	var checkOut *time.Time
+	if target.CheckOutTime != nil {
+		moved := target.CheckOutTime.Add(at.Sub(target.ScheduledTime))
+		checkOut = &moved
+	}
+	if requested == nil && target.StaffID != nil {
+		staffID, err := checkAvailability(q, service, target.StaffID, at, checkOut, exclude...)
+		if err == nil || !(errors.Is(err, ErrStaffUnavailable) || errors.Is(err, ErrStaffNotFound) || errors.Is(err, ErrStaffCannotPerform)) {
+			return staffID, err
+		}
+	}
+	return checkAvailability(q, service, requested, at, checkOut, exclude...)
+}
+
+// shift is a reschedule applied to a booking and the later occurrences of
+// its series: a number of days and a change in local time of day, in the
+// provider's zone. Moving each by the same duration instead would put
+// occurrences across a daylight saving change an hour out.
+type shift struct {
+	loc   *time.Location
+	days  int
+	clock time.Duration
+}
+
+// newShift returns the shift that moves from to to.
+func newShift(loc *time.Location, from, to time.Time) shift {
+	from, to = from.In(loc), to.In(loc)
+	fromY, fromM, fromD := from.Date()
+	toY, toM, toD := to.Date()
+	days := time.Date(toY, toM, toD, 0, 0, 0, 0, time.UTC).Sub(time.Date(fromY, fromM, fromD, 0, 0, 0, 0, time.UTC))
+	return shift{loc: loc, days: int(days / (24 * time.Hour)), clock: timeOfDay(to) - timeOfDay(from)}
+}
+
+// timeOfDay returns how far t's wall clock is past midnight.
+func timeOfDay(t time.Time) time.Duration {
+	h, m, s := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second + time.Duration(t.Nanosecond())
+}
+
+// none reports whether the shift leaves bookings where they are.
+func (s shift) none() bool {
+	return s.days == 0 && s.clock == 0
+}
+
+// apply returns t moved by the shift, keeping to the provider's wall clock.
+func (s shift) apply(t time.Time) time.Time {
+	if s.none() {
+		return t
+	}
+	local := t.In(s.loc)
+	y, m, d := local.Date()
+	return time.Date(y, m, d+s.days, 0, 0, 0, int(timeOfDay(local)+s.clock), s.loc)
+}
+
+// rescheduleStay checks that a stay can move by the shift: it must cover as
+// many nights as before, and its resources must be free on each of them.
+func rescheduleStay(q queryer, service *models.Service, target models.Booking, move shift, exclude []uuid.UUID) error {
+	loc, err := providerLocation(q, target.ProviderID)
+	if err != nil {
+		return err
+	}
+	checkIn, checkOut := move.apply(target.ScheduledTime), move.apply(*target.CheckOutTime)
+	if countNights(loc, checkIn, checkOut) != target.Nights {
+		return ErrStayLengthChanged
+	}
+	excluded := make([]string, len(exclude))
+	for i, id := range exclude {
+		excluded[i] = id.String()
+	}
+	return checkNights(q, service, loc, checkIn, checkOut, excluded)
+}
+
+// webhookUpdatedBooking queues the webhooks for one booking changed by an
+// update: a reschedule, and the status it moved to, if either changed.
+func webhookUpdatedBooking(q queryer, target models.Booking, status models.BookingStatus, notes string, move shift, now time.Time) error {
+	previous := target.Status
+	target.ScheduledTime = move.apply(target.ScheduledTime)
+	if target.CheckOutTime != nil {
+		checkOut := move.apply(*target.CheckOutTime)
+		target.CheckOutTime = &checkOut
+	}
+	target.Status = status
+	target.Notes = notes
+	target.UpdatedAt = now
+
+	if !move.none() {
+		if err := webhookBookings(q, models.WebhookBookingRescheduled, target); err != nil {
+			return err
+		}
+	}
+	if status == previous {
+		return nil
+	}
+	events := map[models.BookingStatus]models.WebhookEvent{
+		models.StatusConfirmed:  models.WebhookBookingConfirmed,
+		models.StatusInProgress: models.WebhookBookingStarted,
+		models.StatusCompleted:  models.WebhookBookingCompleted,
+	}
+	if event, ok := events[status]; ok {
+		return webhookBookings(q, event, target)
+	}
+	return nil
+}
+
+// publishChangedAfterCommit reloads the bookings an update touched and
+// publishes their new state.
+func (s *BookingService) publishChangedAfterCommit(kind string, ids []uuid.UUID) {
+	changed := make([]models.Booking, 0, len(ids))
+	for _, id := range ids {
+		booking, err := s.getBooking(s.db, id, false)
+		if err != nil {
+			log.Printf("Failed to load booking %s for %s event: %v", id, kind, err)
+			continue
+		}
+		changed = append(changed, *booking)
+	}
+	publishBookingsAfterCommit(s.events, kind, changed...)
+}
+
+// Cancel cancels a booking, or with ScopeFollowing also every later active
+// occurrence of its series, and returns the bookings that were cancelled.
+// When the owner cancels inside the provider's fee window a late-cancel fee
+// is recorded on each affected booking, and visits paid for with a prepaid
+// credit or loyalty points are forfeited; otherwise they are returned.
+// Each freed future slot is offered to the provider's waitlist, and both
+// parties are notified.
+func (s *BookingService) Cancel(id, userID uuid.UUID, req models.CancelBookingRequest) (*models.CancellationResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	booking, err := s.getBooking(tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID && booking.ProviderID != userID {
+		return nil, ErrBookingNotFound
+	}
+	if !booking.Status.CanTransitionTo(models.StatusCancelled) {
+		return nil, ErrInvalidTransition
+	}
+
+	policy, err := getCancellationPolicy(tx, booking.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := scopeTargets(tx, booking, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := &models.CancellationResult{
+		Bookings: []models.Booking{},
+		TotalFee: models.Zero(booking.TotalPrice.Currency),
+	}
+	for _, target := range targets {
+		if !target.Status.CanTransitionTo(models.StatusCancelled) {
+			continue
+		}
+
+		fee := models.Zero(target.TotalPrice.Currency)
+		late := false
+		if userID == target.UserID {
+			fee = policy.CancellationFee(target.TotalPrice, target.ScheduledTime, now)
+			late = policy.ChargesLateFee(target.ScheduledTime, now)
+		}
+		if target.CreditID != nil && !late {
+			if err := restoreCredit(tx, *target.CreditID); err != nil {
+				return nil, err
+			}
+		}
+		if !late {
+			if err := refundBookingPoints(tx, &target); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err := tx.Exec(`
+			UPDATE bookings
+			SET status = $1, cancellation_reason = $2, cancelled_by = $3, cancelled_at = $4,
+				cancellation_fee = $5, updated_at = $4
+			WHERE id = $6`,
+			models.StatusCancelled, req.Reason, userID, now, fee.Amount, target.ID)
+		if err != nil {
+			return nil, err
+		}
+		target.Status = models.StatusCancelled
+		target.CancellationReason = req.Reason
+		target.CancelledBy = &userID
+		target.CancelledAt = &now
+		target.CancellationFee = fee
+		target.UpdatedAt = now
+		result.Bookings = append(result.Bookings, target)
+		if result.TotalFee, err = result.TotalFee.Add(fee); err != nil {
+			return nil, err
+		}
+
+		if target.ScheduledTime.After(now) {
+			if _, err := offerSlot(tx, target.ProviderID, target.ScheduledTime); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := webhookBookings(tx, models.WebhookBookingCancelled, result.Bookings...); err != nil {
+		return nil, err
+	}
+	if len(result.Bookings) > 0 {
+		if err := notifyBooking(tx, models.EventBookingCancelled, &result.Bookings[0], notify.BookingData{
+			Occurrences: len(result.Bookings),
+			Reason:      req.Reason,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	for i := range result.Bookings {
+		s.payments.settleAfterCommit(&result.Bookings[i], result.Bookings[i].CancellationFee)
+	}
+	s.invoices.issueAfterCommit(result.Bookings)
+	publishBookingsAfterCommit(s.events, realtime.BookingStatusChanged, result.Bookings...)
+	return result, nil
+}
+
+// ConfirmAttendance records that the owner will turn up to an upcoming
+// booking. Confirming again keeps the first time.
+func (s *BookingService) ConfirmAttendance(id, ownerID uuid.UUID) (*models.Booking, error) {
+	booking, err := s.getBooking(s.db, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != ownerID {
+		return nil, ErrBookingNotFound
+	}
+	if !booking.Status.IsActive() {
+		return nil, ErrBookingInactive
+	}
+
+	if _, err := s.db.Exec(`
+		UPDATE bookings SET owner_confirmed_at = COALESCE(owner_confirmed_at, $1)
+		WHERE id = $2`, time.Now(), id); err != nil {
+		return nil, err
+	}
+	if booking, err = s.getBooking(s.db, id, false); err != nil {
+		return nil, err
+	}
+	publishBookingsAfterCommit(s.events, realtime.BookingUpdated, *booking)
+	return booking, nil
+}
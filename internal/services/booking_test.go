@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestShiftKeepsLocalTimeAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip("time zone data unavailable:", err)
+	}
+	// A weekly series at 10:00 starting a week before the clocks go back.
+	start := time.Date(2024, 10, 19, 10, 0, 0, 0, loc)
+	occurrences, err := models.RecurrenceRule{IntervalWeeks: 1, Count: 3}.Occurrences(start, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first is moved to 14:30 the next day, sent in UTC by the client.
+	move := newShift(loc, occurrences[0], time.Date(2024, 10, 20, 13, 30, 0, 0, time.UTC))
+	for i, at := range occurrences {
+		got := move.apply(at).In(loc)
+		want := time.Date(2024, 10, 20+7*i, 14, 30, 0, 0, loc)
+		if !got.Equal(want) {
+			t.Errorf("occurrence %d moved to %s, want %s", i, got, want)
+		}
+	}
+
+	// A stay's check-out moves with it and keeps its own time of day.
+	checkOut := time.Date(2024, 10, 28, 9, 0, 0, 0, loc)
+	if got, want := move.apply(checkOut), time.Date(2024, 10, 29, 13, 30, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("check-out moved to %s, want %s", got.In(loc), want)
+	}
+}
+
+func TestShiftBackwardAndNone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data unavailable:", err)
+	}
+	// Moving 09:00 on 3 November, after the clocks went back, to 17:00
+	// the day before keeps later occurrences at 17:00 too.
+	move := newShift(loc, time.Date(2026, 11, 3, 9, 0, 0, 0, loc), time.Date(2026, 11, 2, 17, 0, 0, 0, loc))
+	if move.days != -1 || move.clock != 8*time.Hour {
+		t.Errorf("shift = %d days %s, want -1 days 8h", move.days, move.clock)
+	}
+	if got, want := move.apply(time.Date(2026, 10, 27, 9, 0, 0, 0, loc)), time.Date(2026, 10, 26, 17, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("moved to %s, want %s", got.In(loc), want)
+	}
+
+	at := time.Date(2026, 11, 3, 9, 0, 0, 0, loc)
+	same := newShift(loc, at, at.UTC())
+	if !same.none() || !same.apply(at).Equal(at) {
+		t.Errorf("an unchanged time gave shift %+v", same)
+	}
+	if !(shift{}).none() || !(shift{}).apply(at).Equal(at) {
+		t.Error("the zero shift moved a booking")
+	}
+}
+
+func TestSittingStayAtSalon(t *testing.T) {
+	db := openTestDB(t)
+	booking := seedBooking(t, db, models.ServiceSitting, models.NewMoney(6000, "USD"), models.Zero("USD"), "card")
+	staffService := NewStaffService(db)
+	if _, err := staffService.SaveOrganization(booking.ProviderID, models.SaveOrganizationRequest{Name: "Salon", TimeZone: "UTC"}); err != nil {
+		t.Fatal(err)
+	}
+	var week []models.StaffShift
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		week = append(week, models.StaffShift{Weekday: day, Start: "09:00", End: "17:00"})
+	}
+	sitter, err := staffService.AddStaff(booking.ProviderID, models.CreateStaffRequest{
+		Name: "Sam", ServiceIDs: []uuid.UUID{booking.ServiceID}, Schedule: week,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := getService(db, booking.ServiceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two nights checking in at 10:00, long past the end of every shift.
+	day := time.Now().UTC().AddDate(0, 0, 7)
+	start := time.Date(day.Year(), day.Month(), day.Day(), 10, 0, 0, 0, time.UTC)
+	checkOut := start.AddDate(0, 0, 2)
+	staffID, err := checkAvailability(db, service, nil, start, &checkOut, booking.ID)
+	if err != nil {
+		t.Fatalf("a two-night stay with a rostered sitter: %v", err)
+	}
+	if staffID == nil || *staffID != sitter.ID {
+		t.Fatalf("assigned %v, want the sitter %s", staffID, sitter.ID)
+	}
+	if _, err := db.Exec(`UPDATE bookings SET scheduled_time = $2, check_out_time = $3, staff_id = $4 WHERE id = $1`,
+		booking.ID, start, checkOut, sitter.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The sitter is away until check-out, even during their shifts.
+	middle := start.AddDate(0, 0, 1)
+	later := middle.AddDate(0, 0, 2)
+	if _, err := checkAvailability(db, service, nil, middle, &later); !errors.Is(err, ErrSlotUnavailable) {
+		t.Errorf("an overlapping stay: %v, want ErrSlotUnavailable", err)
+	}
+	if _, err := checkAvailability(db, service, &sitter.ID, middle, &later); !errors.Is(err, ErrStaffUnavailable) {
+		t.Errorf("asking for the busy sitter: %v, want ErrStaffUnavailable", err)
+	}
+	// A check-in outside the shifts still needs a sitter on duty.
+	evening := checkOut.Add(9 * time.Hour)
+	eveningOut := evening.AddDate(0, 0, 1)
+	if _, err := checkAvailability(db, service, nil, evening, &eveningOut); !errors.Is(err, ErrSlotUnavailable) {
+		t.Errorf("a check-in after hours: %v, want ErrSlotUnavailable", err)
+	}
+}
+
+func TestPromoMinimumSpendCountsEveryNight(t *testing.T) {
+	db := openTestDB(t)
+	booking := seedBooking(t, db, models.ServiceBoarding, models.NewMoney(4000, "USD"), models.Zero("USD"), "card")
+	if _, err := db.Exec(`
+		INSERT INTO promo_codes (id, provider_id, code, kind, percent, min_spend, currency)
+		VALUES ($1, $2, 'LONGSTAY', $3, 10, 10000, 'USD')`,
+		uuid.New(), booking.ProviderID, models.DiscountPercentage); err != nil {
+		t.Fatal(err)
+	}
+	service, err := getService(db, booking.ServiceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One night at 40.00 is short of the 100.00 minimum; three nights are not.
+	if _, err := applyPromoCode(db, "longstay", booking.UserID, service, service.Price, false); !errors.Is(err, ErrPromoNotApplicable) {
+		t.Errorf("one night: %v, want ErrPromoNotApplicable", err)
+	}
+	if _, err := applyPromoCode(db, "longstay", booking.UserID, service, service.Price.Times(3), false); err != nil {
+		t.Errorf("three nights: %v", err)
+	}
+}
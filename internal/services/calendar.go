@@ -0,0 +1,266 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pet-grooming-app/internal/ical"
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+// bookingUIDSuffix ends the UID of every event made from a booking, which
+// tells them apart from other events when reading calendars back.
+const bookingUIDSuffix = "@pet-grooming-app"
+
+// calendarFeedHistory is how far back a feed lists bookings. Older ones drop
+// out of subscribed calendars, which keeps feeds small.
+const calendarFeedHistory = 90 * 24 * time.Hour
+
+// CalendarService publishes bookings as iCalendar data: a subscribable feed
+// per user and single-booking downloads.
+type CalendarService struct {
+	db      *sql.DB
+	baseURL string
+}
+
+// NewCalendarService points feed URLs at baseURL, the server's public
+// address.
+func NewCalendarService(db *sql.DB, baseURL string) *CalendarService {
+	return &CalendarService{db: db, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func newFeedToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *CalendarService) feed(token string, createdAt time.Time) *models.CalendarFeed {
+	return &models.CalendarFeed{URL: s.baseURL + "/api/v1/calendar/" + token + ".ics", CreatedAt: createdAt}
+}
+
+// Feed returns the user's feed, creating it the first time.
+func (s *CalendarService) Feed(userID uuid.UUID) (*models.CalendarFeed, error) {
+	token, err := newFeedToken()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Exec(`
+		INSERT INTO calendar_feeds (user_id, token, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO NOTHING`, userID, token, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var createdAt time.Time
+	if err := s.db.QueryRow(`SELECT token, created_at FROM calendar_feeds WHERE user_id = $1`, userID).Scan(
+		&token, &createdAt); err != nil {
+		return nil, err
+	}
+	return s.feed(token, createdAt), nil
+}
+
+// ResetFeed replaces the user's feed URL, cutting off anyone who had the old
+// one.
+func (s *CalendarService) ResetFeed(userID uuid.UUID) (*models.CalendarFeed, error) {
+	token, err := newFeedToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if _, err := s.db.Exec(`
+		INSERT INTO calendar_feeds (user_id, token, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET token = EXCLUDED.token, created_at = EXCLUDED.created_at`,
+		userID, token, now); err != nil {
+		return nil, err
+	}
+	return s.feed(token, now), nil
+}
+
+// FeedCalendar renders the feed behind token: every booking the user is the
+// owner or provider of, from the last 90 days on. Cancelled bookings stay in
+// the feed marked as cancelled, so subscribed calendars remove them.
+func (s *CalendarService) FeedCalendar(token string) (*ical.Calendar, error) {
+	var userID uuid.UUID
+	err := s.db.QueryRow(`SELECT user_id FROM calendar_feeds WHERE token = $1`, token).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return nil, ErrCalendarFeedNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+bookingColumns+` FROM bookings
+		WHERE (user_id = $1 OR provider_id = $1) AND scheduled_time >= $2
+		ORDER BY scheduled_time`, userID, time.Now().Add(-calendarFeedHistory))
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := scanBookings(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := calendarEvents(s.db, bookings)
+	if err != nil {
+		return nil, err
+	}
+	return &ical.Calendar{Name: "Grooming appointments", Events: events}, nil
+}
+
+// BookingCalendar renders a single booking for download.
+func (s *CalendarService) BookingCalendar(bookingID, userID uuid.UUID) (*ical.Calendar, error) {
+	booking, err := getBookingRow(s.db, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID && booking.ProviderID != userID {
+		return nil, ErrBookingNotFound
+	}
+
+	events, err := calendarEvents(s.db, []models.Booking{*booking})
+	if err != nil {
+		return nil, err
+	}
+	return &ical.Calendar{Events: events}, nil
+}
+
+// calendarEvents turns bookings into events, looking up the pets, services
+// and people they refer to in one query each.
+func calendarEvents(q queryer, bookings []models.Booking) ([]ical.Event, error) {
+	var petIDs, serviceIDs, userIDs, staffIDs []string
+	for _, b := range bookings {
+		petIDs = append(petIDs, b.PetID.String())
+		serviceIDs = append(serviceIDs, b.ServiceID.String())
+		userIDs = append(userIDs, b.UserID.String(), b.ProviderID.String())
+		if b.StaffID != nil {
+			staffIDs = append(staffIDs, b.StaffID.String())
+		}
+	}
+
+	pets := make(map[uuid.UUID]string)
+	if err := lookupNames(q, `SELECT id, name FROM pets WHERE id = ANY($1::uuid[])`, petIDs, func(rows *sql.Rows) error {
+		var id uuid.UUID
+		var name string
+		err := rows.Scan(&id, &name)
+		pets[id] = name
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	type serviceInfo struct {
+		name     string
+		duration int
+	}
+	services := make(map[uuid.UUID]serviceInfo)
+	if err := lookupNames(q, `SELECT id, name, duration_minutes FROM services WHERE id = ANY($1::uuid[])`, serviceIDs, func(rows *sql.Rows) error {
+		var id uuid.UUID
+		var info serviceInfo
+		err := rows.Scan(&id, &info.name, &info.duration)
+		services[id] = info
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	type userInfo struct {
+		name    string
+		address string
+	}
+	users := make(map[uuid.UUID]userInfo)
+	if err := lookupNames(q, `SELECT id, first_name, last_name, COALESCE(address, '') FROM users WHERE id = ANY($1::uuid[])`, userIDs, func(rows *sql.Rows) error {
+		var id uuid.UUID
+		var firstName, lastName, address string
+		err := rows.Scan(&id, &firstName, &lastName, &address)
+		users[id] = userInfo{name: strings.TrimSpace(firstName + " " + lastName), address: address}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	staff := make(map[uuid.UUID]string)
+	if err := lookupNames(q, `SELECT id, name FROM staff_members WHERE id = ANY($1::uuid[])`, staffIDs, func(rows *sql.Rows) error {
+		var id uuid.UUID
+		var name string
+		err := rows.Scan(&id, &name)
+		staff[id] = name
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	events := make([]ical.Event, len(bookings))
+	for i, b := range bookings {
+		service := services[b.ServiceID]
+		status := ical.StatusConfirmed
+		switch b.Status {
+		case models.StatusPending:
+			status = ical.StatusTentative
+		case models.StatusCancelled:
+			status = ical.StatusCancelled
+		}
+
+		groomer := users[b.ProviderID].name
+		if b.StaffID != nil {
+			groomer = staff[*b.StaffID]
+		}
+		description := fmt.Sprintf("Owner: %s\nGroomer: %s\nStatus: %s",
+			users[b.UserID].name, groomer, b.Status)
+		if b.Notes != "" {
+			description += "\nNotes: " + b.Notes
+		}
+
+		end := b.ScheduledTime.Add(time.Duration(service.duration) * time.Minute)
+		if b.CheckOutTime != nil {
+			end = *b.CheckOutTime
+		}
+
+		events[i] = ical.Event{
+			UID: b.ID.String() + bookingUIDSuffix,
+			// Each change to a booking moves updated_at forward, so the
+			// seconds since it was created make a growing sequence.
+			Sequence:    int(b.UpdatedAt.Sub(b.CreatedAt) / time.Second),
+			Start:       b.ScheduledTime,
+			End:         end,
+			Summary:     fmt.Sprintf("%s for %s", service.name, pets[b.PetID]),
+			Description: description,
+			Location:    users[b.ProviderID].address,
+			Status:      status,
+			Created:     b.CreatedAt,
+			Updated:     b.UpdatedAt,
+		}
+	}
+	return events, nil
+}
+
+// lookupNames runs query with ids as its only argument and calls scan for
+// each row.
+func lookupNames(q queryer, query string, ids []string, scan func(*sql.Rows) error) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	rows, err := q.Query(query, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
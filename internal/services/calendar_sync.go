@@ -0,0 +1,382 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"pet-grooming-app/internal/caldav"
+	"pet-grooming-app/internal/ical"
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/safehttp"
+	"pet-grooming-app/internal/secrets"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCalendarNotConnected = errors.New("no calendar is connected")
+	ErrCalendarURLNotPublic = errors.New("calendar URL must point to a public address")
+)
+
+const (
+	// calendarSyncHorizon is how far ahead busy time is imported.
+	calendarSyncHorizon = 90 * 24 * time.Hour
+	// calendarSyncInterval is how often each connected calendar is synced.
+	calendarSyncInterval = 15 * time.Minute
+	// calendarSyncBatchSize caps how many calendars one pass syncs.
+	calendarSyncBatchSize = 20
+	// busySourceCalDAV marks blocks imported from a CalDAV calendar.
+	busySourceCalDAV = "caldav"
+)
+
+const calendarConnectionColumns = `provider_id, calendar_url, username, password, last_synced_at, last_error, next_sync_at, created_at`
+
+func scanCalendarConnection(row rowScanner) (*models.CalendarConnection, error) {
+	var conn models.CalendarConnection
+	var lastSyncedAt sql.NullTime
+	err := row.Scan(&conn.ProviderID, &conn.CalendarURL, &conn.Username, &conn.Password,
+		&lastSyncedAt, &conn.LastError, &conn.NextSyncAt, &conn.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastSyncedAt.Valid {
+		conn.LastSyncedAt = &lastSyncedAt.Time
+	}
+	return &conn, nil
+}
+
+// CalendarSyncService keeps providers' external CalDAV calendars and their
+// bookings in step: busy time is imported into availability, and bookings
+// are written out as events.
+type CalendarSyncService struct {
+	db *sql.DB
+	// box seals calendar passwords before they are stored.
+	box *secrets.Box
+	// client is nil when private addresses are allowed, so caldav uses a
+	// plain client that can reach a CalDAV server on the local network.
+	client *http.Client
+}
+
+// NewCalendarSyncService returns the service. Calendars on loopback and
+// private addresses are refused unless allowPrivate is set, which is meant
+// for development against a local CalDAV server.
+func NewCalendarSyncService(db *sql.DB, box *secrets.Box, allowPrivate bool) *CalendarSyncService {
+	s := &CalendarSyncService{db: db, box: box}
+	if !allowPrivate {
+		s.client = safehttp.NewClient(caldav.RequestTimeout)
+	}
+	return s
+}
+
+// Get returns the provider's calendar connection.
+func (s *CalendarSyncService) Get(providerID uuid.UUID) (*models.CalendarConnection, error) {
+	conn, err := scanCalendarConnection(s.db.QueryRow(`
+		SELECT `+calendarConnectionColumns+` FROM calendar_connections WHERE provider_id = $1`, providerID))
+	if err == sql.ErrNoRows {
+		return nil, ErrCalendarNotConnected
+	}
+	return conn, err
+}
+
+// Connect links the provider's calendar, replacing any earlier one, and
+// syncs it straight away. The calendar is checked first, so bad URLs and
+// credentials are reported here rather than on the next sync.
+func (s *CalendarSyncService) Connect(ctx context.Context, providerID uuid.UUID, req models.ConnectCalendarRequest) (*models.CalendarConnection, error) {
+	if err := requireProvider(s.db, providerID); err != nil {
+		return nil, err
+	}
+	client, err := caldav.NewClient(s.client, req.CalendarURL, req.Username, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	if s.client != nil {
+		if err := safehttp.CheckURL(ctx, req.CalendarURL); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCalendarURLNotPublic, err)
+		}
+	}
+	if err := client.Check(ctx); err != nil {
+		return nil, err
+	}
+	password, err := s.box.Seal(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bookings already written to a previous calendar are written again.
+	if err := s.Disconnect(providerID); err != nil && !errors.Is(err, ErrCalendarNotConnected) {
+		return nil, err
+	}
+	now := time.Now()
+	conn := &models.CalendarConnection{
+		ProviderID:  providerID,
+		CalendarURL: req.CalendarURL,
+		Username:    req.Username,
+		Password:    password,
+		NextSyncAt:  now.Add(calendarSyncInterval),
+		CreatedAt:   now,
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO calendar_connections (`+calendarConnectionColumns+`)
+		VALUES ($1, $2, $3, $4, NULL, '', $5, $6)`,
+		conn.ProviderID, conn.CalendarURL, conn.Username, conn.Password, conn.NextSyncAt, conn.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	s.sync(ctx, conn)
+	return conn, nil
+}
+
+// Disconnect unlinks the provider's calendar and drops the busy time
+// imported from it. Events already written to the calendar are left there.
+func (s *CalendarSyncService) Disconnect(providerID uuid.UUID) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM calendar_connections WHERE provider_id = $1`, providerID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrCalendarNotConnected
+	}
+	if _, err := tx.Exec(`DELETE FROM busy_blocks WHERE provider_id = $1 AND source = $2`, providerID, busySourceCalDAV); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM calendar_pushed_bookings
+		WHERE booking_id IN (SELECT id FROM bookings WHERE provider_id = $1)`, providerID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SyncNow syncs the provider's calendar immediately and returns the updated
+// connection, with any failure in LastError.
+func (s *CalendarSyncService) SyncNow(ctx context.Context, providerID uuid.UUID) (*models.CalendarConnection, error) {
+	conn, err := s.Get(providerID)
+	if err != nil {
+		return nil, err
+	}
+	s.sync(ctx, conn)
+	return conn, nil
+}
+
+// BusyBlocks returns the provider's busy time from now on.
+func (s *CalendarSyncService) BusyBlocks(providerID uuid.UUID) ([]models.BusyBlock, error) {
+	rows, err := s.db.Query(`
+		SELECT id, provider_id, start_time, end_time, source, imported_at FROM busy_blocks
+		WHERE provider_id = $1 AND end_time > NOW()
+		ORDER BY start_time`, providerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := []models.BusyBlock{}
+	for rows.Next() {
+		var b models.BusyBlock
+		if err := rows.Scan(&b.ID, &b.ProviderID, &b.StartTime, &b.EndTime, &b.Source, &b.ImportedAt); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
+// Run syncs due calendars every interval until ctx is cancelled.
+func (s *CalendarSyncService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.SyncDue(ctx); err != nil {
+			log.Printf("Failed to sync calendars: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncDue claims the calendars due a sync and syncs them, returning how many
+// synced cleanly. Claiming moves next_sync_at on, so instances share the
+// work as they do the notification outbox.
+func (s *CalendarSyncService) SyncDue(ctx context.Context) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE calendar_connections SET next_sync_at = $1
+		WHERE provider_id IN (
+			SELECT provider_id FROM calendar_connections
+			WHERE next_sync_at <= NOW()
+			ORDER BY next_sync_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+calendarConnectionColumns, time.Now().Add(calendarSyncInterval), calendarSyncBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var claimed []*models.CalendarConnection
+	for rows.Next() {
+		conn, err := scanCalendarConnection(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		claimed = append(claimed, conn)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	synced := 0
+	for _, conn := range claimed {
+		if s.sync(ctx, conn) {
+			synced++
+		}
+	}
+	return synced, nil
+}
+
+// sync imports and pushes one calendar and records the outcome on conn and
+// its row. Failures are kept for the provider to see rather than returned.
+func (s *CalendarSyncService) sync(ctx context.Context, conn *models.CalendarConnection) bool {
+	syncErr := s.syncCalendar(ctx, conn)
+
+	now := time.Now()
+	conn.LastError = ""
+	if syncErr != nil {
+		conn.LastError = syncErr.Error()
+	} else {
+		conn.LastSyncedAt = &now
+	}
+	if _, err := s.db.Exec(`
+		UPDATE calendar_connections SET last_synced_at = $1, last_error = $2 WHERE provider_id = $3`,
+		conn.LastSyncedAt, conn.LastError, conn.ProviderID); err != nil {
+		log.Printf("Failed to record calendar sync for provider %s: %v", conn.ProviderID, err)
+	}
+	return syncErr == nil
+}
+
+func (s *CalendarSyncService) syncCalendar(ctx context.Context, conn *models.CalendarConnection) error {
+	password, err := s.box.Open(conn.Password)
+	if err != nil {
+		return err
+	}
+	if !secrets.IsSealed(conn.Password) {
+		s.resealPassword(conn, password)
+	}
+	client, err := caldav.NewClient(s.client, conn.CalendarURL, conn.Username, password)
+	if err != nil {
+		return err
+	}
+	if err := s.importBusy(ctx, client, conn.ProviderID); err != nil {
+		return err
+	}
+	return s.pushBookings(ctx, client, conn.ProviderID)
+}
+
+// resealPassword encrypts a password stored before passwords were sealed.
+// Failing leaves the plaintext row to be retried on the next sync.
+func (s *CalendarSyncService) resealPassword(conn *models.CalendarConnection, password string) {
+	sealed, err := s.box.Seal(password)
+	if err == nil {
+		_, err = s.db.Exec(`
+			UPDATE calendar_connections SET password = $1 WHERE provider_id = $2 AND password = $3`,
+			sealed, conn.ProviderID, conn.Password)
+	}
+	if err != nil {
+		log.Printf("Failed to encrypt calendar password for provider %s: %v", conn.ProviderID, err)
+		return
+	}
+	conn.Password = sealed
+}
+
+// importBusy replaces the provider's imported busy time with the opaque,
+// uncancelled events in their calendar over the sync horizon. Events written
+// from bookings are skipped; the bookings themselves already hold the time.
+func (s *CalendarSyncService) importBusy(ctx context.Context, client *caldav.Client, providerID uuid.UUID) error {
+	now := time.Now()
+	events, err := client.Events(ctx, now, now.Add(calendarSyncHorizon))
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM busy_blocks WHERE provider_id = $1 AND source = $2`, providerID, busySourceCalDAV); err != nil {
+		return err
+	}
+	for _, e := range events {
+		if e.Transparent || e.Status == ical.StatusCancelled || strings.HasSuffix(e.UID, bookingUIDSuffix) || !e.End.After(e.Start) {
+			continue
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO busy_blocks (id, provider_id, start_time, end_time, source, external_uid, imported_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			uuid.New(), providerID, e.Start, e.End, busySourceCalDAV, e.UID, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// pushBookings writes the provider's upcoming bookings that changed since
+// they were last written. Cancelled bookings and no-shows are removed from
+// the calendar.
+func (s *CalendarSyncService) pushBookings(ctx context.Context, client *caldav.Client, providerID uuid.UUID) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+bookingColumns+` FROM bookings
+		WHERE provider_id = $1 AND scheduled_time >= $2
+			AND NOT EXISTS (
+				SELECT 1 FROM calendar_pushed_bookings p
+				WHERE p.booking_id = bookings.id AND p.pushed_at >= bookings.updated_at
+			)
+		ORDER BY scheduled_time`, providerID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+	bookings, err := scanBookings(rows)
+	if err != nil {
+		return err
+	}
+	events, err := calendarEvents(s.db, bookings)
+	if err != nil {
+		return err
+	}
+
+	for i, booking := range bookings {
+		name := booking.ID.String()
+		if booking.Status == models.StatusCancelled || booking.Status == models.StatusNoShow {
+			err = client.Delete(ctx, name)
+		} else {
+			err = client.Put(ctx, name, &ical.Calendar{Events: events[i : i+1]})
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := s.db.Exec(`
+			INSERT INTO calendar_pushed_bookings (booking_id, pushed_at) VALUES ($1, $2)
+			ON CONFLICT (booking_id) DO UPDATE SET pushed_at = EXCLUDED.pushed_at`,
+			booking.ID, booking.UpdatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
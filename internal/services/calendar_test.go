@@ -0,0 +1,45 @@
+package services
+
+import (
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"pet-grooming-app/internal/ical"
+	"pet-grooming-app/internal/models"
+)
+
+func TestFeedKeepsCancelledBookings(t *testing.T) {
+	db := openTestDB(t)
+	s := NewCalendarService(db, "https://grooming.example.com/")
+	booking := seedBooking(t, db, models.ServiceGrooming, models.NewMoney(5000, "USD"), models.Zero("USD"), "card")
+	if _, err := db.Exec(`UPDATE bookings SET status = 'cancelled' WHERE id = $1`, booking.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	feed, err := s.Feed(booking.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := strings.TrimSuffix(path.Base(feed.URL), ".ics")
+	cal, err := s.FeedCalendar(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cal.Events) != 1 {
+		t.Fatalf("feed has %d events, want 1", len(cal.Events))
+	}
+	e := cal.Events[0]
+	if e.UID != booking.ID.String()+bookingUIDSuffix || e.Status != ical.StatusCancelled || e.Summary != "Test service for Rex" {
+		t.Errorf("event = %+v", e)
+	}
+	// The database keeps microseconds.
+	if d := e.Start.Sub(booking.ScheduledTime); d < -time.Microsecond || d > time.Microsecond || e.End.Sub(e.Start) != time.Hour {
+		t.Errorf("event runs %s to %s", e.Start, e.End)
+	}
+
+	if _, err := s.FeedCalendar("not-a-token"); err != ErrCalendarFeedNotFound {
+		t.Errorf("unknown token: %v, want ErrCalendarFeedNotFound", err)
+	}
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/realtime"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotProvider    = errors.New("only service providers can perform this action")
+	ErrOwnerBlocked   = errors.New("this provider is not accepting bookings from owners with repeated no-shows")
+	ErrNoShowTooEarly = errors.New("a booking can only be marked as a no-show after its scheduled time")
+)
+
+// requireProvider returns ErrNotProvider unless userID is a provider or admin.
+func requireProvider(q queryer, userID uuid.UUID) error {
+	var role models.UserRole
+	err := q.QueryRow(`SELECT role FROM users WHERE id = $1`, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return ErrNotProvider
+	}
+	if err != nil {
+		return err
+	}
+	if role != models.RoleProvider && role != models.RoleAdmin {
+		return ErrNotProvider
+	}
+	return nil
+}
+
+// getCancellationPolicy returns the provider's policy, or the default policy
+// when none has been configured.
+func getCancellationPolicy(q queryer, providerID uuid.UUID) (*models.CancellationPolicy, error) {
+	policy := models.CancellationPolicy{
+		ProviderID:            providerID,
+		FreeCancellationHours: models.DefaultFreeCancellationHours,
+		NoShowAction:          models.NoShowActionNone,
+	}
+
+	err := q.QueryRow(`
+		SELECT free_cancellation_hours, late_cancel_fee_percent, no_show_fee_percent,
+			no_show_threshold, no_show_action, deposit_percent, updated_at
+		FROM cancellation_policies WHERE provider_id = $1`, providerID).Scan(
+		&policy.FreeCancellationHours, &policy.LateCancelFeePercent, &policy.NoShowFeePercent,
+		&policy.NoShowThreshold, &policy.NoShowAction, &policy.DepositPercent, &policy.UpdatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// GetCancellationPolicy returns the cancellation policy of a provider.
+func (s *BookingService) GetCancellationPolicy(providerID uuid.UUID) (*models.CancellationPolicy, error) {
+	if err := requireProvider(s.db, providerID); err != nil {
+		return nil, err
+	}
+	return getCancellationPolicy(s.db, providerID)
+}
+
+// UpdateCancellationPolicy creates or updates a provider's cancellation
+// policy; fields left out of req keep their current values.
+func (s *BookingService) UpdateCancellationPolicy(providerID uuid.UUID, req models.UpdateCancellationPolicyRequest) (*models.CancellationPolicy, error) {
+	if err := requireProvider(s.db, providerID); err != nil {
+		return nil, err
+	}
+
+	policy, err := getCancellationPolicy(s.db, providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FreeCancellationHours != nil {
+		policy.FreeCancellationHours = *req.FreeCancellationHours
+	}
+	if req.LateCancelFeePercent != nil {
+		policy.LateCancelFeePercent = *req.LateCancelFeePercent
+	}
+	if req.NoShowFeePercent != nil {
+		policy.NoShowFeePercent = *req.NoShowFeePercent
+	}
+	if req.NoShowThreshold != nil {
+		policy.NoShowThreshold = *req.NoShowThreshold
+	}
+	if req.NoShowAction != nil {
+		policy.NoShowAction = *req.NoShowAction
+	}
+	if req.DepositPercent != nil {
+		policy.DepositPercent = *req.DepositPercent
+	}
+	policy.UpdatedAt = time.Now()
+
+	_, err = s.db.Exec(`
+		INSERT INTO cancellation_policies (provider_id, free_cancellation_hours, late_cancel_fee_percent,
+			no_show_fee_percent, no_show_threshold, no_show_action, deposit_percent, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (provider_id) DO UPDATE SET
+			free_cancellation_hours = EXCLUDED.free_cancellation_hours,
+			late_cancel_fee_percent = EXCLUDED.late_cancel_fee_percent,
+			no_show_fee_percent = EXCLUDED.no_show_fee_percent,
+			no_show_threshold = EXCLUDED.no_show_threshold,
+			no_show_action = EXCLUDED.no_show_action,
+			deposit_percent = EXCLUDED.deposit_percent,
+			updated_at = EXCLUDED.updated_at`,
+		policy.ProviderID, policy.FreeCancellationHours, policy.LateCancelFeePercent,
+		policy.NoShowFeePercent, policy.NoShowThreshold, policy.NoShowAction, policy.DepositPercent, policy.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func countNoShows(q queryer, ownerID uuid.UUID) (int, error) {
+	var count int
+	err := q.QueryRow(`SELECT COUNT(*) FROM bookings WHERE user_id = $1 AND status = $2`,
+		ownerID, models.StatusNoShow).Scan(&count)
+	return count, err
+}
+
+// checkOwnerReliability applies the provider's no-show policy to an owner
+// about to book. It returns ErrOwnerBlocked when the owner may not book, and
+// whether a deposit must be taken otherwise.
+func checkOwnerReliability(q queryer, policy *models.CancellationPolicy, ownerID uuid.UUID) (bool, error) {
+	if policy.NoShowThreshold == 0 {
+		return false, nil
+	}
+
+	noShows, err := countNoShows(q, ownerID)
+	if err != nil {
+		return false, err
+	}
+
+	switch policy.ActionFor(noShows) {
+	case models.NoShowActionBlock:
+		return false, ErrOwnerBlocked
+	case models.NoShowActionRequireDeposit:
+		return true, nil
+	}
+	return false, nil
+}
+
+// MarkNoShow records that the owner did not turn up. Only the provider may do
+// this, and only once the scheduled time has passed; the policy's no-show fee
+// is recorded on the booking.
+func (s *BookingService) MarkNoShow(id, providerID uuid.UUID) (*models.Booking, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	booking, err := s.getBooking(tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if booking.ProviderID != providerID {
+		if booking.UserID == providerID {
+			return nil, ErrForbidden
+		}
+		return nil, ErrBookingNotFound
+	}
+	if !booking.Status.CanTransitionTo(models.StatusNoShow) {
+		return nil, ErrInvalidTransition
+	}
+
+	now := time.Now()
+	if now.Before(booking.ScheduledTime) {
+		return nil, ErrNoShowTooEarly
+	}
+
+	policy, err := getCancellationPolicy(tx, booking.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	booking.Status = models.StatusNoShow
+	booking.NoShowFee = policy.NoShowFee(booking.TotalPrice)
+	booking.UpdatedAt = now
+
+	_, err = tx.Exec(`UPDATE bookings SET status = $1, no_show_fee = $2, updated_at = $3 WHERE id = $4`,
+		booking.Status, booking.NoShowFee.Amount, booking.UpdatedAt, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := webhookBookings(tx, models.WebhookBookingNoShow, *booking); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.payments.settleAfterCommit(booking, booking.NoShowFee)
+	s.invoices.issueAfterCommit([]models.Booking{*booking})
+	publishBookingsAfterCommit(s.events, realtime.BookingStatusChanged, *booking)
+	return booking, nil
+}
+
+// ListCustomers returns every owner who has booked with the provider, with
+// their no-show history and how the provider's policy currently treats them.
+func (s *BookingService) ListCustomers(providerID uuid.UUID) ([]models.ProviderCustomer, error) {
+	if err := requireProvider(s.db, providerID); err != nil {
+		return nil, err
+	}
+
+	policy, err := getCancellationPolicy(s.db, providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT u.id, u.email, u.first_name, u.last_name, u.phone, u.address, u.role, u.created_at, u.updated_at,
+			COUNT(b.id),
+			COUNT(b.id) FILTER (WHERE b.status = 'no_show'),
+			(SELECT COUNT(*) FROM bookings nb WHERE nb.user_id = u.id AND nb.status = 'no_show')
+		FROM bookings b
+		JOIN users u ON u.id = b.user_id
+		WHERE b.provider_id = $1
+		GROUP BY u.id
+		ORDER BY u.last_name, u.first_name`, providerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := []models.ProviderCustomer{}
+	for rows.Next() {
+		var customer models.ProviderCustomer
+		user := &customer.User
+		err := rows.Scan(
+			&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.Phone, &user.Address,
+			&user.Role, &user.CreatedAt, &user.UpdatedAt,
+			&customer.BookingCount, &customer.NoShowCount, &customer.TotalNoShows,
+		)
+		if err != nil {
+			return nil, err
+		}
+		customer.NoShowAction = policy.ActionFor(customer.TotalNoShows)
+		customers = append(customers, customer)
+	}
+	return customers, rows.Err()
+}
@@ -0,0 +1,381 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrGiftCardNotFound      = errors.New("gift card not found")
+	ErrGiftCardEmpty         = errors.New("gift card has no balance left")
+	ErrGiftCardNotApplicable = errors.New("gift card cannot be used for this booking")
+	ErrInvalidGiftCardAmount = errors.New("gift card amount must be positive")
+)
+
+const giftCardColumns = `id, code, provider_id, purchaser_id, initial_amount, balance, currency, created_at, updated_at`
+
+func scanGiftCard(row rowScanner) (*models.GiftCard, error) {
+	var card models.GiftCard
+	var purchaserID uuid.NullUUID
+	var currency string
+	err := row.Scan(
+		&card.ID, &card.Code, &card.ProviderID, &purchaserID, &card.InitialAmount.Amount,
+		&card.Balance.Amount, &currency, &card.CreatedAt, &card.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if purchaserID.Valid {
+		card.PurchaserID = &purchaserID.UUID
+	}
+	card.InitialAmount.Currency = currency
+	card.Balance.Currency = currency
+	return &card, nil
+}
+
+const giftCardTransactionColumns = `id, gift_card_id, kind, amount, balance_after, currency, booking_id, created_at`
+
+func scanGiftCardTransaction(row rowScanner) (*models.GiftCardTransaction, error) {
+	var txn models.GiftCardTransaction
+	var bookingID uuid.NullUUID
+	var currency string
+	err := row.Scan(
+		&txn.ID, &txn.GiftCardID, &txn.Kind, &txn.Amount.Amount, &txn.BalanceAfter.Amount,
+		&currency, &bookingID, &txn.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if bookingID.Valid {
+		txn.BookingID = &bookingID.UUID
+	}
+	txn.Amount.Currency = currency
+	txn.BalanceAfter.Currency = currency
+	return &txn, nil
+}
+
+// newGiftCardCode returns a random code grouped for reading aloud, e.g.
+// ABCD-EFGH-IJKL-MNOP.
+func newGiftCardCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	raw := base32.StdEncoding.EncodeToString(b)
+	return raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16], nil
+}
+
+// normalizeGiftCardCode accepts codes typed in any case, with or without the
+// grouping dashes.
+func normalizeGiftCardCode(code string) string {
+	raw := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(raw) != 16 {
+		return raw
+	}
+	return raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16]
+}
+
+// lockGiftCard loads a provider's card by code and locks it for the rest of
+// tx, so concurrent bookings cannot spend the same balance twice.
+func lockGiftCard(tx *sql.Tx, code string, providerID uuid.UUID) (*models.GiftCard, error) {
+	card, err := scanGiftCard(tx.QueryRow(`
+		SELECT `+giftCardColumns+` FROM gift_cards
+		WHERE code = $1 AND provider_id = $2 FOR UPDATE`, normalizeGiftCardCode(code), providerID))
+	if err == sql.ErrNoRows {
+		return nil, ErrGiftCardNotFound
+	}
+	return card, err
+}
+
+// debitGiftCard takes amount off a card for a booking. The balance check is
+// part of the update, so the card can never go negative even without a lock.
+func debitGiftCard(q queryer, cardID uuid.UUID, amount models.Money, bookingID uuid.UUID) error {
+	result, err := q.Exec(`
+		WITH card AS (
+			UPDATE gift_cards SET balance = balance - $1, updated_at = $2
+			WHERE id = $3 AND balance >= $1
+			RETURNING id, balance, currency
+		)
+		INSERT INTO gift_card_transactions (`+giftCardTransactionColumns+`)
+		SELECT $4, id, $5, $1, balance, currency, $6, $2 FROM card`,
+		amount.Amount, time.Now(), cardID, uuid.New(), models.GiftCardRedeem, bookingID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrGiftCardEmpty
+	}
+	return nil
+}
+
+// refundToGiftCard puts amount of a booking's gift card payment back onto the
+// card. transactionID is the payment's TransactionID, which for gift card
+// payments is the card's ID.
+func refundToGiftCard(q queryer, transactionID string, amount models.Money, bookingID uuid.UUID) error {
+	cardID, err := uuid.Parse(transactionID)
+	if err != nil {
+		return fmt.Errorf("gift card payment %q: %w", transactionID, err)
+	}
+	_, err = q.Exec(`
+		WITH card AS (
+			UPDATE gift_cards SET balance = balance + $1, updated_at = $2
+			WHERE id = $3
+			RETURNING id, balance, currency
+		)
+		INSERT INTO gift_card_transactions (`+giftCardTransactionColumns+`)
+		SELECT $4, id, $5, $1, balance, currency, $6, $2 FROM card`,
+		amount.Amount, time.Now(), cardID, uuid.New(), models.GiftCardRefund, bookingID)
+	return err
+}
+
+// payWithGiftCard debits amount from a card and records it as a captured
+// payment on a booking that has just been inserted.
+func payWithGiftCard(tx *sql.Tx, booking *models.Booking, cardID uuid.UUID, amount models.Money) error {
+	if !amount.IsPositive() {
+		return nil
+	}
+	if err := debitGiftCard(tx, cardID, amount, booking.ID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	payment := &models.Payment{
+		ID:             uuid.New(),
+		BookingID:      booking.ID,
+		Kind:           models.PaymentKindGiftCard,
+		TransactionID:  cardID.String(),
+		Amount:         amount,
+		RefundedAmount: models.Zero(amount.Currency),
+		Status:         models.PaymentRecordCaptured,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := insertPayment(tx, payment); err != nil {
+		return err
+	}
+	if err := webhookPayment(tx, booking, payment); err != nil {
+		return err
+	}
+
+	paid, err := booking.AmountPaid.Add(amount)
+	if err != nil {
+		return err
+	}
+	booking.AmountPaid = paid
+	booking.PaymentStatus = paymentStatus(booking.TotalPrice.Amount, booking.AmountPaid.Amount, 0)
+	_, err = tx.Exec(`UPDATE bookings SET amount_paid = $1, payment_status = $2 WHERE id = $3`,
+		booking.AmountPaid.Amount, booking.PaymentStatus, booking.ID)
+	return err
+}
+
+// restoreGiftCardPayments puts whatever a booking still holds from gift
+// cards back onto them, for bookings that are about to be discarded.
+func restoreGiftCardPayments(db *sql.DB, booking *models.Booking) error {
+	rows, err := db.Query(`
+		SELECT transaction_id, amount - refunded_amount FROM payments
+		WHERE booking_id = $1 AND kind = $2 AND status <> 'failed' AND amount > refunded_amount`,
+		booking.ID, models.PaymentKindGiftCard)
+	if err != nil {
+		return err
+	}
+	type held struct {
+		cardID string
+		amount models.Money
+	}
+	var payments []held
+	for rows.Next() {
+		p := held{amount: models.Zero(booking.TotalPrice.Currency)}
+		if err := rows.Scan(&p.cardID, &p.amount.Amount); err != nil {
+			rows.Close()
+			return err
+		}
+		payments = append(payments, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, p := range payments {
+		if err := refundToGiftCard(db, p.cardID, p.amount, booking.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GiftCardService sells and issues gift cards. Redemption happens as part of
+// booking creation.
+type GiftCardService struct {
+	db       *sql.DB
+	payments *PaymentService
+}
+
+func NewGiftCardService(db *sql.DB, payments *PaymentService) *GiftCardService {
+	return &GiftCardService{db: db, payments: payments}
+}
+
+// Purchase sells an owner a gift card for a provider, charging its value
+// before the card is created. If the card cannot be saved the charge is
+// refunded.
+func (s *GiftCardService) Purchase(userID uuid.UUID, req models.PurchaseGiftCardRequest) (*models.GiftCard, error) {
+	if err := requireProvider(s.db, req.ProviderID); err != nil {
+		return nil, err
+	}
+	if !req.Amount.IsPositive() {
+		return nil, ErrInvalidGiftCardAmount
+	}
+
+	card, err := newGiftCard(req.ProviderID, &userID, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	txnID, err := s.payments.ChargePurchase(card.InitialAmount, req.PaymentMethod,
+		fmt.Sprintf("Gift card for %s", card.InitialAmount), card.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.insert(card); err != nil {
+		if refundErr := s.payments.RefundPurchase(txnID, card.InitialAmount); refundErr != nil {
+			log.Printf("Failed to refund unrecorded gift card %s (transaction %s): %v", card.ID, txnID, refundErr)
+		}
+		return nil, err
+	}
+	return card, nil
+}
+
+// Issue creates a complimentary card on the provider's behalf, for example
+// as a goodwill gesture or a prize.
+func (s *GiftCardService) Issue(providerID uuid.UUID, req models.IssueGiftCardRequest) (*models.GiftCard, error) {
+	if err := requireProvider(s.db, providerID); err != nil {
+		return nil, err
+	}
+	if !req.Amount.IsPositive() {
+		return nil, ErrInvalidGiftCardAmount
+	}
+
+	card, err := newGiftCard(providerID, nil, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.insert(card); err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+func newGiftCard(providerID uuid.UUID, purchaserID *uuid.UUID, amount models.Money) (*models.GiftCard, error) {
+	code, err := newGiftCardCode()
+	if err != nil {
+		return nil, err
+	}
+	amount.Currency = models.NormalizeCurrency(amount.Currency)
+	now := time.Now()
+	return &models.GiftCard{
+		ID:            uuid.New(),
+		Code:          code,
+		ProviderID:    providerID,
+		PurchaserID:   purchaserID,
+		InitialAmount: amount,
+		Balance:       amount,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// insert stores a new card together with the ledger entry that issued it.
+func (s *GiftCardService) insert(card *models.GiftCard) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO gift_cards (`+giftCardColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		card.ID, card.Code, card.ProviderID, card.PurchaserID, card.InitialAmount.Amount,
+		card.Balance.Amount, card.Balance.Currency, card.CreatedAt, card.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO gift_card_transactions (`+giftCardTransactionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, NULL, $7)`,
+		uuid.New(), card.ID, models.GiftCardIssue, card.InitialAmount.Amount, card.Balance.Amount,
+		card.Balance.Currency, card.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListPurchased returns the cards an owner has bought, newest first.
+func (s *GiftCardService) ListPurchased(userID uuid.UUID) ([]models.GiftCard, error) {
+	return s.list(`WHERE purchaser_id = $1`, userID)
+}
+
+// ListForProvider returns every card redeemable with a provider, newest
+// first.
+func (s *GiftCardService) ListForProvider(providerID uuid.UUID) ([]models.GiftCard, error) {
+	return s.list(`WHERE provider_id = $1`, providerID)
+}
+
+func (s *GiftCardService) list(where string, id uuid.UUID) ([]models.GiftCard, error) {
+	rows, err := s.db.Query(`SELECT `+giftCardColumns+` FROM gift_cards `+where+` ORDER BY created_at DESC`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cards := []models.GiftCard{}
+	for rows.Next() {
+		card, err := scanGiftCard(rows)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, *card)
+	}
+	return cards, rows.Err()
+}
+
+// Lookup returns a card and its ledger to anyone holding the code, so
+// recipients can check their balance before booking.
+func (s *GiftCardService) Lookup(code string) (*models.GiftCardDetails, error) {
+	card, err := scanGiftCard(s.db.QueryRow(`
+		SELECT `+giftCardColumns+` FROM gift_cards WHERE code = $1`, normalizeGiftCardCode(code)))
+	if err == sql.ErrNoRows {
+		return nil, ErrGiftCardNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+giftCardTransactionColumns+` FROM gift_card_transactions
+		WHERE gift_card_id = $1 ORDER BY created_at`, card.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	details := &models.GiftCardDetails{GiftCard: *card, Transactions: []models.GiftCardTransaction{}}
+	for rows.Next() {
+		txn, err := scanGiftCardTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		details.Transactions = append(details.Transactions, *txn)
+	}
+	return details, rows.Err()
+}
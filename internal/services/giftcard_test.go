@@ -0,0 +1,127 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/payments"
+
+	"github.com/google/uuid"
+)
+
+func giftCardBalance(t *testing.T, db *sql.DB, cardID uuid.UUID) int64 {
+	t.Helper()
+	var balance int64
+	if err := db.QueryRow(`SELECT balance FROM gift_cards WHERE id = $1`, cardID).Scan(&balance); err != nil {
+		t.Fatal(err)
+	}
+	return balance
+}
+
+func TestConcurrentRedemptionsCannotOverdraw(t *testing.T) {
+	db := openTestDB(t)
+	booking := seedBooking(t, db, models.ServiceGrooming, models.NewMoney(1000, "USD"), models.Zero("USD"), "gift_card")
+	cards := NewGiftCardService(db, NewPaymentService(db, payments.NewFakeProvider()))
+	card, err := cards.Issue(booking.ProviderID, models.IssueGiftCardRequest{Amount: models.NewMoney(5000, "USD")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ten redemptions of 10.00 race for a 50.00 balance.
+	const attempts = 10
+	results := make(chan error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tx, err := db.Begin()
+			if err != nil {
+				results <- err
+				return
+			}
+			defer tx.Rollback()
+			if err := debitGiftCard(tx, card.ID, models.NewMoney(1000, "USD"), booking.ID); err != nil {
+				results <- err
+				return
+			}
+			results <- tx.Commit()
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrGiftCardEmpty):
+			t.Errorf("redemption failed: %v", err)
+		}
+	}
+	if succeeded != 5 {
+		t.Errorf("%d redemptions succeeded, want 5", succeeded)
+	}
+	if balance := giftCardBalance(t, db, card.ID); balance != 0 {
+		t.Errorf("balance = %d, want 0", balance)
+	}
+}
+
+func TestGiftCardPaymentRefundsToCard(t *testing.T) {
+	db := openTestDB(t)
+	paymentService := NewPaymentService(db, payments.NewFakeProvider())
+	booking := seedBooking(t, db, models.ServiceGrooming, models.NewMoney(3000, "USD"), models.Zero("USD"), "gift_card")
+	card, err := NewGiftCardService(db, paymentService).Issue(booking.ProviderID,
+		models.IssueGiftCardRequest{Amount: models.NewMoney(8000, "USD")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := payWithGiftCard(tx, booking, card.ID, models.NewMoney(3000, "USD")); err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if balance := giftCardBalance(t, db, card.ID); balance != 5000 {
+		t.Fatalf("balance after paying = %d, want 5000", balance)
+	}
+
+	// A partial refund goes back onto the card, not to a card processor.
+	amount := models.NewMoney(1000, "USD")
+	refunded, err := paymentService.Refund(booking.ID, booking.ProviderID, models.RefundRequest{Amount: &amount})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refunded) != 1 || refunded[0].Kind != models.PaymentKindGiftCard || refunded[0].RefundedAmount.Amount != 1000 {
+		t.Errorf("payments after refund = %+v", refunded)
+	}
+	if balance := giftCardBalance(t, db, card.ID); balance != 6000 {
+		t.Errorf("balance after partial refund = %d, want 6000", balance)
+	}
+
+	// Refunding the rest restores the card in full.
+	if _, err := paymentService.Refund(booking.ID, booking.ProviderID, models.RefundRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if balance := giftCardBalance(t, db, card.ID); balance != 8000 {
+		t.Errorf("balance after full refund = %d, want 8000", balance)
+	}
+	var refunds int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM gift_card_transactions WHERE gift_card_id = $1 AND kind = $2`,
+		card.ID, models.GiftCardRefund).Scan(&refunds); err != nil {
+		t.Fatal(err)
+	}
+	if refunds != 2 {
+		t.Errorf("%d refund entries in the card's ledger, want 2", refunds)
+	}
+}
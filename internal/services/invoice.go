@@ -0,0 +1,483 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/pdf"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvoiceNotAvailable = errors.New("invoices are only issued for completed bookings and fees charged")
+
+const invoiceColumns = `id, booking_id, provider_id, user_id, number, invoice_number, issued_at, provider_name, customer_name, pet_name, service_date, currency, subtotal, tax_total, tip_total, total, amount_paid, tax_registration_number`
+
+func scanInvoice(row rowScanner) (*models.Invoice, error) {
+	var inv models.Invoice
+	var currency string
+	err := row.Scan(
+		&inv.ID, &inv.BookingID, &inv.ProviderID, &inv.UserID, &inv.Number, &inv.InvoiceNumber,
+		&inv.IssuedAt, &inv.ProviderName, &inv.CustomerName, &inv.PetName, &inv.ServiceDate, &currency,
+		&inv.Subtotal.Amount, &inv.TaxTotal.Amount, &inv.TipTotal.Amount, &inv.Total.Amount, &inv.AmountPaid.Amount,
+		&inv.TaxRegistrationNumber,
+	)
+	if err != nil {
+		return nil, err
+	}
+	inv.BalanceDue = models.NewMoney(inv.Total.Amount-inv.AmountPaid.Amount, currency)
+	inv.SetCurrency(currency)
+	return &inv, nil
+}
+
+// InvoiceService issues invoices for completed bookings, and for late
+// cancellations and no-shows that were charged a fee. Invoice numbers are
+// allocated from a per-provider counter so each provider's invoices run
+// 1, 2, 3... without gaps.
+type InvoiceService struct {
+	db *sql.DB
+}
+
+func NewInvoiceService(db *sql.DB) *InvoiceService {
+	return &InvoiceService{db: db}
+}
+
+// FormatInvoiceNumber renders a provider's sequential invoice number.
+func FormatInvoiceNumber(number int) string {
+	return fmt.Sprintf("INV-%06d", number)
+}
+
+// Get returns the invoice for a booking, visible to its owner and provider.
+// Invoiceable bookings whose invoice has not been issued yet get one now.
+func (s *InvoiceService) Get(bookingID, userID uuid.UUID) (*models.Invoice, error) {
+	booking, err := getBookingRow(s.db, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID && booking.ProviderID != userID {
+		return nil, ErrBookingNotFound
+	}
+	return s.Issue(bookingID)
+}
+
+// List returns the invoices issued to or by userID, newest first.
+func (s *InvoiceService) List(userID uuid.UUID) ([]models.Invoice, error) {
+	rows, err := s.db.Query(`
+		SELECT `+invoiceColumns+` FROM invoices
+		WHERE user_id = $1 OR provider_id = $1
+		ORDER BY issued_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := []models.Invoice{}
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, *inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range invoices {
+		if invoices[i].Lines, err = s.loadLines(s.db, invoices[i].ID, invoices[i].Total.Currency); err != nil {
+			return nil, err
+		}
+	}
+	return invoices, nil
+}
+
+// Issue returns the booking's invoice, creating it if the booking is
+// invoiceable and none exists yet. The booking row is locked so concurrent
+// callers cannot issue two invoices for it.
+func (s *InvoiceService) Issue(bookingID uuid.UUID) (*models.Invoice, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	booking, err := lockBooking(tx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := s.findByBooking(tx, booking.ID)
+	if err != nil || inv != nil {
+		return inv, err
+	}
+	if !invoiceable(booking) {
+		return nil, ErrInvoiceNotAvailable
+	}
+
+	inv, err = s.build(tx, booking)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO invoice_counters (provider_id, last_number) VALUES ($1, 1)
+		ON CONFLICT (provider_id) DO UPDATE SET last_number = invoice_counters.last_number + 1
+		RETURNING last_number`, booking.ProviderID).Scan(&inv.Number)
+	if err != nil {
+		return nil, err
+	}
+	inv.InvoiceNumber = FormatInvoiceNumber(inv.Number)
+
+	_, err = tx.Exec(`
+		INSERT INTO invoices (`+invoiceColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`,
+		inv.ID, inv.BookingID, inv.ProviderID, inv.UserID, inv.Number, inv.InvoiceNumber, inv.IssuedAt,
+		inv.ProviderName, inv.CustomerName, inv.PetName, inv.ServiceDate, inv.Total.Currency,
+		inv.Subtotal.Amount, inv.TaxTotal.Amount, inv.TipTotal.Amount, inv.Total.Amount, inv.AmountPaid.Amount,
+		inv.TaxRegistrationNumber)
+	if err != nil {
+		return nil, err
+	}
+	if err := insertInvoiceLines(tx, inv.ID, 1, inv.Lines); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// invoiceable reports whether a booking is billed: it was completed, or it
+// was cancelled late or missed and owes a fee.
+func invoiceable(booking *models.Booking) bool {
+	switch booking.Status {
+	case models.StatusCompleted:
+		return true
+	case models.StatusCancelled:
+		return booking.CancellationFee.IsPositive()
+	case models.StatusNoShow:
+		return booking.NoShowFee.IsPositive()
+	}
+	return false
+}
+
+// issueAfterCommit issues invoices for bookings that have just been
+// completed, cancelled or missed, skipping those with nothing to bill. The
+// change stands regardless, so failures are only logged; the invoice is
+// issued on first request instead.
+func (s *InvoiceService) issueAfterCommit(bookings []models.Booking) {
+	for i, booking := range bookings {
+		if !invoiceable(&bookings[i]) {
+			continue
+		}
+		if _, err := s.Issue(booking.ID); err != nil {
+			log.Printf("Failed to issue invoice for booking %s: %v", booking.ID, err)
+		}
+	}
+}
+
+// addTip appends a tip given after the booking's invoice was issued. Invoices
+// not issued yet pick the tip up when they are built.
+func (s *InvoiceService) addTip(tip *models.Tip) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var invoiceID uuid.UUID
+	var position int
+	err = tx.QueryRow(`SELECT id FROM invoices WHERE booking_id = $1 FOR UPDATE`, tip.BookingID).Scan(&invoiceID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(`SELECT COALESCE(MAX(position), 0) FROM invoice_lines WHERE invoice_id = $1`, invoiceID).Scan(&position)
+	if err != nil {
+		return err
+	}
+	if err := insertInvoiceLines(tx, invoiceID, position+1, []models.InvoiceLine{tipLine(tip)}); err != nil {
+		return err
+	}
+
+	paid := int64(0)
+	if tip.PaymentID != nil {
+		paid = tip.Amount.Amount
+	}
+	_, err = tx.Exec(`
+		UPDATE invoices SET tip_total = tip_total + $1, total = total + $1, amount_paid = amount_paid + $2
+		WHERE id = $3`, tip.Amount.Amount, paid, invoiceID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *InvoiceService) findByBooking(q queryer, bookingID uuid.UUID) (*models.Invoice, error) {
+	inv, err := scanInvoice(q.QueryRow(`SELECT `+invoiceColumns+` FROM invoices WHERE booking_id = $1`, bookingID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	inv.Lines, err = s.loadLines(q, inv.ID, inv.Total.Currency)
+	return inv, err
+}
+
+func (s *InvoiceService) loadLines(q queryer, invoiceID uuid.UUID, currency string) ([]models.InvoiceLine, error) {
+	rows, err := q.Query(`
+		SELECT kind, description, quantity, unit_amount, amount FROM invoice_lines
+		WHERE invoice_id = $1 ORDER BY position`, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []models.InvoiceLine{}
+	for rows.Next() {
+		var line models.InvoiceLine
+		if err := rows.Scan(&line.Kind, &line.Description, &line.Quantity, &line.UnitAmount.Amount, &line.Amount.Amount); err != nil {
+			return nil, err
+		}
+		line.UnitAmount.Currency = currency
+		line.Amount.Currency = currency
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// insertInvoiceLines stores lines in order, numbering them from first.
+func insertInvoiceLines(tx *sql.Tx, invoiceID uuid.UUID, first int, lines []models.InvoiceLine) error {
+	for i, line := range lines {
+		_, err := tx.Exec(`
+			INSERT INTO invoice_lines (invoice_id, position, kind, description, quantity, unit_amount, amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			invoiceID, first+i, line.Kind, line.Description, line.Quantity, line.UnitAmount.Amount, line.Amount.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// build assembles an unnumbered invoice for a booking and the names printed
+// on it. Completed bookings are billed for the service with its discount and
+// tax, and any tip; cancelled and missed bookings only for their fee.
+func (s *InvoiceService) build(q queryer, booking *models.Booking) (*models.Invoice, error) {
+	inv := &models.Invoice{
+		ID:          uuid.New(),
+		BookingID:   booking.ID,
+		ProviderID:  booking.ProviderID,
+		UserID:      booking.UserID,
+		IssuedAt:    time.Now(),
+		ServiceDate: booking.ScheduledTime,
+		AmountPaid:  booking.AmountPaid,
+	}
+
+	var err error
+	if inv.ProviderName, err = userDisplayName(q, booking.ProviderID); err != nil {
+		return nil, err
+	}
+	if inv.CustomerName, err = userDisplayName(q, booking.UserID); err != nil {
+		return nil, err
+	}
+	tax, err := getTaxSettings(q, booking.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	inv.TaxRegistrationNumber = tax.RegistrationNumber
+	if err := q.QueryRow(`SELECT name FROM pets WHERE id = $1`, booking.PetID).Scan(&inv.PetName); err != nil {
+		return nil, err
+	}
+
+	if booking.Status == models.StatusCompleted {
+		if inv.Lines, err = saleLines(q, booking); err != nil {
+			return nil, err
+		}
+	}
+	for _, fee := range []struct {
+		description string
+		amount      models.Money
+	}{
+		{"Late cancellation fee", booking.CancellationFee},
+		{"No-show fee", booking.NoShowFee},
+	} {
+		if fee.amount.IsPositive() {
+			inv.Lines = append(inv.Lines, models.InvoiceLine{
+				Kind:        models.InvoiceLineFee,
+				Description: fee.description,
+				Quantity:    1,
+				UnitAmount:  fee.amount,
+				Amount:      fee.amount,
+			})
+		}
+	}
+
+	tip, err := getTip(q, booking.ID)
+	switch {
+	case err == nil:
+		inv.Lines = append(inv.Lines, tipLine(tip))
+		if tip.PaymentID != nil {
+			if inv.AmountPaid, err = inv.AmountPaid.Add(tip.Amount); err != nil {
+				return nil, err
+			}
+		}
+	case !errors.Is(err, ErrTipNotFound):
+		return nil, err
+	}
+
+	if err := inv.Summarize(booking.TotalPrice.Currency); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// saleLines lists what a completed booking was sold for: the service at its
+// listed price, discounts off it and the tax on the result.
+func saleLines(q queryer, booking *models.Booking) ([]models.InvoiceLine, error) {
+	service, err := getService(q, booking.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []models.InvoiceLine
+	description := service.Name
+	if booking.CreditID != nil {
+		description += " (prepaid)"
+	}
+	listed := models.NewMoney(booking.Subtotal.Amount+booking.Discount.Amount+booking.PointsDiscount.Amount,
+		booking.TotalPrice.Currency)
+	line := models.InvoiceLine{
+		Kind:        models.InvoiceLineService,
+		Description: description,
+		Quantity:    1,
+		UnitAmount:  listed,
+		Amount:      listed,
+	}
+	// Stays are listed per night where the nightly rate divides evenly, as
+	// it does unless tax-inclusive pricing rounded the subtotal.
+	if booking.Nights > 0 {
+		if listed.Amount%int64(booking.Nights) == 0 {
+			line.Quantity = booking.Nights
+			line.UnitAmount = models.NewMoney(listed.Amount/int64(booking.Nights), listed.Currency)
+		} else {
+			line.Description += fmt.Sprintf(" (%d nights)", booking.Nights)
+		}
+	}
+	lines = append(lines, line)
+	if booking.PromoCodeID != nil && booking.Discount.IsPositive() {
+		var code string
+		if err := q.QueryRow(`SELECT code FROM promo_codes WHERE id = $1`, *booking.PromoCodeID).Scan(&code); err != nil {
+			return nil, err
+		}
+		discount := models.NewMoney(-booking.Discount.Amount, booking.Discount.Currency)
+		lines = append(lines, models.InvoiceLine{
+			Kind:        models.InvoiceLineDiscount,
+			Description: "Discount (" + code + ")",
+			Quantity:    1,
+			UnitAmount:  discount,
+			Amount:      discount,
+		})
+	}
+	if booking.PointsDiscount.IsPositive() {
+		discount := models.NewMoney(-booking.PointsDiscount.Amount, booking.PointsDiscount.Currency)
+		lines = append(lines, models.InvoiceLine{
+			Kind:        models.InvoiceLineDiscount,
+			Description: fmt.Sprintf("Loyalty points (%d)", booking.PointsRedeemed),
+			Quantity:    1,
+			UnitAmount:  discount,
+			Amount:      discount,
+		})
+	}
+	if booking.TaxAmount.IsPositive() {
+		lines = append(lines, models.InvoiceLine{
+			Kind:        models.InvoiceLineTax,
+			Description: fmt.Sprintf("Tax (%s%%)", strconv.FormatFloat(booking.TaxRate, 'f', -1, 64)),
+			Quantity:    1,
+			UnitAmount:  booking.TaxAmount,
+			Amount:      booking.TaxAmount,
+		})
+	}
+	return lines, nil
+}
+
+func userDisplayName(q queryer, id uuid.UUID) (string, error) {
+	var firstName, lastName string
+	err := q.QueryRow(`SELECT first_name, last_name FROM users WHERE id = $1`, id).Scan(&firstName, &lastName)
+	return strings.TrimSpace(firstName + " " + lastName), err
+}
+
+// RenderInvoicePDF lays the invoice out as a single A4 page, continuing onto
+// further pages if there are more lines than fit.
+func RenderInvoicePDF(inv *models.Invoice) []byte {
+	doc := pdf.New()
+	const left, right = 50.0, pdf.PageWidth - 50
+
+	doc.Text(left, 70, 22, true, "Invoice")
+	doc.TextRight(right, 70, 12, true, inv.InvoiceNumber)
+	doc.Text(left, 100, 10, false, "Issued: "+inv.IssuedAt.Format("2 January 2006"))
+	doc.Text(left, 115, 10, false, "Service date: "+inv.ServiceDate.Format("2 January 2006 15:04"))
+
+	doc.Text(left, 150, 10, true, "From")
+	doc.Text(left, 165, 10, false, inv.ProviderName)
+	if inv.TaxRegistrationNumber != "" {
+		doc.Text(left, 180, 10, false, "Tax registration: "+inv.TaxRegistrationNumber)
+	}
+	doc.Text(300, 150, 10, true, "Billed to")
+	doc.Text(300, 165, 10, false, inv.CustomerName)
+	doc.Text(300, 180, 10, false, "Pet: "+inv.PetName)
+
+	y := 220.0
+	header := func() {
+		doc.Text(left, y, 10, true, "Description")
+		doc.TextRight(350, y, 10, true, "Qty")
+		doc.TextRight(450, y, 10, true, "Unit")
+		doc.TextRight(right, y, 10, true, "Amount")
+		y += 20
+	}
+	header()
+	for _, line := range inv.Lines {
+		if y > pdf.PageHeight-150 {
+			doc.AddPage()
+			y = 70
+			header()
+		}
+		doc.Text(left, y, 10, false, line.Description)
+		doc.TextRight(350, y, 10, false, fmt.Sprint(line.Quantity))
+		doc.TextRight(450, y, 10, false, line.UnitAmount.String())
+		doc.TextRight(right, y, 10, false, line.Amount.String())
+		y += 16
+	}
+
+	y += 14
+	for _, total := range []struct {
+		label  string
+		amount models.Money
+		bold   bool
+	}{
+		{"Subtotal", inv.Subtotal, false},
+		{"Tax", inv.TaxTotal, false},
+		{"Tips", inv.TipTotal, false},
+		{"Total", inv.Total, true},
+		{"Paid", inv.AmountPaid, false},
+		{"Balance due", inv.BalanceDue, true},
+	} {
+		doc.Text(350, y, 10, total.bold, total.label)
+		doc.TextRight(right, y, 10, total.bold, total.amount.String())
+		y += 16
+	}
+
+	return doc.Bytes()
+}
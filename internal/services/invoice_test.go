@@ -0,0 +1,29 @@
+package services
+
+import (
+	"testing"
+
+	"pet-grooming-app/internal/models"
+)
+
+func TestInvoiceable(t *testing.T) {
+	fee, none := models.NewMoney(2500, "USD"), models.Zero("USD")
+	tests := []struct {
+		status               models.BookingStatus
+		cancelFee, noShowFee models.Money
+		want                 bool
+	}{
+		{models.StatusCompleted, none, none, true},
+		{models.StatusConfirmed, none, none, false},
+		{models.StatusCancelled, fee, none, true},
+		{models.StatusCancelled, none, none, false},
+		{models.StatusNoShow, none, fee, true},
+		{models.StatusNoShow, none, none, false},
+	}
+	for _, tt := range tests {
+		b := &models.Booking{Status: tt.status, CancellationFee: tt.cancelFee, NoShowFee: tt.noShowFee}
+		if got := invoiceable(b); got != tt.want {
+			t.Errorf("invoiceable(%s, fees %s/%s) = %v, want %v", tt.status, tt.cancelFee, tt.noShowFee, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInsufficientPoints  = errors.New("not enough loyalty points")
+	ErrPointsNotApplicable = errors.New("loyalty points can only be redeemed on a single paid booking")
+	ErrTooManyPoints       = errors.New("cannot redeem more points than the booking costs")
+	ErrInvalidReferralCode = errors.New("referral code not found")
+)
+
+const pointsColumns = `id, user_id, kind, reason, points, remaining, source_id, expires_at, created_at`
+
+func scanPointsEntry(row rowScanner) (*models.PointsEntry, error) {
+	var entry models.PointsEntry
+	var sourceID uuid.NullUUID
+	var expiresAt sql.NullTime
+	err := row.Scan(
+		&entry.ID, &entry.UserID, &entry.Kind, &entry.Reason, &entry.Points, &entry.Remaining,
+		&sourceID, &expiresAt, &entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if sourceID.Valid {
+		entry.SourceID = &sourceID.UUID
+	}
+	if expiresAt.Valid {
+		entry.ExpiresAt = &expiresAt.Time
+	}
+	return &entry, nil
+}
+
+// creditPoints adds points to an owner's ledger with a fresh expiry. Each
+// source earns a given reason at most once, so repeated calls are harmless.
+func creditPoints(q queryer, userID uuid.UUID, kind models.PointsEntryKind, reason models.PointsReason, points int, sourceID uuid.UUID) error {
+	now := time.Now()
+	_, err := q.Exec(`
+		INSERT INTO points_ledger (`+pointsColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $5, $6, $7, $8)
+		ON CONFLICT (user_id, reason, source_id) DO NOTHING`,
+		uuid.New(), userID, kind, reason, points, sourceID, now.Add(models.PointsLifetime), now)
+	return err
+}
+
+// expiryEntryID is the ID of the ledger entry writing off lot's lapsed
+// points. Reads show lapsed points under the same ID before the write-off is
+// recorded.
+func expiryEntryID(lotID uuid.UUID) uuid.UUID {
+	return uuid.NewSHA1(lotID, []byte("expiry"))
+}
+
+// withLapsed adds the write-offs due for lots that lapsed by now but have
+// not been written off yet, keeping entries newest first.
+func withLapsed(entries []models.PointsEntry, now time.Time) []models.PointsEntry {
+	for i := range entries {
+		lot := &entries[i]
+		if lot.Remaining <= 0 || lot.ExpiresAt == nil || lot.ExpiresAt.After(now) {
+			continue
+		}
+		sourceID := lot.ID
+		entries = append(entries, models.PointsEntry{
+			ID:        expiryEntryID(lot.ID),
+			UserID:    lot.UserID,
+			Kind:      models.PointsExpire,
+			Reason:    models.ReasonExpiry,
+			Points:    -lot.Remaining,
+			SourceID:  &sourceID,
+			CreatedAt: *lot.ExpiresAt,
+		})
+		entries[i].Remaining = 0
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	return entries
+}
+
+// lockPointsAccount serialises ledger changes for one owner and writes off
+// any points that have lapsed.
+func lockPointsAccount(tx *sql.Tx, userID uuid.UUID) error {
+	var id uuid.UUID
+	if err := tx.QueryRow(`SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&id); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, remaining FROM points_ledger
+		WHERE user_id = $1 AND remaining > 0 AND expires_at <= NOW()`, userID)
+	if err != nil {
+		return err
+	}
+	type lapsed struct {
+		id        uuid.UUID
+		remaining int
+	}
+	var expired []lapsed
+	for rows.Next() {
+		var entry lapsed
+		if err := rows.Scan(&entry.id, &entry.remaining); err != nil {
+			rows.Close()
+			return err
+		}
+		expired = append(expired, entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, entry := range expired {
+		if _, err := tx.Exec(`UPDATE points_ledger SET remaining = 0 WHERE id = $1`, entry.id); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+			INSERT INTO points_ledger (`+pointsColumns+`)
+			VALUES ($1, $2, $3, $4, $5, 0, $6, NULL, $7)`,
+			expiryEntryID(entry.id), userID, models.PointsExpire, models.ReasonExpiry, -entry.remaining, entry.id, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func pointsBalance(q queryer, userID uuid.UUID) (int, error) {
+	var balance int
+	err := q.QueryRow(`
+		SELECT COALESCE(SUM(remaining), 0) FROM points_ledger
+		WHERE user_id = $1 AND remaining > 0 AND expires_at > NOW()`, userID).Scan(&balance)
+	return balance, err
+}
+
+// spendPoints redeems points against a booking, using up the points that
+// expire soonest first.
+func spendPoints(tx *sql.Tx, userID uuid.UUID, points int, bookingID uuid.UUID) error {
+	if err := lockPointsAccount(tx, userID); err != nil {
+		return err
+	}
+	balance, err := pointsBalance(tx, userID)
+	if err != nil {
+		return err
+	}
+	if balance < points {
+		return ErrInsufficientPoints
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, remaining FROM points_ledger
+		WHERE user_id = $1 AND remaining > 0 AND expires_at > NOW()
+		ORDER BY expires_at`, userID)
+	if err != nil {
+		return err
+	}
+	type lot struct {
+		id        uuid.UUID
+		remaining int
+	}
+	var lots []lot
+	for rows.Next() {
+		var l lot
+		if err := rows.Scan(&l.id, &l.remaining); err != nil {
+			rows.Close()
+			return err
+		}
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	left := points
+	for _, l := range lots {
+		if left == 0 {
+			break
+		}
+		used := min(l.remaining, left)
+		if _, err := tx.Exec(`UPDATE points_ledger SET remaining = remaining - $1 WHERE id = $2`, used, l.id); err != nil {
+			return err
+		}
+		left -= used
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO points_ledger (`+pointsColumns+`)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, NULL, $7)`,
+		uuid.New(), userID, models.PointsRedeem, models.ReasonRedemption, -points, bookingID, time.Now())
+	return err
+}
+
+// refundBookingPoints returns the points a booking was paid with, for
+// cancellations and discarded bookings.
+func refundBookingPoints(q queryer, booking *models.Booking) error {
+	if booking.PointsRedeemed == 0 {
+		return nil
+	}
+	return creditPoints(q, booking.UserID, models.PointsRefund, models.ReasonCancellation, booking.PointsRedeemed, booking.ID)
+}
+
+func newReferralCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(b), nil
+}
+
+// LoyaltyService awards and reports loyalty points. Redemption happens as
+// part of booking creation.
+type LoyaltyService struct {
+	db *sql.DB
+}
+
+func NewLoyaltyService(db *sql.DB) *LoyaltyService {
+	return &LoyaltyService{db: db}
+}
+
+// awardCompletedAfterCommit credits points for bookings that have just been
+// completed and, on an owner's first completed booking, rewards whoever
+// referred them. The completion stands regardless, so failures are logged.
+func (s *LoyaltyService) awardCompletedAfterCommit(bookings []models.Booking) {
+	for _, booking := range bookings {
+		if err := creditPoints(s.db, booking.UserID, models.PointsEarn, models.ReasonBookingCompleted,
+			models.PointsPerBooking, booking.ID); err != nil {
+			log.Printf("Failed to award points for booking %s: %v", booking.ID, err)
+		}
+
+		var referrer uuid.NullUUID
+		if err := s.db.QueryRow(`SELECT referred_by FROM users WHERE id = $1`, booking.UserID).Scan(&referrer); err != nil {
+			log.Printf("Failed to look up referrer of user %s: %v", booking.UserID, err)
+			continue
+		}
+		if referrer.Valid {
+			if err := creditPoints(s.db, referrer.UUID, models.PointsEarn, models.ReasonReferral,
+				models.PointsPerReferral, booking.UserID); err != nil {
+				log.Printf("Failed to award referral points to user %s: %v", referrer.UUID, err)
+			}
+		}
+	}
+}
+
+// Run writes off lapsed points every interval until ctx is cancelled. Reads
+// leave lapsed points out without waiting for it, and the next change to an
+// owner's ledger writes theirs off too.
+func (s *LoyaltyService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.ExpirePoints(); err != nil {
+			log.Printf("Failed to expire loyalty points: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpirePoints writes off lapsed points for every owner who has some, one
+// owner per transaction.
+func (s *LoyaltyService) ExpirePoints() error {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT user_id FROM points_ledger
+		WHERE remaining > 0 AND expires_at <= NOW()`)
+	if err != nil {
+		return err
+	}
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		userIDs = append(userIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if err := s.expireAccount(userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LoyaltyService) expireAccount(userID uuid.UUID) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockPointsAccount(tx, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Balance returns the owner's current points, the next batch due to lapse,
+// and their referral code. Points past their expiry are left out whether or
+// not they have been written off yet.
+func (s *LoyaltyService) Balance(userID uuid.UUID) (*models.PointsBalance, error) {
+	var err error
+	balance := &models.PointsBalance{UserID: userID}
+	if balance.Points, err = pointsBalance(s.db, userID); err != nil {
+		return nil, err
+	}
+
+	var nextExpiry sql.NullTime
+	err = s.db.QueryRow(`
+		SELECT expires_at, SUM(remaining) FROM points_ledger
+		WHERE user_id = $1 AND remaining > 0 AND expires_at > NOW()
+		GROUP BY expires_at ORDER BY expires_at LIMIT 1`, userID).Scan(&nextExpiry, &balance.ExpiringNext)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if nextExpiry.Valid {
+		balance.NextExpiry = &nextExpiry.Time
+	}
+
+	if balance.ReferralCode, err = referralCode(s.db, userID); err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+// referralCode returns the user's referral code, assigning one to accounts
+// created before referrals existed. Only that first call writes.
+func referralCode(q queryer, userID uuid.UUID) (string, error) {
+	var code sql.NullString
+	if err := q.QueryRow(`SELECT referral_code FROM users WHERE id = $1`, userID).Scan(&code); err != nil {
+		return "", err
+	}
+	if code.Valid {
+		return code.String, nil
+	}
+
+	newCode, err := newReferralCode()
+	if err != nil {
+		return "", err
+	}
+	// A concurrent first call may have assigned one already; keep theirs.
+	err = q.QueryRow(`
+		UPDATE users SET referral_code = COALESCE(referral_code, $1)
+		WHERE id = $2 RETURNING referral_code`, newCode, userID).Scan(&code)
+	return code.String, err
+}
+
+// History returns the owner's ledger, newest first, including write-offs
+// of lapsed points that have not been recorded yet.
+func (s *LoyaltyService) History(userID uuid.UUID) ([]models.PointsEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT `+pointsColumns+` FROM points_ledger
+		WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := []models.PointsEntry{}
+	for rows.Next() {
+		entry, err := scanPointsEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return withLapsed(entries, time.Now()), nil
+}
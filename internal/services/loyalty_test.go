@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestWithLapsed(t *testing.T) {
+	now := time.Now()
+	lapsedAt, laterAt := now.Add(-time.Hour), now.Add(time.Hour)
+	lapsed := models.PointsEntry{
+		ID: uuid.New(), Kind: models.PointsEarn, Points: 100, Remaining: 40,
+		ExpiresAt: &lapsedAt, CreatedAt: now.Add(-48 * time.Hour),
+	}
+	current := models.PointsEntry{
+		ID: uuid.New(), Kind: models.PointsEarn, Points: 50, Remaining: 50,
+		ExpiresAt: &laterAt, CreatedAt: now.Add(-24 * time.Hour),
+	}
+
+	entries := withLapsed([]models.PointsEntry{current, lapsed}, now)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want the two lots and a write-off", len(entries))
+	}
+	writeOff := entries[0]
+	if writeOff.Kind != models.PointsExpire || writeOff.Points != -40 || !writeOff.CreatedAt.Equal(lapsedAt) {
+		t.Errorf("newest entry = %+v, want a 40 point write-off at expiry", writeOff)
+	}
+	if writeOff.ID != expiryEntryID(lapsed.ID) || writeOff.SourceID == nil || *writeOff.SourceID != lapsed.ID {
+		t.Errorf("write-off %s for %v, want %s for lot %s", writeOff.ID, writeOff.SourceID, expiryEntryID(lapsed.ID), lapsed.ID)
+	}
+	if entries[1].ID != current.ID || entries[2].Remaining != 0 {
+		t.Errorf("entries = %+v, want current lot then the spent lapsed lot", entries[1:])
+	}
+
+	// Already written off: nothing to add.
+	lapsed.Remaining = 0
+	if got := withLapsed([]models.PointsEntry{current, lapsed}, now); len(got) != 2 {
+		t.Errorf("got %d entries for written-off lot, want 2", len(got))
+	}
+}
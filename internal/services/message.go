@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrEmptyMessage       = errors.New("message needs a body or an attachment")
+	ErrTooManyAttachments = errors.New("too many attachments")
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	ErrAttachmentType     = errors.New("attachments must be images or PDFs")
+	ErrAttachmentNotFound = errors.New("attachment not found")
+)
+
+var allowedAttachmentTypes = map[string]bool{
+	"image/jpeg": true, "image/png": true, "image/gif": true, "image/webp": true, "application/pdf": true,
+}
+
+const (
+	// messagePageSize caps how many messages one read returns.
+	messagePageSize = 200
+	// messagePollInterval is how often a waiting read re-checks the
+	// database, picking up messages sent through other instances.
+	messagePollInterval = 2 * time.Second
+)
+
+const messageColumns = `id, booking_id, sender_id, body, read_at, created_at, seq`
+
+func scanMessage(row rowScanner) (*models.Message, error) {
+	var m models.Message
+	var readAt sql.NullTime
+	if err := row.Scan(&m.ID, &m.BookingID, &m.SenderID, &m.Body, &readAt, &m.CreatedAt, &m.Seq); err != nil {
+		return nil, err
+	}
+	if readAt.Valid {
+		m.ReadAt = &readAt.Time
+	}
+	m.Attachments = []models.MessageAttachment{}
+	return &m, nil
+}
+
+const attachmentColumns = `id, message_id, filename, content_type, size`
+
+// MessageService keeps the message thread attached to each booking. Only
+// the booking's owner and provider can read or post to it.
+type MessageService struct {
+	db *sql.DB
+
+	mu      sync.Mutex
+	waiters map[uuid.UUID][]chan struct{}
+}
+
+func NewMessageService(db *sql.DB) *MessageService {
+	return &MessageService{db: db, waiters: make(map[uuid.UUID][]chan struct{})}
+}
+
+func (s *MessageService) authorize(bookingID, userID uuid.UUID) (*models.Booking, error) {
+	booking, err := getBookingRow(s.db, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID && booking.ProviderID != userID {
+		return nil, ErrBookingNotFound
+	}
+	return booking, nil
+}
+
+// List returns the thread's messages with a Seq above after, oldest first.
+// With a positive wait it long-polls: if there is nothing new it blocks
+// until a message arrives, wait elapses or ctx is cancelled.
+func (s *MessageService) List(ctx context.Context, bookingID, userID uuid.UUID, after int64, wait time.Duration) ([]models.Message, error) {
+	if _, err := s.authorize(bookingID, userID); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		// Subscribing before reading means a message sent in between still
+		// wakes us.
+		wake := s.subscribe(bookingID)
+		messages, err := s.after(bookingID, after)
+		remaining := time.Until(deadline)
+		if err != nil || len(messages) > 0 || remaining <= 0 {
+			s.unsubscribe(bookingID, wake)
+			return messages, err
+		}
+
+		timer := time.NewTimer(min(remaining, messagePollInterval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.unsubscribe(bookingID, wake)
+			return messages, nil
+		case <-wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+		s.unsubscribe(bookingID, wake)
+	}
+}
+
+func (s *MessageService) after(bookingID uuid.UUID, after int64) ([]models.Message, error) {
+	rows, err := s.db.Query(`
+		SELECT `+messageColumns+` FROM booking_messages
+		WHERE booking_id = $1 AND seq > $2
+		ORDER BY seq
+		LIMIT $3`, bookingID, after, messagePageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []models.Message{}
+	index := make(map[uuid.UUID]int)
+	ids := []string{}
+	for rows.Next() {
+		m, err := scanMessage(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		index[m.ID] = len(messages)
+		ids = append(ids, m.ID.String())
+		messages = append(messages, *m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(messages) == 0 {
+		return messages, err
+	}
+
+	rows, err = s.db.Query(`
+		SELECT `+attachmentColumns+` FROM message_attachments
+		WHERE message_id = ANY($1::uuid[]) ORDER BY created_at`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a models.MessageAttachment
+		if err := rows.Scan(&a.ID, &a.MessageID, &a.Filename, &a.ContentType, &a.Size); err != nil {
+			return nil, err
+		}
+		m := &messages[index[a.MessageID]]
+		m.Attachments = append(m.Attachments, a)
+	}
+	return messages, rows.Err()
+}
+
+// Send posts a message to the thread. Attachment types are sniffed from
+// their content rather than trusted from the upload. Sends to one thread
+// are serialised, so messages commit in Seq order and a reader that has
+// seen a message has seen every one before it.
+func (s *MessageService) Send(bookingID, userID uuid.UUID, body string, files []models.NewAttachment) (*models.Message, error) {
+	if _, err := s.authorize(bookingID, userID); err != nil {
+		return nil, err
+	}
+
+	body = strings.TrimSpace(body)
+	if body == "" && len(files) == 0 {
+		return nil, ErrEmptyMessage
+	}
+	if len(files) > models.MaxMessageAttachments {
+		return nil, ErrTooManyAttachments
+	}
+	for i := range files {
+		if len(files[i].Data) > models.MaxAttachmentSize {
+			return nil, ErrAttachmentTooLarge
+		}
+		files[i].ContentType = strings.TrimSpace(strings.Split(http.DetectContentType(files[i].Data), ";")[0])
+		if !allowedAttachmentTypes[files[i].ContentType] {
+			return nil, ErrAttachmentType
+		}
+	}
+
+	message := &models.Message{
+		ID:          uuid.New(),
+		BookingID:   bookingID,
+		SenderID:    userID,
+		Body:        body,
+		Attachments: []models.MessageAttachment{},
+		CreatedAt:   time.Now(),
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT 1 FROM bookings WHERE id = $1 FOR NO KEY UPDATE`, bookingID); err != nil {
+		return nil, err
+	}
+	if err := tx.QueryRow(`
+		INSERT INTO booking_messages (id, booking_id, sender_id, body, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING seq`,
+		message.ID, message.BookingID, message.SenderID, message.Body, message.CreatedAt).Scan(&message.Seq); err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		a := models.MessageAttachment{
+			ID:          uuid.New(),
+			MessageID:   message.ID,
+			Filename:    file.Filename,
+			ContentType: file.ContentType,
+			Size:        int64(len(file.Data)),
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO message_attachments (`+attachmentColumns+`, data, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			a.ID, a.MessageID, a.Filename, a.ContentType, a.Size, file.Data, message.CreatedAt); err != nil {
+			return nil, err
+		}
+		message.Attachments = append(message.Attachments, a)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	s.wake(bookingID)
+	return message, nil
+}
+
+// MarkRead records that userID has read every message the other participant
+// has sent so far, returning how many were newly read.
+func (s *MessageService) MarkRead(bookingID, userID uuid.UUID) (int, error) {
+	if _, err := s.authorize(bookingID, userID); err != nil {
+		return 0, err
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE booking_messages SET read_at = $1
+		WHERE booking_id = $2 AND sender_id <> $3 AND read_at IS NULL`, time.Now(), bookingID, userID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// Unread counts the messages waiting for userID across all their bookings.
+func (s *MessageService) Unread(userID uuid.UUID) (*models.UnreadSummary, error) {
+	rows, err := s.db.Query(`
+		SELECT m.booking_id, COUNT(*) FROM booking_messages m
+		JOIN bookings b ON b.id = m.booking_id
+		WHERE (b.user_id = $1 OR b.provider_id = $1) AND m.sender_id <> $1 AND m.read_at IS NULL
+		GROUP BY m.booking_id
+		ORDER BY MAX(m.created_at) DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summary := &models.UnreadSummary{Bookings: []models.UnreadCount{}}
+	for rows.Next() {
+		var count models.UnreadCount
+		if err := rows.Scan(&count.BookingID, &count.Unread); err != nil {
+			return nil, err
+		}
+		summary.Total += count.Unread
+		summary.Bookings = append(summary.Bookings, count)
+	}
+	return summary, rows.Err()
+}
+
+// Attachment returns an attachment's description and content.
+func (s *MessageService) Attachment(bookingID, attachmentID, userID uuid.UUID) (*models.MessageAttachment, []byte, error) {
+	if _, err := s.authorize(bookingID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	var a models.MessageAttachment
+	var data []byte
+	err := s.db.QueryRow(`
+		SELECT a.id, a.message_id, a.filename, a.content_type, a.size, a.data
+		FROM message_attachments a
+		JOIN booking_messages m ON m.id = a.message_id
+		WHERE a.id = $1 AND m.booking_id = $2`, attachmentID, bookingID).Scan(
+		&a.ID, &a.MessageID, &a.Filename, &a.ContentType, &a.Size, &data)
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &a, data, nil
+}
+
+func (s *MessageService) subscribe(bookingID uuid.UUID) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch := make(chan struct{})
+	s.waiters[bookingID] = append(s.waiters[bookingID], ch)
+	return ch
+}
+
+func (s *MessageService) unsubscribe(bookingID uuid.UUID, ch chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	waiters := s.waiters[bookingID]
+	for i, w := range waiters {
+		if w == ch {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(s.waiters, bookingID)
+	} else {
+		s.waiters[bookingID] = waiters
+	}
+}
+
+// wake releases every read waiting on the booking's thread in this
+// instance.
+func (s *MessageService) wake(bookingID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ch := range s.waiters[bookingID] {
+		close(ch)
+	}
+	delete(s.waiters, bookingID)
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"pet-grooming-app/internal/models"
+)
+
+func TestMessageCursor(t *testing.T) {
+	db := openTestDB(t)
+	s := NewMessageService(db)
+	booking := seedBooking(t, db, models.ServiceGrooming,
+		models.NewMoney(5000, "USD"), models.Zero("USD"), "")
+
+	first, err := s.Send(booking.ID, booking.UserID, "Is 10:00 still fine?", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.Send(booking.ID, booking.ProviderID, "Yes, see you then", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Seq <= first.Seq {
+		t.Fatalf("seq went from %d to %d", first.Seq, second.Seq)
+	}
+
+	ctx := context.Background()
+	all, err := s.List(ctx, booking.ID, booking.UserID, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].ID != first.ID || all[1].ID != second.ID {
+		t.Fatalf("List from the start = %+v, want both messages in order", all)
+	}
+
+	newer, err := s.List(ctx, booking.ID, booking.ProviderID, first.Seq, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newer) != 1 || newer[0].ID != second.ID {
+		t.Errorf("List after the first = %+v, want only the second", newer)
+	}
+}
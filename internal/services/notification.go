@@ -0,0 +1,450 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/notify"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrInvalidTimeZone     = errors.New("unknown time zone")
+	ErrInvalidQuietHours   = errors.New("quiet hours need both a start and an end")
+	ErrInvalidPreference   = errors.New("unknown notification event or channel")
+	ErrUnsupportedLanguage = errors.New("unsupported language")
+)
+
+const (
+	// notificationBatchSize caps how many outbox rows one dispatch pass
+	// claims.
+	notificationBatchSize = 50
+	// notificationLease is how long a claimed row is hidden from other
+	// dispatchers; a row whose sender crashed mid-send is retried after it.
+	notificationLease = 2 * time.Minute
+	// maxNotificationAttempts is when delivery is given up on.
+	maxNotificationAttempts = 5
+)
+
+const notificationColumns = `id, user_id, booking_id, event, channel, recipient, subject, body, status, attempts, last_error, next_attempt_at, created_at, sent_at`
+
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	var n models.Notification
+	var bookingID uuid.NullUUID
+	var sentAt sql.NullTime
+	err := row.Scan(
+		&n.ID, &n.UserID, &bookingID, &n.Event, &n.Channel, &n.Recipient, &n.Subject, &n.Body,
+		&n.Status, &n.Attempts, &n.LastError, &n.NextAttemptAt, &n.CreatedAt, &sentAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if bookingID.Valid {
+		n.BookingID = &bookingID.UUID
+	}
+	if sentAt.Valid {
+		n.SentAt = &sentAt.Time
+	}
+	return &n, nil
+}
+
+const notificationPreferencesColumns = `user_id, muted_events, channels, language, time_zone, quiet_hours_start, quiet_hours_end, updated_at`
+
+func scanNotificationPreferences(row rowScanner) (*models.NotificationPreferences, error) {
+	var prefs models.NotificationPreferences
+	var mutedEvents, channels []string
+	var quietStart, quietEnd sql.NullInt64
+	err := row.Scan(
+		&prefs.UserID, pq.Array(&mutedEvents), pq.Array(&channels), &prefs.Language, &prefs.TimeZone,
+		&quietStart, &quietEnd, &prefs.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	prefs.MutedEvents = make([]models.NotificationEvent, len(mutedEvents))
+	for i, e := range mutedEvents {
+		prefs.MutedEvents[i] = models.NotificationEvent(e)
+	}
+	prefs.Channels = make([]models.NotificationChannel, len(channels))
+	for i, c := range channels {
+		prefs.Channels[i] = models.NotificationChannel(c)
+	}
+	if quietStart.Valid && quietEnd.Valid {
+		start, end := int(quietStart.Int64), int(quietEnd.Int64)
+		prefs.QuietHoursStart = &start
+		prefs.QuietHoursEnd = &end
+	}
+	return &prefs, nil
+}
+
+// getNotificationPreferences returns the user's preferences, or the defaults
+// if they have never set any.
+func getNotificationPreferences(q queryer, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	prefs, err := scanNotificationPreferences(q.QueryRow(`
+		SELECT `+notificationPreferencesColumns+` FROM notification_preferences WHERE user_id = $1`, userID))
+	if err == sql.ErrNoRows {
+		return models.DefaultNotificationPreferences(userID), nil
+	}
+	return prefs, err
+}
+
+type contact struct {
+	id    uuid.UUID
+	name  string
+	email string
+	phone string
+	prefs *models.NotificationPreferences
+}
+
+func getContact(q queryer, id uuid.UUID) (*contact, error) {
+	c := &contact{id: id}
+	var firstName, lastName string
+	err := q.QueryRow(`SELECT first_name, last_name, email, COALESCE(phone, '') FROM users WHERE id = $1`, id).Scan(
+		&firstName, &lastName, &c.email, &c.phone)
+	if err != nil {
+		return nil, err
+	}
+	c.name = strings.TrimSpace(firstName + " " + lastName)
+	c.prefs, err = getNotificationPreferences(q, id)
+	return c, err
+}
+
+// notifyBooking renders event for everyone it concerns and adds the messages
+// to the outbox through q, normally the transaction making the change. data
+// carries the event-specific fields; names and the time are filled in here.
+// Owners hear about everything; providers about everything except the
+// confirmations they make themselves and reminders.
+func notifyBooking(q queryer, event models.NotificationEvent, booking *models.Booking, data notify.BookingData) error {
+	owner, err := getContact(q, booking.UserID)
+	if err != nil {
+		return err
+	}
+	provider, err := getContact(q, booking.ProviderID)
+	if err != nil {
+		return err
+	}
+	if err := q.QueryRow(`
+		SELECT p.name, s.name FROM pets p, services s
+		WHERE p.id = $1 AND s.id = $2`, booking.PetID, booking.ServiceID).Scan(&data.PetName, &data.ServiceName); err != nil {
+		return err
+	}
+	data.OwnerName = owner.name
+	data.ProviderName = provider.name
+	data.ScheduledTime = booking.ScheduledTime
+
+	recipients := []*contact{owner, provider}
+	if event == models.EventBookingConfirmed || event == models.EventBookingReminder {
+		recipients = recipients[:1]
+	}
+	for _, recipient := range recipients {
+		data.RecipientName = recipient.name
+		if err := enqueueNotification(q, recipient, event, &booking.ID, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueueNotification writes one outbox row per channel the recipient wants
+// the event on and can be reached on. Messages are written in the
+// recipient's language and time zone, and SMS and push messages are held
+// until their quiet hours end; reminders that would be held past the
+// appointment are dropped on those channels. Every sender reads from the
+// outbox, so this is where preferences are applied.
+func enqueueNotification(q queryer, recipient *contact, event models.NotificationEvent, bookingID *uuid.UUID, data notify.BookingData) error {
+	loc := recipient.prefs.Location()
+	data.ScheduledTime = data.ScheduledTime.In(loc)
+	data.PreviousTime = data.PreviousTime.In(loc)
+	data.HoldExpiresAt = data.HoldExpiresAt.In(loc)
+
+	rendered, err := notify.Render(string(event), recipient.prefs.Language, data)
+	if err != nil {
+		return err
+	}
+
+	messages := map[models.NotificationChannel]notify.Message{
+		models.ChannelPush: {To: recipient.id.String(), Subject: rendered.Subject, Body: rendered.Short},
+	}
+	if recipient.email != "" {
+		messages[models.ChannelEmail] = notify.Message{To: recipient.email, Subject: rendered.Subject, Body: rendered.Body}
+	}
+	if recipient.phone != "" {
+		messages[models.ChannelSMS] = notify.Message{To: recipient.phone, Body: rendered.Short}
+	}
+
+	now := time.Now()
+	for channel, msg := range messages {
+		if !recipient.prefs.Wants(event, channel) {
+			continue
+		}
+		due, ok := deliverAt(recipient.prefs, event, channel, now, data.ScheduledTime)
+		if !ok {
+			continue
+		}
+		_, err := q.Exec(`
+			INSERT INTO notifications (`+notificationColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 0, '', $10, $11, NULL)`,
+			uuid.New(), recipient.id, bookingID, event, channel, msg.To, msg.Subject, msg.Body,
+			models.NotificationPending, due, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliverAt returns when a message queued at now goes out on channel: at
+// once by email, and after the recipient's quiet hours by SMS and push. It
+// reports false for a reminder that quiet hours would hold until the
+// booking has started.
+func deliverAt(prefs *models.NotificationPreferences, event models.NotificationEvent, channel models.NotificationChannel, now, scheduled time.Time) (time.Time, bool) {
+	if channel == models.ChannelEmail {
+		return now, true
+	}
+	due := prefs.QuietUntil(now)
+	if event == models.EventBookingReminder && !due.Before(scheduled) {
+		return due, false
+	}
+	return due, true
+}
+
+// NotificationService delivers the outbox through the configured senders.
+type NotificationService struct {
+	db    *sql.DB
+	email notify.EmailSender
+	sms   notify.SMSSender
+	push  notify.PushSender
+}
+
+func NewNotificationService(db *sql.DB, email notify.EmailSender, sms notify.SMSSender, push notify.PushSender) *NotificationService {
+	return &NotificationService{db: db, email: email, sms: sms, push: push}
+}
+
+// List returns the user's most recent notifications.
+func (s *NotificationService) List(userID uuid.UUID) ([]models.Notification, error) {
+	rows, err := s.db.Query(`
+		SELECT `+notificationColumns+` FROM notifications
+		WHERE user_id = $1 ORDER BY created_at DESC LIMIT 100`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, *n)
+	}
+	return notifications, rows.Err()
+}
+
+// GetPreferences returns the user's notification preferences.
+func (s *NotificationService) GetPreferences(userID uuid.UUID) (*models.NotificationPreferences, error) {
+	return getNotificationPreferences(s.db, userID)
+}
+
+// UpdatePreferences changes the fields set in req, keeping the rest.
+func (s *NotificationService) UpdatePreferences(userID uuid.UUID, req models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferences, error) {
+	prefs, err := getNotificationPreferences(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.MutedEvents != nil {
+		for _, event := range *req.MutedEvents {
+			if !event.IsValid() {
+				return nil, ErrInvalidPreference
+			}
+		}
+		prefs.MutedEvents = *req.MutedEvents
+	}
+	if req.Channels != nil {
+		for _, channel := range *req.Channels {
+			if !channel.IsValid() {
+				return nil, ErrInvalidPreference
+			}
+		}
+		prefs.Channels = *req.Channels
+	}
+	if req.Language != nil {
+		if !notify.SupportsLanguage(*req.Language) {
+			return nil, ErrUnsupportedLanguage
+		}
+		prefs.Language = *req.Language
+	}
+	if req.TimeZone != nil {
+		if _, err := time.LoadLocation(*req.TimeZone); err != nil || *req.TimeZone == "" {
+			return nil, ErrInvalidTimeZone
+		}
+		prefs.TimeZone = *req.TimeZone
+	}
+	if req.QuietHoursStart != nil {
+		prefs.QuietHoursStart = req.QuietHoursStart
+	}
+	if req.QuietHoursEnd != nil {
+		prefs.QuietHoursEnd = req.QuietHoursEnd
+	}
+	if (prefs.QuietHoursStart == nil) != (prefs.QuietHoursEnd == nil) {
+		return nil, ErrInvalidQuietHours
+	}
+	prefs.UpdatedAt = time.Now()
+
+	mutedEvents := make([]string, len(prefs.MutedEvents))
+	for i, e := range prefs.MutedEvents {
+		mutedEvents[i] = string(e)
+	}
+	channels := make([]string, len(prefs.Channels))
+	for i, c := range prefs.Channels {
+		channels[i] = string(c)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO notification_preferences (`+notificationPreferencesColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+			muted_events = EXCLUDED.muted_events,
+			channels = EXCLUDED.channels,
+			language = EXCLUDED.language,
+			time_zone = EXCLUDED.time_zone,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			updated_at = EXCLUDED.updated_at`,
+		prefs.UserID, pq.Array(mutedEvents), pq.Array(channels), prefs.Language, prefs.TimeZone,
+		prefs.QuietHoursStart, prefs.QuietHoursEnd, prefs.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// Run dispatches the outbox every interval until ctx is cancelled.
+func (s *NotificationService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Dispatch(ctx); err != nil {
+			log.Printf("Failed to dispatch notifications: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch claims a batch of due notifications and sends the ones their
+// recipients still want, returning how many were delivered. Claiming pushes
+// next_attempt_at out by the lease, so any number of instances can dispatch
+// without sending a row twice.
+func (s *NotificationService) Dispatch(ctx context.Context) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE notifications SET attempts = attempts + 1, next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+notificationColumns, time.Now().Add(notificationLease), notificationBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var claimed []*models.Notification
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		claimed = append(claimed, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	prefs := make(map[uuid.UUID]*models.NotificationPreferences)
+	for _, n := range claimed {
+		p, ok := prefs[n.UserID]
+		if !ok {
+			if p, err = getNotificationPreferences(s.db, n.UserID); err != nil {
+				return sent, err
+			}
+			prefs[n.UserID] = p
+		}
+		// Preferences may have changed since the message was queued, for
+		// example while it was held for quiet hours.
+		if !p.Wants(n.Event, n.Channel) {
+			if err := s.skip(n); err != nil {
+				return sent, err
+			}
+			continue
+		}
+
+		sendErr := s.send(ctx, n)
+		if sendErr == nil {
+			sent++
+		}
+		if err := s.record(n, sendErr); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+func (s *NotificationService) send(ctx context.Context, n *models.Notification) error {
+	msg := notify.Message{To: n.Recipient, Subject: n.Subject, Body: n.Body}
+	switch n.Channel {
+	case models.ChannelEmail:
+		return s.email.SendEmail(ctx, msg)
+	case models.ChannelSMS:
+		return s.sms.SendSMS(ctx, msg)
+	case models.ChannelPush:
+		return s.push.SendPush(ctx, msg)
+	}
+	return notify.ErrUndeliverable
+}
+
+// skip marks a message the recipient no longer wants as not to be sent.
+func (s *NotificationService) skip(n *models.Notification) error {
+	n.Status = models.NotificationSkipped
+	_, err := s.db.Exec(`UPDATE notifications SET status = $1 WHERE id = $2`, n.Status, n.ID)
+	return err
+}
+
+// record stores the outcome of a delivery attempt. Failures are retried with
+// exponential backoff unless the recipient is unreachable or the attempts
+// are used up.
+func (s *NotificationService) record(n *models.Notification, sendErr error) error {
+	now := time.Now()
+	switch {
+	case sendErr == nil:
+		n.Status = models.NotificationSent
+		n.SentAt = &now
+		n.LastError = ""
+	case errors.Is(sendErr, notify.ErrUndeliverable) || n.Attempts >= maxNotificationAttempts:
+		n.Status = models.NotificationFailed
+		n.LastError = sendErr.Error()
+	default:
+		n.NextAttemptAt = now.Add(time.Minute << (n.Attempts - 1))
+		n.LastError = sendErr.Error()
+	}
+
+	_, err := s.db.Exec(`
+		UPDATE notifications SET status = $1, last_error = $2, next_attempt_at = $3, sent_at = $4
+		WHERE id = $5`, n.Status, n.LastError, n.NextAttemptAt, n.SentAt, n.ID)
+	return err
+}
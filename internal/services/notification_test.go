@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"pet-grooming-app/internal/models"
+)
+
+func TestDeliverAt(t *testing.T) {
+	start, end := 22, 7
+	prefs := &models.NotificationPreferences{TimeZone: "UTC", QuietHoursStart: &start, QuietHoursEnd: &end}
+	night := time.Date(2026, 3, 14, 23, 0, 0, 0, time.UTC)
+	morning := time.Date(2026, 3, 15, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		event     models.NotificationEvent
+		channel   models.NotificationChannel
+		scheduled time.Time
+		want      time.Time
+		ok        bool
+	}{
+		{"email ignores quiet hours", models.EventBookingReminder, models.ChannelEmail, night.Add(2 * time.Hour), night, true},
+		{"sms waits for morning", models.EventBookingConfirmed, models.ChannelSMS, night.Add(2 * time.Hour), morning, true},
+		{"reminder held until after start", models.EventBookingReminder, models.ChannelSMS, night.Add(2 * time.Hour), morning, false},
+		{"reminder held until before start", models.EventBookingReminder, models.ChannelPush, morning.Add(time.Hour), morning, true},
+	}
+	for _, tt := range tests {
+		got, ok := deliverAt(prefs, tt.event, tt.channel, night, tt.scheduled)
+		if ok != tt.ok || (ok && !got.Equal(tt.want)) {
+			t.Errorf("%s: deliverAt = %s, %v; want %s, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPackageNotFound        = errors.New("package not found")
+	ErrInvalidPackage         = errors.New("packages need at least one credit and memberships none")
+	ErrInvalidPackagePrice    = errors.New("a package's price cannot be negative")
+	ErrInvalidPackageCategory = errors.New("unknown service category")
+	ErrCreditNotFound         = errors.New("credit not found")
+	ErrCreditNotApplicable    = errors.New("this credit does not cover the selected service or date")
+	ErrCreditsExhausted       = errors.New("no credits left on this package")
+)
+
+const packageColumns = `id, provider_id, name, description, kind, category, credits, price, currency, validity_days, active, created_at, updated_at`
+
+func scanPackage(row rowScanner) (*models.Package, error) {
+	var pkg models.Package
+	err := row.Scan(
+		&pkg.ID, &pkg.ProviderID, &pkg.Name, &pkg.Description, &pkg.Kind, &pkg.Category, &pkg.Credits,
+		&pkg.Price.Amount, &pkg.Price.Currency, &pkg.ValidityDays, &pkg.Active, &pkg.CreatedAt, &pkg.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &pkg, nil
+}
+
+const creditColumns = `id, user_id, package_id, provider_id, name, kind, category, credits_remaining, price_paid, currency, transaction_id, purchased_at, expires_at`
+
+func scanCredit(row rowScanner) (*models.Credit, error) {
+	var credit models.Credit
+	var remaining sql.NullInt64
+	err := row.Scan(
+		&credit.ID, &credit.UserID, &credit.PackageID, &credit.ProviderID, &credit.Name, &credit.Kind,
+		&credit.Category, &remaining, &credit.PricePaid.Amount, &credit.PricePaid.Currency,
+		&credit.TransactionID, &credit.PurchasedAt, &credit.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if remaining.Valid {
+		n := int(remaining.Int64)
+		credit.CreditsRemaining = &n
+	}
+	return &credit, nil
+}
+
+// lockCredit loads one of the owner's credits and locks it for the rest of
+// tx, so concurrent bookings cannot spend the same visit twice.
+func lockCredit(tx *sql.Tx, id, userID uuid.UUID) (*models.Credit, error) {
+	credit, err := scanCredit(tx.QueryRow(`
+		SELECT `+creditColumns+` FROM credits
+		WHERE id = $1 AND user_id = $2 FOR UPDATE`, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrCreditNotFound
+	}
+	return credit, err
+}
+
+// redeemCredit spends one visit of credit on a booking for service at `at`.
+// Memberships are only checked for validity.
+func redeemCredit(tx *sql.Tx, credit *models.Credit, service *models.Service, at time.Time) error {
+	if credit.ProviderID != service.ProviderID || !credit.Covers(service.Category, at) {
+		return ErrCreditNotApplicable
+	}
+	if credit.Exhausted() {
+		return ErrCreditsExhausted
+	}
+	if credit.CreditsRemaining == nil {
+		return nil
+	}
+
+	*credit.CreditsRemaining--
+	_, err := tx.Exec(`UPDATE credits SET credits_remaining = $1 WHERE id = $2`, *credit.CreditsRemaining, credit.ID)
+	return err
+}
+
+// restoreCredit gives back the visit a cancelled booking was redeemed
+// against.
+func restoreCredit(tx *sql.Tx, id uuid.UUID) error {
+	_, err := tx.Exec(`
+		UPDATE credits SET credits_remaining = credits_remaining + 1
+		WHERE id = $1 AND credits_remaining IS NOT NULL`, id)
+	return err
+}
+
+// PackageService manages the prepaid packages and memberships providers sell
+// and the credits owners buy with them.
+type PackageService struct {
+	db       *sql.DB
+	payments *PaymentService
+}
+
+func NewPackageService(db *sql.DB, payments *PaymentService) *PackageService {
+	return &PackageService{db: db, payments: payments}
+}
+
+// Create adds a package to the provider's catalogue.
+func (s *PackageService) Create(providerID uuid.UUID, req models.CreatePackageRequest) (*models.Package, error) {
+	if err := requireProvider(s.db, providerID); err != nil {
+		return nil, err
+	}
+	if (req.Kind == models.PackageKindBundle) != (req.Credits > 0) {
+		return nil, ErrInvalidPackage
+	}
+	if req.Price.IsNegative() {
+		return nil, ErrInvalidPackagePrice
+	}
+	// An empty category covers every service; any other has to be one a
+	// service can have, or the credits could never be used.
+	if req.Category != "" && !req.Category.IsValid() {
+		return nil, ErrInvalidPackageCategory
+	}
+
+	now := time.Now()
+	pkg := &models.Package{
+		ID:           uuid.New(),
+		ProviderID:   providerID,
+		Name:         req.Name,
+		Description:  req.Description,
+		Kind:         req.Kind,
+		Category:     req.Category,
+		Credits:      req.Credits,
+		Price:        req.Price,
+		ValidityDays: req.ValidityDays,
+		Active:       true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	pkg.Price.Currency = models.NormalizeCurrency(pkg.Price.Currency)
+
+	_, err := s.db.Exec(`
+		INSERT INTO packages (`+packageColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		pkg.ID, pkg.ProviderID, pkg.Name, pkg.Description, pkg.Kind, pkg.Category, pkg.Credits,
+		pkg.Price.Amount, pkg.Price.Currency, pkg.ValidityDays, pkg.Active, pkg.CreatedAt, pkg.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// List returns a provider's packages. Only active ones are included unless
+// all is set.
+func (s *PackageService) List(providerID uuid.UUID, all bool) ([]models.Package, error) {
+	rows, err := s.db.Query(`
+		SELECT `+packageColumns+` FROM packages
+		WHERE provider_id = $1 AND (active OR $2)
+		ORDER BY created_at`, providerID, all)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	packages := []models.Package{}
+	for rows.Next() {
+		pkg, err := scanPackage(rows)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, *pkg)
+	}
+	return packages, rows.Err()
+}
+
+// Deactivate withdraws a package from sale. Credits already bought remain
+// usable.
+func (s *PackageService) Deactivate(id, providerID uuid.UUID) error {
+	result, err := s.db.Exec(`
+		UPDATE packages SET active = FALSE, updated_at = $1
+		WHERE id = $2 AND provider_id = $3`, time.Now(), id, providerID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrPackageNotFound
+	}
+	return nil
+}
+
+// Purchase sells a package to an owner, charging its price before the
+// credit is created. If the credit cannot be recorded the charge is
+// refunded.
+func (s *PackageService) Purchase(id, userID uuid.UUID, req models.PurchasePackageRequest) (*models.Credit, error) {
+	pkg, err := scanPackage(s.db.QueryRow(`SELECT `+packageColumns+` FROM packages WHERE id = $1 AND active`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrPackageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	credit := &models.Credit{
+		ID:          uuid.New(),
+		UserID:      userID,
+		PackageID:   pkg.ID,
+		ProviderID:  pkg.ProviderID,
+		Name:        pkg.Name,
+		Kind:        pkg.Kind,
+		Category:    pkg.Category,
+		PricePaid:   pkg.Price,
+		PurchasedAt: now,
+		ExpiresAt:   now.AddDate(0, 0, pkg.ValidityDays),
+	}
+	if pkg.Kind == models.PackageKindBundle {
+		credits := pkg.Credits
+		credit.CreditsRemaining = &credits
+	}
+
+	if pkg.Price.IsPositive() {
+		credit.TransactionID, err = s.payments.ChargePurchase(pkg.Price, req.PaymentMethod,
+			fmt.Sprintf("Package %s", pkg.Name), credit.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO credits (`+creditColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		credit.ID, credit.UserID, credit.PackageID, credit.ProviderID, credit.Name, credit.Kind,
+		credit.Category, credit.CreditsRemaining, credit.PricePaid.Amount, credit.PricePaid.Currency,
+		credit.TransactionID, credit.PurchasedAt, credit.ExpiresAt)
+	if err != nil {
+		if credit.TransactionID != "" {
+			if refundErr := s.payments.RefundPurchase(credit.TransactionID, pkg.Price); refundErr != nil {
+				log.Printf("Failed to refund unrecorded purchase of package %s (transaction %s): %v", pkg.ID, credit.TransactionID, refundErr)
+			}
+		}
+		return nil, err
+	}
+	return credit, nil
+}
+
+// ListCredits returns the owner's credits, unexpired ones first, soonest to
+// expire first.
+func (s *PackageService) ListCredits(userID uuid.UUID) ([]models.Credit, error) {
+	rows, err := s.db.Query(`
+		SELECT `+creditColumns+` FROM credits
+		WHERE user_id = $1
+		ORDER BY expires_at <= NOW(), expires_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credits := []models.Credit{}
+	for rows.Next() {
+		credit, err := scanCredit(rows)
+		if err != nil {
+			return nil, err
+		}
+		credits = append(credits, *credit)
+	}
+	return credits, rows.Err()
+}
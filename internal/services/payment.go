@@ -0,0 +1,397 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/payments"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPaymentMethodRequired = errors.New("a payment method is required for this booking")
+	ErrPaymentFailed         = errors.New("payment failed")
+	ErrRefundTooLarge        = errors.New("refund exceeds the amount collected")
+)
+
+const paymentColumns = `id, booking_id, kind, transaction_id, amount, refunded_amount, currency, status, failure_reason, created_at, updated_at`
+
+func scanPayment(row rowScanner) (*models.Payment, error) {
+	var payment models.Payment
+	var currency string
+	err := row.Scan(
+		&payment.ID, &payment.BookingID, &payment.Kind, &payment.TransactionID, &payment.Amount.Amount,
+		&payment.RefundedAmount.Amount, &currency, &payment.Status, &payment.FailureReason,
+		&payment.CreatedAt, &payment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	payment.Amount.Currency = currency
+	payment.RefundedAmount.Currency = currency
+	return &payment, nil
+}
+
+// PaymentService moves money for bookings through a payments.Provider and
+// keeps the booking's payment summary in step with the payments table.
+// Processor calls are made outside database transactions, after the booking
+// change they relate to has been committed.
+type PaymentService struct {
+	db       *sql.DB
+	provider payments.Provider
+}
+
+func NewPaymentService(db *sql.DB, provider payments.Provider) *PaymentService {
+	return &PaymentService{db: db, provider: provider}
+}
+
+// CollectDeposit charges the booking's deposit to its payment method.
+func (s *PaymentService) CollectDeposit(booking *models.Booking) error {
+	if !booking.DepositAmount.IsPositive() {
+		return nil
+	}
+	_, err := s.charge(booking, models.PaymentKindDeposit, booking.DepositAmount)
+	return err
+}
+
+// ChargeTip charges a tip to the booking's payment method.
+func (s *PaymentService) ChargeTip(booking *models.Booking, amount models.Money) (*models.Payment, error) {
+	return s.charge(booking, models.PaymentKindTip, amount)
+}
+
+// Settle brings the net amount collected for a booking to owed, charging the
+// shortfall or refunding the excess. It is used when a booking completes,
+// is cancelled (owed is the late-cancel fee) or is a no-show.
+func (s *PaymentService) Settle(booking *models.Booking, owed models.Money) error {
+	paid, err := s.netCollected(booking)
+	if err != nil {
+		return err
+	}
+
+	diff, err := owed.Sub(paid)
+	if err != nil {
+		return err
+	}
+	switch {
+	case diff.IsPositive():
+		if booking.PaymentMethod == "" {
+			return ErrPaymentMethodRequired
+		}
+		kind := models.PaymentKindBalance
+		if booking.Status == models.StatusCancelled || booking.Status == models.StatusNoShow {
+			kind = models.PaymentKindFee
+		}
+		_, err = s.charge(booking, kind, diff)
+	case diff.IsNegative():
+		_, err = s.refund(booking, models.NewMoney(-diff.Amount, diff.Currency))
+	}
+	return err
+}
+
+// settleAfterCommit runs Settle for a booking change that has already been
+// committed. Failures cannot undo that change, so they are logged and left
+// visible as a failed payment status for the provider to resolve. Bookings
+// with nothing collected and no payment method on file are settled in person.
+func (s *PaymentService) settleAfterCommit(booking *models.Booking, owed models.Money) {
+	if booking.PaymentMethod == "" && booking.AmountPaid.IsZero() {
+		return
+	}
+	if err := s.Settle(booking, owed); err != nil {
+		log.Printf("Failed to settle payment for booking %s: %v", booking.ID, err)
+		if _, dbErr := s.db.Exec(`UPDATE bookings SET payment_status = $1 WHERE id = $2`,
+			models.PaymentFailed, booking.ID); dbErr != nil {
+			log.Printf("Failed to mark payment failure for booking %s: %v", booking.ID, dbErr)
+		}
+	}
+}
+
+// Refund returns money to the owner at the provider's request, newest
+// payment first. A nil amount refunds everything collected.
+func (s *PaymentService) Refund(bookingID, providerID uuid.UUID, req models.RefundRequest) ([]models.Payment, error) {
+	booking, err := getBookingRow(s.db, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.ProviderID != providerID {
+		if booking.UserID == providerID {
+			return nil, ErrForbidden
+		}
+		return nil, ErrBookingNotFound
+	}
+
+	paid, err := s.netCollected(booking)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := paid
+	if req.Amount != nil {
+		amount = *req.Amount
+		amount.Currency = models.CurrencyOr(amount.Currency, paid.Currency)
+		if !amount.SameCurrency(paid) {
+			return nil, models.ErrCurrencyMismatch
+		}
+	}
+	if amount.IsNegative() || amount.Amount > paid.Amount {
+		return nil, ErrRefundTooLarge
+	}
+	if amount.IsPositive() {
+		if _, err := s.refund(booking, amount); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.listPayments(booking.ID)
+}
+
+// List returns the payments for a booking visible to userID.
+func (s *PaymentService) List(bookingID, userID uuid.UUID) ([]models.Payment, error) {
+	booking, err := getBookingRow(s.db, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID && booking.ProviderID != userID {
+		return nil, ErrBookingNotFound
+	}
+	return s.listPayments(booking.ID)
+}
+
+func getBookingRow(q queryer, id uuid.UUID) (*models.Booking, error) {
+	booking, err := scanBooking(q.QueryRow(`SELECT `+bookingColumns+` FROM bookings WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrBookingNotFound
+	}
+	return booking, err
+}
+
+// lockBooking loads a booking and locks its row for the rest of tx.
+func lockBooking(tx *sql.Tx, id uuid.UUID) (*models.Booking, error) {
+	booking, err := scanBooking(tx.QueryRow(`SELECT `+bookingColumns+` FROM bookings WHERE id = $1 FOR UPDATE`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrBookingNotFound
+	}
+	return booking, err
+}
+
+func (s *PaymentService) listPayments(bookingID uuid.UUID) ([]models.Payment, error) {
+	rows, err := s.db.Query(`
+		SELECT `+paymentColumns+` FROM payments
+		WHERE booking_id = $1 ORDER BY created_at`, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.Payment{}
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *payment)
+	}
+	return result, rows.Err()
+}
+
+func (s *PaymentService) netCollected(booking *models.Booking) (models.Money, error) {
+	paid := models.Zero(booking.TotalPrice.Currency)
+	err := s.db.QueryRow(`
+		SELECT COALESCE(SUM(amount - refunded_amount), 0) FROM payments
+		WHERE booking_id = $1 AND status <> 'failed' AND kind <> 'tip'`, booking.ID).Scan(&paid.Amount)
+	return paid, err
+}
+
+// charge collects amount for a booking, recording the attempt whether or not
+// it succeeds.
+func (s *PaymentService) charge(booking *models.Booking, kind models.PaymentKind, amount models.Money) (*models.Payment, error) {
+	now := time.Now()
+	payment := &models.Payment{
+		ID:             uuid.New(),
+		BookingID:      booking.ID,
+		Kind:           kind,
+		Amount:         amount,
+		RefundedAmount: models.Zero(amount.Currency),
+		Status:         models.PaymentRecordCaptured,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	var err error
+	payment.TransactionID, err = s.capture(amount, booking.PaymentMethod,
+		fmt.Sprintf("Booking %s %s", booking.ID, kind), payment.ID.String())
+	if err != nil {
+		payment.Status = models.PaymentRecordFailed
+		payment.FailureReason = err.Error()
+	}
+
+	if dbErr := insertPayment(s.db, payment); dbErr != nil {
+		return nil, dbErr
+	}
+	if dbErr := webhookPayment(s.db, booking, payment); dbErr != nil {
+		return nil, dbErr
+	}
+
+	if err != nil {
+		// A declined tip leaves the booking's own payments untouched.
+		if kind != models.PaymentKindTip {
+			if _, dbErr := s.db.Exec(`UPDATE bookings SET payment_status = $1 WHERE id = $2`,
+				models.PaymentFailed, booking.ID); dbErr != nil {
+				return nil, dbErr
+			}
+		}
+		return nil, fmt.Errorf("%w: %v", ErrPaymentFailed, err)
+	}
+
+	return payment, s.syncBooking(booking)
+}
+
+func insertPayment(q queryer, payment *models.Payment) error {
+	_, err := q.Exec(`
+		INSERT INTO payments (`+paymentColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		payment.ID, payment.BookingID, payment.Kind, payment.TransactionID, payment.Amount.Amount,
+		payment.RefundedAmount.Amount, payment.Amount.Currency, payment.Status, payment.FailureReason,
+		payment.CreatedAt, payment.UpdatedAt)
+	return err
+}
+
+// capture authorizes and immediately captures amount, voiding the
+// authorization if the capture fails. It returns the processor's transaction
+// ID, which is set whenever the authorization succeeded.
+func (s *PaymentService) capture(amount models.Money, paymentMethod, description, idempotencyKey string) (string, error) {
+	ctx := context.Background()
+	txn, err := s.provider.Authorize(ctx, payments.AuthorizeRequest{
+		Amount:         amount.Amount,
+		Currency:       amount.Currency,
+		PaymentMethod:  paymentMethod,
+		Description:    description,
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.provider.Capture(ctx, txn.ID, amount.Amount); err != nil {
+		if _, voidErr := s.provider.Void(ctx, txn.ID); voidErr != nil {
+			log.Printf("Failed to void transaction %s: %v", txn.ID, voidErr)
+		}
+		return txn.ID, err
+	}
+	return txn.ID, nil
+}
+
+// ChargePurchase takes payment for something not tied to a booking, such as
+// a prepaid package, and returns the processor's transaction ID.
+func (s *PaymentService) ChargePurchase(amount models.Money, paymentMethod, description string, key uuid.UUID) (string, error) {
+	txnID, err := s.capture(amount, paymentMethod, description, key.String())
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrPaymentFailed, err)
+	}
+	return txnID, nil
+}
+
+// RefundPurchase returns a purchase charged by ChargePurchase in full, for
+// when what was bought could not be recorded.
+func (s *PaymentService) RefundPurchase(txnID string, amount models.Money) error {
+	if _, err := s.provider.Refund(context.Background(), txnID, amount.Amount); err != nil {
+		return fmt.Errorf("%w: %v", ErrPaymentFailed, err)
+	}
+	return nil
+}
+
+// refund returns amount across the booking's captured payments, newest
+// first. Tips are not part of the booking's price and are left alone; gift
+// card payments go back onto the card.
+func (s *PaymentService) refund(booking *models.Booking, amount models.Money) ([]models.Payment, error) {
+	captured, err := s.listPayments(booking.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var refunded []models.Payment
+	remaining := amount
+	for i := len(captured) - 1; i >= 0 && remaining.IsPositive(); i-- {
+		payment := captured[i]
+		available, err := payment.Amount.Sub(payment.RefundedAmount)
+		if err != nil {
+			return refunded, err
+		}
+		if payment.Status == models.PaymentRecordFailed || payment.Kind == models.PaymentKindTip || !available.IsPositive() {
+			continue
+		}
+
+		part, err := available.Min(remaining)
+		if err != nil {
+			return refunded, err
+		}
+		if payment.Kind == models.PaymentKindGiftCard {
+			if err := refundToGiftCard(s.db, payment.TransactionID, part, booking.ID); err != nil {
+				return refunded, err
+			}
+		} else if _, err := s.provider.Refund(context.Background(), payment.TransactionID, part.Amount); err != nil {
+			return refunded, fmt.Errorf("%w: %v", ErrPaymentFailed, err)
+		}
+
+		payment.RefundedAmount.Amount += part.Amount
+		payment.Status = models.PaymentRecordPartiallyRefunded
+		if payment.RefundedAmount == payment.Amount {
+			payment.Status = models.PaymentRecordRefunded
+		}
+		payment.UpdatedAt = time.Now()
+
+		_, err = s.db.Exec(`UPDATE payments SET refunded_amount = $1, status = $2, updated_at = $3 WHERE id = $4`,
+			payment.RefundedAmount.Amount, payment.Status, payment.UpdatedAt, payment.ID)
+		if err != nil {
+			return refunded, err
+		}
+		if err := webhookPayment(s.db, booking, &payment); err != nil {
+			return refunded, err
+		}
+		refunded = append(refunded, payment)
+		remaining.Amount -= part.Amount
+	}
+
+	if remaining.IsPositive() {
+		return refunded, ErrRefundTooLarge
+	}
+	return refunded, s.syncBooking(booking)
+}
+
+// paymentStatus summarises what has been collected against a booking total.
+func paymentStatus(total, collected, refunded int64) models.PaymentStatus {
+	paid := collected - refunded
+	switch {
+	case refunded > 0 && paid <= 0:
+		return models.PaymentRefunded
+	case refunded > 0:
+		return models.PaymentPartiallyRefunded
+	case paid >= total:
+		return models.PaymentPaid
+	case paid > 0:
+		return models.PaymentDepositPaid
+	}
+	return models.PaymentUnpaid
+}
+
+// syncBooking recomputes the booking's amount paid and payment status from
+// its payments.
+func (s *PaymentService) syncBooking(booking *models.Booking) error {
+	var collected, refunded int64
+	err := s.db.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0), COALESCE(SUM(refunded_amount), 0) FROM payments
+		WHERE booking_id = $1 AND status <> 'failed' AND kind <> 'tip'`, booking.ID).Scan(&collected, &refunded)
+	if err != nil {
+		return err
+	}
+
+	booking.AmountPaid = models.NewMoney(collected-refunded, booking.TotalPrice.Currency)
+	booking.PaymentStatus = paymentStatus(booking.TotalPrice.Amount, collected, refunded)
+	_, err = s.db.Exec(`UPDATE bookings SET amount_paid = $1, payment_status = $2 WHERE id = $3`,
+		booking.AmountPaid.Amount, booking.PaymentStatus, booking.ID)
+	return err
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/payments"
+
+	"github.com/google/uuid"
+)
+
+func TestPaymentStatus(t *testing.T) {
+	tests := []struct {
+		total, collected, refunded int64
+		want                       models.PaymentStatus
+	}{
+		{8000, 0, 0, models.PaymentUnpaid},
+		{8000, 2000, 0, models.PaymentDepositPaid},
+		{8000, 8000, 0, models.PaymentPaid},
+		{0, 0, 0, models.PaymentPaid},
+		{8000, 8000, 3000, models.PaymentPartiallyRefunded},
+		{8000, 8000, 8000, models.PaymentRefunded},
+	}
+	for _, tt := range tests {
+		if got := paymentStatus(tt.total, tt.collected, tt.refunded); got != tt.want {
+			t.Errorf("paymentStatus(%d, %d, %d) = %s, want %s", tt.total, tt.collected, tt.refunded, got, tt.want)
+		}
+	}
+}
+
+func TestChargePurchase(t *testing.T) {
+	s := NewPaymentService(nil, payments.NewFakeProvider())
+
+	txnID, err := s.ChargePurchase(models.NewMoney(4500, "USD"), "tok_visa", "Package", uuid.New())
+	if err != nil || txnID == "" {
+		t.Fatalf("ChargePurchase = %q, %v", txnID, err)
+	}
+	if err := s.RefundPurchase(txnID, models.NewMoney(4500, "USD")); err != nil {
+		t.Errorf("RefundPurchase: %v", err)
+	}
+	if err := s.RefundPurchase(txnID, models.NewMoney(4500, "USD")); !errors.Is(err, ErrPaymentFailed) {
+		t.Errorf("second refund: %v, want ErrPaymentFailed", err)
+	}
+
+	_, err = s.ChargePurchase(models.NewMoney(4500, "USD"), payments.DeclinedPaymentMethod, "Package", uuid.New())
+	if !errors.Is(err, ErrPaymentFailed) {
+		t.Errorf("declined purchase: %v, want ErrPaymentFailed", err)
+	}
+}
+
+func TestDepositSettleRefund(t *testing.T) {
+	db := openTestDB(t)
+	s := NewPaymentService(db, payments.NewFakeProvider())
+	booking := seedBooking(t, db, models.ServiceGrooming,
+		models.NewMoney(8000, "USD"), models.NewMoney(2000, "USD"), "tok_visa")
+
+	if err := s.CollectDeposit(booking); err != nil {
+		t.Fatal(err)
+	}
+	if booking.PaymentStatus != models.PaymentDepositPaid || booking.AmountPaid.Amount != 2000 {
+		t.Fatalf("after deposit: %s, %s paid", booking.PaymentStatus, booking.AmountPaid)
+	}
+
+	// Completing the booking charges the balance.
+	if err := s.Settle(booking, booking.TotalPrice); err != nil {
+		t.Fatal(err)
+	}
+	if booking.PaymentStatus != models.PaymentPaid || booking.AmountPaid.Amount != 8000 {
+		t.Fatalf("after settle: %s, %s paid", booking.PaymentStatus, booking.AmountPaid)
+	}
+
+	// A partial refund comes off the newest payment, the balance.
+	amount := models.NewMoney(3000, "USD")
+	refunded, err := s.Refund(booking.ID, booking.ProviderID, models.RefundRequest{Amount: &amount})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refunded) != 2 {
+		t.Fatalf("got %d payments, want deposit and balance", len(refunded))
+	}
+	for _, p := range refunded {
+		want := int64(0)
+		if p.Kind == models.PaymentKindBalance {
+			want = 3000
+		}
+		if p.RefundedAmount.Amount != want {
+			t.Errorf("%s payment refunded %s, want %d", p.Kind, p.RefundedAmount, want)
+		}
+	}
+
+	tooMuch := models.NewMoney(5001, "USD")
+	if _, err := s.Refund(booking.ID, booking.ProviderID, models.RefundRequest{Amount: &tooMuch}); !errors.Is(err, ErrRefundTooLarge) {
+		t.Errorf("refund beyond collected: %v, want ErrRefundTooLarge", err)
+	}
+	if _, err := s.Refund(booking.ID, booking.UserID, models.RefundRequest{}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("refund by owner: %v, want ErrForbidden", err)
+	}
+
+	// Settling to nothing, as a free cancellation does, refunds the rest.
+	if err := s.Settle(booking, models.Zero("USD")); err != nil {
+		t.Fatal(err)
+	}
+	if booking.PaymentStatus != models.PaymentRefunded || !booking.AmountPaid.IsZero() {
+		t.Errorf("after full refund: %s, %s paid", booking.PaymentStatus, booking.AmountPaid)
+	}
+}
+
+func TestDeclinedDeposit(t *testing.T) {
+	db := openTestDB(t)
+	s := NewPaymentService(db, payments.NewFakeProvider())
+	booking := seedBooking(t, db, models.ServiceGrooming,
+		models.NewMoney(8000, "USD"), models.NewMoney(2000, "USD"), payments.DeclinedPaymentMethod)
+
+	if err := s.CollectDeposit(booking); !errors.Is(err, ErrPaymentFailed) {
+		t.Fatalf("CollectDeposit: %v, want ErrPaymentFailed", err)
+	}
+	recorded, err := s.List(booking.ID, booking.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != 1 || recorded[0].Status != models.PaymentRecordFailed {
+		t.Errorf("payments = %+v, want one failed attempt", recorded)
+	}
+	stored, err := getBookingRow(db, booking.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.PaymentStatus != models.PaymentFailed {
+		t.Errorf("payment status = %s, want failed", stored.PaymentStatus)
+	}
+}
+
+func TestRefundDefaultsToBookingCurrency(t *testing.T) {
+	db := openTestDB(t)
+	s := NewPaymentService(db, payments.NewFakeProvider())
+	booking := seedBooking(t, db, models.ServiceGrooming,
+		models.NewMoney(8000, "EUR"), models.NewMoney(2000, "EUR"), "tok_visa")
+	if err := s.CollectDeposit(booking); err != nil {
+		t.Fatal(err)
+	}
+
+	// An amount without a currency is in the booking's.
+	amount := models.Money{Amount: 500}
+	refunded, err := s.Refund(booking.ID, booking.ProviderID, models.RefundRequest{Amount: &amount})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refunded) != 1 || refunded[0].RefundedAmount != models.NewMoney(500, "EUR") {
+		t.Errorf("refunded %+v, want 5.00 EUR off the deposit", refunded)
+	}
+
+	usd := models.NewMoney(500, "USD")
+	if _, err := s.Refund(booking.ID, booking.ProviderID, models.RefundRequest{Amount: &usd}); !errors.Is(err, models.ErrCurrencyMismatch) {
+		t.Errorf("refund in another currency: %v, want ErrCurrencyMismatch", err)
+	}
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrPromoCodeNotFound  = errors.New("promo code not found")
+	ErrPromoCodeExists    = errors.New("a promo code with this code already exists")
+	ErrInvalidDiscount    = errors.New("percentage codes need a percent and fixed codes a positive amount")
+	ErrPromoCodeInvalid   = errors.New("promo code is invalid or has expired")
+	ErrPromoNotApplicable = errors.New("promo code does not apply to this booking")
+	ErrPromoCodeUsedUp    = errors.New("promo code has reached its usage limit")
+)
+
+const promoColumns = `id, provider_id, code, kind, percent, amount, min_spend, currency, valid_from, valid_until,
+	max_uses, uses, first_booking_only, categories, active, created_at, updated_at`
+
+func scanPromoCode(row rowScanner) (*models.PromoCode, error) {
+	var promo models.PromoCode
+	var currency string
+	var validFrom, validUntil sql.NullTime
+	var maxUses sql.NullInt64
+	var categories []string
+	err := row.Scan(
+		&promo.ID, &promo.ProviderID, &promo.Code, &promo.Kind, &promo.Percent, &promo.Amount.Amount,
+		&promo.MinSpend.Amount, &currency, &validFrom, &validUntil, &maxUses, &promo.Uses,
+		&promo.FirstBookingOnly, pq.Array(&categories), &promo.Active, &promo.CreatedAt, &promo.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	promo.Amount.Currency = currency
+	promo.MinSpend.Currency = currency
+	if validFrom.Valid {
+		promo.ValidFrom = &validFrom.Time
+	}
+	if validUntil.Valid {
+		promo.ValidUntil = &validUntil.Time
+	}
+	if maxUses.Valid {
+		n := int(maxUses.Int64)
+		promo.MaxUses = &n
+	}
+	promo.Categories = make([]models.ServiceType, len(categories))
+	for i, c := range categories {
+		promo.Categories[i] = models.ServiceType(c)
+	}
+	return &promo, nil
+}
+
+// normalizePromoCode makes codes case-insensitive.
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// applyPromoCode validates a code against a new booking for service listed
+// at amount before tax, all nights of a stay included, and returns it. Usage
+// is counted per booking by consumePromoCode.
+func applyPromoCode(q queryer, code string, userID uuid.UUID, service *models.Service, amount models.Money, recurring bool) (*models.PromoCode, error) {
+	promo, err := scanPromoCode(q.QueryRow(`
+		SELECT `+promoColumns+` FROM promo_codes
+		WHERE provider_id = $1 AND code = $2`, service.ProviderID, normalizePromoCode(code)))
+	if err == sql.ErrNoRows {
+		return nil, ErrPromoCodeInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !promo.ValidAt(time.Now()) {
+		return nil, ErrPromoCodeInvalid
+	}
+	if !promo.AppliesTo(service.Category, amount) {
+		return nil, ErrPromoNotApplicable
+	}
+
+	if promo.FirstBookingOnly {
+		// A first-booking discount covers a single booking, not a series.
+		if recurring {
+			return nil, ErrPromoNotApplicable
+		}
+		var previous int
+		err := q.QueryRow(`
+			SELECT COUNT(*) FROM bookings
+			WHERE user_id = $1 AND provider_id = $2 AND status <> $3`,
+			userID, service.ProviderID, models.StatusCancelled).Scan(&previous)
+		if err != nil {
+			return nil, err
+		}
+		if previous > 0 {
+			return nil, ErrPromoNotApplicable
+		}
+	}
+	return promo, nil
+}
+
+// consumePromoCode counts one use of promo. The conditional update is atomic,
+// so concurrent bookings cannot push a code past its limit.
+func consumePromoCode(tx *sql.Tx, promo *models.PromoCode) error {
+	err := tx.QueryRow(`
+		UPDATE promo_codes SET uses = uses + 1
+		WHERE id = $1 AND (max_uses IS NULL OR uses < max_uses)
+		RETURNING uses`, promo.ID).Scan(&promo.Uses)
+	if err == sql.ErrNoRows {
+		return ErrPromoCodeUsedUp
+	}
+	return err
+}
+
+// CreatePromoCode adds a discount code for the provider's services.
+func (s *BookingService) CreatePromoCode(providerID uuid.UUID, req models.CreatePromoCodeRequest) (*models.PromoCode, error) {
+	if err := requireProvider(s.db, providerID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	promo := &models.PromoCode{
+		ID:               uuid.New(),
+		ProviderID:       providerID,
+		Code:             normalizePromoCode(req.Code),
+		Kind:             req.Kind,
+		ValidFrom:        req.ValidFrom,
+		ValidUntil:       req.ValidUntil,
+		MaxUses:          req.MaxUses,
+		FirstBookingOnly: req.FirstBookingOnly,
+		Categories:       req.Categories,
+		Active:           true,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if promo.Categories == nil {
+		promo.Categories = []models.ServiceType{}
+	}
+
+	// A code's amounts share one currency: the fixed discount's, or the
+	// minimum spend's for percentage codes.
+	currency := models.DefaultCurrency
+	if req.MinSpend != nil && req.MinSpend.Currency != "" {
+		currency = models.NormalizeCurrency(req.MinSpend.Currency)
+	}
+	promo.Amount = models.Zero(currency)
+	switch req.Kind {
+	case models.DiscountPercentage:
+		if req.Percent <= 0 {
+			return nil, ErrInvalidDiscount
+		}
+		promo.Percent = req.Percent
+	case models.DiscountFixed:
+		if req.Amount == nil || !req.Amount.IsPositive() {
+			return nil, ErrInvalidDiscount
+		}
+		promo.Amount = models.NewMoney(req.Amount.Amount, models.NormalizeCurrency(req.Amount.Currency))
+		if req.MinSpend != nil && req.MinSpend.Currency != "" && promo.Amount.Currency != currency {
+			return nil, models.ErrCurrencyMismatch
+		}
+		currency = promo.Amount.Currency
+	}
+	promo.MinSpend = models.Zero(currency)
+	if req.MinSpend != nil {
+		promo.MinSpend.Amount = req.MinSpend.Amount
+	}
+
+	var exists bool
+	err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM promo_codes WHERE provider_id = $1 AND code = $2)`,
+		providerID, promo.Code).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrPromoCodeExists
+	}
+
+	categories := make([]string, len(promo.Categories))
+	for i, c := range promo.Categories {
+		categories[i] = string(c)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO promo_codes (`+promoColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`,
+		promo.ID, promo.ProviderID, promo.Code, promo.Kind, promo.Percent, promo.Amount.Amount,
+		promo.MinSpend.Amount, currency, promo.ValidFrom, promo.ValidUntil, promo.MaxUses, promo.Uses,
+		promo.FirstBookingOnly, pq.Array(categories), promo.Active, promo.CreatedAt, promo.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return promo, nil
+}
+
+// ListPromoCodes returns the provider's promo codes, newest first.
+func (s *BookingService) ListPromoCodes(providerID uuid.UUID) ([]models.PromoCode, error) {
+	if err := requireProvider(s.db, providerID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+promoColumns+` FROM promo_codes
+		WHERE provider_id = $1 ORDER BY created_at DESC`, providerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	codes := []models.PromoCode{}
+	for rows.Next() {
+		promo, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, *promo)
+	}
+	return codes, rows.Err()
+}
+
+// DeactivatePromoCode stops a code from being redeemed. Bookings that
+// already used it keep their discount.
+func (s *BookingService) DeactivatePromoCode(id, providerID uuid.UUID) error {
+	result, err := s.db.Exec(`
+		UPDATE promo_codes SET active = FALSE, updated_at = $1
+		WHERE id = $2 AND provider_id = $3`, time.Now(), id, providerID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrPromoCodeNotFound
+	}
+	return nil
+}
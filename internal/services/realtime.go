@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/realtime"
+
+	"github.com/google/uuid"
+)
+
+// publishBookingsAfterCommit tells each booking's owner and provider that it
+// changed. Clients treat events as hints to refresh, so a failed publish is
+// logged rather than undoing the change.
+func publishBookingsAfterCommit(events realtime.PubSub, kind string, bookings ...models.Booking) {
+	for i := range bookings {
+		booking := &bookings[i]
+		publishAfterCommit(events, kind, booking.ID, booking, booking.UserID, booking.ProviderID)
+	}
+}
+
+// publishAfterCommit sends data about the booking with id to users,
+// logging rather than returning failures.
+func publishAfterCommit(events realtime.PubSub, kind string, id uuid.UUID, data any, users ...uuid.UUID) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event for booking %s: %v", kind, id, err)
+		return
+	}
+	event := realtime.Event{
+		Type:    kind,
+		UserIDs: users,
+		Data:    encoded,
+		At:      time.Now(),
+	}
+	if err := events.Publish(context.Background(), event); err != nil {
+		log.Printf("Failed to publish %s event for booking %s: %v", kind, id, err)
+	}
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/notify"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidBookingLink = errors.New("link is invalid or has expired")
+
+// ReminderService sends reminders ahead of confirmed bookings and handles the
+// signed confirm and cancel links they carry.
+type ReminderService struct {
+	db       *sql.DB
+	bookings *BookingService
+	secret   []byte
+	baseURL  string
+}
+
+// NewReminderService signs links with secret and points them at baseURL, the
+// server's public address.
+func NewReminderService(db *sql.DB, bookings *BookingService, secret []byte, baseURL string) *ReminderService {
+	return &ReminderService{db: db, bookings: bookings, secret: secret, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// Run sends due reminders every interval until ctx is cancelled.
+func (s *ReminderService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.SendDue(ctx); err != nil {
+			log.Printf("Failed to send reminders: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue queues every reminder whose lead time has been reached and returns
+// how many were queued. Each reminder is sent from the window between its
+// own lead time and the next shorter one, so a booking made at short notice
+// only gets the reminders still ahead of it.
+func (s *ReminderService) SendDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	sent := 0
+	for i, reminder := range models.ReminderSchedule {
+		var next time.Duration
+		if i+1 < len(models.ReminderSchedule) {
+			next = models.ReminderSchedule[i+1].Lead
+		}
+
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT `+bookingColumns+` FROM bookings
+			WHERE status = $1 AND scheduled_time > $2 AND scheduled_time <= $3
+				AND NOT EXISTS (
+					SELECT 1 FROM booking_reminders r
+					WHERE r.booking_id = bookings.id AND r.kind = $4
+				)
+			ORDER BY scheduled_time`,
+			models.StatusConfirmed, now.Add(next), now.Add(reminder.Lead), reminder.Kind)
+		if err != nil {
+			return sent, err
+		}
+		due, err := scanBookings(rows)
+		if err != nil {
+			return sent, err
+		}
+
+		for i := range due {
+			ok, err := s.remind(&due[i], reminder.Kind)
+			if err != nil {
+				return sent, err
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// remind records and queues one reminder. The booking_reminders primary key
+// is the claim: whichever instance inserts the row sends the reminder, and
+// the others find it already taken.
+func (s *ReminderService) remind(booking *models.Booking, kind models.ReminderKind) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO booking_reminders (booking_id, kind, sent_at) VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING`, booking.ID, kind, time.Now())
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	data := notify.BookingData{
+		ConfirmURL: s.linkURL(booking, models.LinkConfirmAttendance),
+		CancelURL:  s.linkURL(booking, models.LinkCancelBooking),
+	}
+	if err := notifyBooking(tx, models.EventBookingReminder, booking, data); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// linkURL points at the page that shows the booking and asks the owner to
+// confirm the action; the app uses the same token with the JSON API.
+func (s *ReminderService) linkURL(booking *models.Booking, action models.BookingLinkAction) string {
+	return s.baseURL + "/booking-links/" + s.signLink(booking.ID, action, booking.ScheduledTime)
+}
+
+// signLink returns a token of the form id.action.expiry.signature, where the
+// signature is an HMAC-SHA256 of the rest. Links expire when the booking
+// starts.
+func (s *ReminderService) signLink(bookingID uuid.UUID, action models.BookingLinkAction, expires time.Time) string {
+	payload := fmt.Sprintf("%s.%s.%d", bookingID, action, expires.Unix())
+	return payload + "." + s.signature(payload)
+}
+
+func (s *ReminderService) signature(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parseLink checks a token's signature and expiry.
+func (s *ReminderService) parseLink(token string) (*models.BookingLink, error) {
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return nil, ErrInvalidBookingLink
+	}
+	payload, signature := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(s.signature(payload))) {
+		return nil, ErrInvalidBookingLink
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidBookingLink
+	}
+	bookingID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return nil, ErrInvalidBookingLink
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || time.Now().Unix() >= expires {
+		return nil, ErrInvalidBookingLink
+	}
+
+	link := &models.BookingLink{
+		Action:    models.BookingLinkAction(parts[1]),
+		BookingID: bookingID,
+		ExpiresAt: time.Unix(expires, 0),
+	}
+	if link.Action != models.LinkConfirmAttendance && link.Action != models.LinkCancelBooking {
+		return nil, ErrInvalidBookingLink
+	}
+	return link, nil
+}
+
+// InspectLink describes what a reminder link will do without doing it, so a
+// link opened by a mail scanner changes nothing.
+func (s *ReminderService) InspectLink(token string) (*models.BookingLink, error) {
+	link, err := s.parseLink(token)
+	if err != nil {
+		return nil, err
+	}
+	if link.Booking, err = getBookingRow(s.db, link.BookingID); err != nil {
+		return nil, err
+	}
+
+	provider, err := getContact(s.db, link.Booking.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	link.ProviderName = provider.name
+	if err := s.db.QueryRow(`
+		SELECT p.name, s.name FROM pets p, services s
+		WHERE p.id = $1 AND s.id = $2`, link.Booking.PetID, link.Booking.ServiceID).Scan(&link.PetName, &link.ServiceName); err != nil {
+		return nil, err
+	}
+	loc, err := providerLocation(s.db, link.Booking.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	link.LocalTime = link.Booking.ScheduledTime.In(loc)
+	return link, nil
+}
+
+// FollowLink performs a reminder link's action on the owner's behalf. Links
+// carry the owner's authority, so cancellations are subject to the same fees
+// as cancelling in the app.
+func (s *ReminderService) FollowLink(token string) (*models.BookingLink, error) {
+	link, err := s.InspectLink(token)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := link.Booking.UserID
+	switch link.Action {
+	case models.LinkConfirmAttendance:
+		link.Booking, err = s.bookings.ConfirmAttendance(link.BookingID, owner)
+	case models.LinkCancelBooking:
+		var result *models.CancellationResult
+		result, err = s.bookings.Cancel(link.BookingID, owner, models.CancelBookingRequest{
+			Reason: "Cancelled from reminder",
+			Scope:  models.ScopeThis,
+		})
+		if err == nil && len(result.Bookings) > 0 {
+			link.Booking = &result.Bookings[0]
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestBookingLinks(t *testing.T) {
+	s := NewReminderService(nil, nil, []byte("secret"), "https://example.com/")
+	bookingID := uuid.New()
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	token := s.signLink(bookingID, models.LinkCancelBooking, expires)
+	link, err := s.parseLink(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.BookingID != bookingID || link.Action != models.LinkCancelBooking || !link.ExpiresAt.Equal(expires) {
+		t.Errorf("parsed link = %+v", link)
+	}
+
+	booking := &models.Booking{ID: bookingID, ScheduledTime: expires}
+	if url := s.linkURL(booking, models.LinkConfirmAttendance); !strings.HasPrefix(url, "https://example.com/booking-links/") {
+		t.Errorf("link URL %q does not open the booking link page", url)
+	}
+
+	other := NewReminderService(nil, nil, []byte("other secret"), "")
+	invalid := map[string]string{
+		"tampered action": strings.Replace(token, ".cancel.", ".confirm.", 1),
+		"other secret":    other.signLink(bookingID, models.LinkCancelBooking, expires),
+		"expired":         s.signLink(bookingID, models.LinkCancelBooking, time.Now().Add(-time.Minute)),
+		"unknown action":  s.signLink(bookingID, "delete", expires),
+		"no signature":    "garbage",
+	}
+	for name, token := range invalid {
+		if _, err := s.parseLink(token); !errors.Is(err, ErrInvalidBookingLink) {
+			t.Errorf("%s: %v, want ErrInvalidBookingLink", name, err)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrResourceNotFound   = errors.New("resource not found")
+	ErrInvalidNightRange  = errors.New("to must be after from and no more than a year later")
+	ErrInvalidResourceUse = errors.New("resource use must fit within the service and its quantity within the resource's capacity")
+	// ErrResourceUnavailable is an ErrSlotUnavailable, so callers that skip
+	// or report taken slots treat both alike.
+	ErrResourceUnavailable = fmt.Errorf("%w: a resource it needs is fully booked", ErrSlotUnavailable)
+)
+
+const resourceColumns = `id, provider_id, name, kind, capacity, active, created_at, updated_at`
+
+func scanResource(row rowScanner) (*models.Resource, error) {
+	var r models.Resource
+	err := row.Scan(&r.ID, &r.ProviderID, &r.Name, &r.Kind, &r.Capacity, &r.Active, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func getResource(q queryer, id, providerID uuid.UUID) (*models.Resource, error) {
+	r, err := scanResource(q.QueryRow(`
+		SELECT `+resourceColumns+` FROM resources WHERE id = $1 AND provider_id = $2`, id, providerID))
+	if err == sql.ErrNoRows {
+		return nil, ErrResourceNotFound
+	}
+	return r, err
+}
+
+// resourceNeed is one active resource a service uses, with its capacity.
+type resourceNeed struct {
+	models.ServiceResource
+	name     string
+	capacity int
+}
+
+// window returns when a booking of service starting at start holds the
+// resource.
+func (n resourceNeed) window(service *models.Service, start time.Time) (time.Time, time.Time) {
+	from := start.Add(time.Duration(n.StartOffset) * time.Minute)
+	minutes := n.Duration
+	if minutes == 0 {
+		minutes = service.Duration - n.StartOffset
+	}
+	return from, from.Add(time.Duration(minutes) * time.Minute)
+}
+
+// serviceResources returns the active resources service uses.
+func serviceResources(q queryer, serviceID uuid.UUID) ([]resourceNeed, error) {
+	rows, err := q.Query(`
+		SELECT sr.resource_id, sr.quantity, sr.start_offset_minutes, sr.duration_minutes, r.name, r.capacity
+		FROM service_resources sr
+		JOIN resources r ON r.id = sr.resource_id
+		WHERE sr.service_id = $1 AND r.active
+		ORDER BY r.name`, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var needs []resourceNeed
+	for rows.Next() {
+		var n resourceNeed
+		if err := rows.Scan(&n.ResourceID, &n.Quantity, &n.StartOffset, &n.Duration, &n.name, &n.capacity); err != nil {
+			return nil, err
+		}
+		needs = append(needs, n)
+	}
+	return needs, rows.Err()
+}
+
+// resourceUse is a period some quantity of a resource is held.
+type resourceUse struct {
+	start, end time.Time
+	quantity   int
+}
+
+// resourceUses returns the holds on a resource overlapping [from, to) by
+// active bookings, other than those in excluded, and unexpired waitlist
+// holds. Each is placed by its service's current use of the resource, except
+// that stays hold it for whole nights, counted in loc.
+func resourceUses(q queryer, resourceID uuid.UUID, loc *time.Location, from, to time.Time, excluded []string) ([]resourceUse, error) {
+	rows, err := q.Query(`
+		SELECT use_start, use_end, quantity FROM (
+			SELECT CASE WHEN b.check_out_time IS NOT NULL
+					THEN date_trunc('day', b.scheduled_time AT TIME ZONE $5) AT TIME ZONE $5
+					ELSE b.scheduled_time + sr.start_offset_minutes * INTERVAL '1 minute' END AS use_start,
+				CASE WHEN b.check_out_time IS NOT NULL
+					THEN date_trunc('day', b.check_out_time AT TIME ZONE $5) AT TIME ZONE $5
+					ELSE b.scheduled_time + CASE WHEN sr.duration_minutes > 0
+						THEN sr.start_offset_minutes + sr.duration_minutes
+						ELSE s.duration_minutes END * INTERVAL '1 minute' END AS use_end,
+				sr.quantity
+			FROM bookings b
+			JOIN services s ON s.id = b.service_id
+			JOIN service_resources sr ON sr.service_id = b.service_id
+			WHERE sr.resource_id = $1
+				AND b.status IN ('pending', 'confirmed', 'in_progress')
+				AND NOT (b.id = ANY($4::uuid[]))
+			UNION ALL
+			SELECT w.offered_time + sr.start_offset_minutes * INTERVAL '1 minute',
+				w.offered_time + CASE WHEN sr.duration_minutes > 0
+					THEN sr.start_offset_minutes + sr.duration_minutes
+					ELSE s.duration_minutes END * INTERVAL '1 minute',
+				sr.quantity
+			FROM waitlist_entries w
+			JOIN services s ON s.id = w.service_id
+			JOIN service_resources sr ON sr.service_id = w.service_id
+			WHERE sr.resource_id = $1
+				AND w.status = 'offered'
+				AND w.hold_expires_at > NOW()
+		) uses
+		WHERE use_start < $3 AND use_end > $2`, resourceID, from, to, pq.Array(excluded), loc.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var uses []resourceUse
+	for rows.Next() {
+		var u resourceUse
+		if err := rows.Scan(&u.start, &u.end, &u.quantity); err != nil {
+			return nil, err
+		}
+		uses = append(uses, u)
+	}
+	return uses, rows.Err()
+}
+
+// peakUse returns the most of a resource held at any one moment by uses.
+// Holds that end as another starts do not overlap.
+func peakUse(uses []resourceUse) int {
+	type change struct {
+		at    time.Time
+		delta int
+	}
+	changes := make([]change, 0, 2*len(uses))
+	for _, u := range uses {
+		changes = append(changes, change{u.start, u.quantity}, change{u.end, -u.quantity})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].at.Equal(changes[j].at) {
+			return changes[i].delta < changes[j].delta
+		}
+		return changes[i].at.Before(changes[j].at)
+	})
+
+	peak, current := 0, 0
+	for _, c := range changes {
+		current += c.delta
+		if current > peak {
+			peak = current
+		}
+	}
+	return peak
+}
+
+// checkResources returns ErrResourceUnavailable if a booking of service at
+// start would need more of one of its resources than is left. Stays hold
+// their resources by the night and are checked by checkNights instead.
+func checkResources(q queryer, service *models.Service, start time.Time, excluded []string) error {
+	if service.Category.IsStay() {
+		return nil
+	}
+	return checkResourceWindows(q, service, excluded, func(need resourceNeed) (time.Time, time.Time) {
+		return need.window(service, start)
+	})
+}
+
+// checkResourceWindows checks each resource service uses over the period
+// window gives for it.
+func checkResourceWindows(q queryer, service *models.Service, excluded []string, window func(resourceNeed) (time.Time, time.Time)) error {
+	needs, err := serviceResources(q, service.ID)
+	if err != nil || len(needs) == 0 {
+		return err
+	}
+	loc, err := providerLocation(q, service.ProviderID)
+	if err != nil {
+		return err
+	}
+	for _, need := range needs {
+		from, to := window(need)
+		uses, err := resourceUses(q, need.ResourceID, loc, from, to, excluded)
+		if err != nil {
+			return err
+		}
+		if peakUse(uses)+need.Quantity > need.capacity {
+			return fmt.Errorf("%w (%s)", ErrResourceUnavailable, need.name)
+		}
+	}
+	return nil
+}
+
+// ResourceService manages the equipment and space providers schedule
+// alongside their staff, and which of it each service uses.
+type ResourceService struct {
+	db *sql.DB
+}
+
+func NewResourceService(db *sql.DB) *ResourceService {
+	return &ResourceService{db: db}
+}
+
+// List returns the provider's resources, including retired ones.
+func (s *ResourceService) List(providerID uuid.UUID) ([]models.Resource, error) {
+	rows, err := s.db.Query(`
+		SELECT `+resourceColumns+` FROM resources
+		WHERE provider_id = $1
+		ORDER BY active DESC, kind, name`, providerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resources := []models.Resource{}
+	for rows.Next() {
+		r, err := scanResource(rows)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, *r)
+	}
+	return resources, rows.Err()
+}
+
+// Create adds a resource to the provider's schedule.
+func (s *ResourceService) Create(providerID uuid.UUID, req models.CreateResourceRequest) (*models.Resource, error) {
+	if err := requireProvider(s.db, providerID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	r := &models.Resource{
+		ID:         uuid.New(),
+		ProviderID: providerID,
+		Name:       strings.TrimSpace(req.Name),
+		Kind:       req.Kind,
+		Capacity:   req.Capacity,
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO resources (`+resourceColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		r.ID, r.ProviderID, r.Name, r.Kind, r.Capacity, r.Active, r.CreatedAt, r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Update changes a resource. Existing bookings are kept even if they now
+// exceed its capacity.
+func (s *ResourceService) Update(id, providerID uuid.UUID, req models.UpdateResourceRequest) (*models.Resource, error) {
+	r, err := getResource(s.db, id, providerID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		r.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Kind != nil {
+		r.Kind = *req.Kind
+	}
+	if req.Capacity != nil {
+		r.Capacity = *req.Capacity
+	}
+	r.UpdatedAt = time.Now()
+
+	_, err = s.db.Exec(`
+		UPDATE resources SET name = $1, kind = $2, capacity = $3, updated_at = $4 WHERE id = $5`,
+		r.Name, r.Kind, r.Capacity, r.UpdatedAt, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Retire stops a resource from limiting bookings. Services keep their link
+// to it, so it can be brought back with the same setup.
+func (s *ResourceService) Retire(id, providerID uuid.UUID) error {
+	result, err := s.db.Exec(`
+		UPDATE resources SET active = FALSE, updated_at = NOW()
+		WHERE id = $1 AND provider_id = $2`, id, providerID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrResourceNotFound
+	}
+	return nil
+}
+
+// providerService loads one of the provider's services.
+func providerService(q queryer, serviceID, providerID uuid.UUID) (*models.Service, error) {
+	service, err := getService(q, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if service.ProviderID != providerID {
+		return nil, ErrServiceNotFound
+	}
+	return service, nil
+}
+
+// ServiceResources returns the resources one of the provider's services
+// uses.
+func (s *ResourceService) ServiceResources(serviceID, providerID uuid.UUID) ([]models.ServiceResource, error) {
+	if _, err := providerService(s.db, serviceID, providerID); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(`
+		SELECT resource_id, quantity, start_offset_minutes, duration_minutes
+		FROM service_resources WHERE service_id = $1
+		ORDER BY start_offset_minutes`, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	uses := []models.ServiceResource{}
+	for rows.Next() {
+		var u models.ServiceResource
+		if err := rows.Scan(&u.ResourceID, &u.Quantity, &u.StartOffset, &u.Duration); err != nil {
+			return nil, err
+		}
+		uses = append(uses, u)
+	}
+	return uses, rows.Err()
+}
+
+// SetServiceResources replaces the resources a service uses. The change
+// applies to the service's existing bookings as well as new ones.
+func (s *ResourceService) SetServiceResources(serviceID, providerID uuid.UUID, uses []models.ServiceResource) ([]models.ServiceResource, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	service, err := providerService(tx, serviceID, providerID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM service_resources WHERE service_id = $1`, serviceID); err != nil {
+		return nil, err
+	}
+
+	saved := []models.ServiceResource{}
+	seen := make(map[uuid.UUID]bool)
+	for _, u := range uses {
+		if seen[u.ResourceID] {
+			return nil, ErrInvalidResourceUse
+		}
+		seen[u.ResourceID] = true
+
+		r, err := getResource(tx, u.ResourceID, providerID)
+		if err != nil {
+			return nil, err
+		}
+		if u.Quantity == 0 {
+			u.Quantity = 1
+		}
+		if u.Quantity > r.Capacity || u.StartOffset >= service.Duration || u.StartOffset+u.Duration > service.Duration {
+			return nil, ErrInvalidResourceUse
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO service_resources (service_id, resource_id, quantity, start_offset_minutes, duration_minutes)
+			VALUES ($1, $2, $3, $4, $5)`,
+			serviceID, u.ResourceID, u.Quantity, u.StartOffset, u.Duration); err != nil {
+			return nil, err
+		}
+		saved = append(saved, u)
+	}
+	return saved, tx.Commit()
+}
+
+// maxOccupancyNights caps how many nights one occupancy request covers.
+const maxOccupancyNights = 366
+
+// Occupancy returns how much of a resource is booked on each night from the
+// one starting on from's date up to the one before to's. The dates are read
+// in the provider's time zone.
+func (s *ResourceService) Occupancy(id, providerID uuid.UUID, from, to time.Time) ([]models.NightOccupancy, error) {
+	r, err := getResource(s.db, id, providerID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := providerLocation(s.db, providerID)
+	if err != nil {
+		return nil, err
+	}
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc)
+	if !last.After(first) || countNights(loc, first, last) > maxOccupancyNights {
+		return nil, ErrInvalidNightRange
+	}
+
+	uses, err := resourceUses(s.db, r.ID, loc, first, last, nil)
+	if err != nil {
+		return nil, err
+	}
+	nights := []models.NightOccupancy{}
+	for night := first; night.Before(last); night = night.AddDate(0, 0, 1) {
+		next := night.AddDate(0, 0, 1)
+		var tonight []resourceUse
+		for _, u := range uses {
+			if u.start.Before(next) && u.end.After(night) {
+				tonight = append(tonight, u)
+			}
+		}
+		nights = append(nights, models.NightOccupancy{
+			Date:     night.Format("2006-01-02"),
+			Booked:   peakUse(tonight),
+			Capacity: r.Capacity,
+		})
+	}
+	return nights, nil
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeakUse(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2026, 10, 20, hour, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		uses []resourceUse
+		want int
+	}{
+		{"none", nil, 0},
+		{"one", []resourceUse{{at(9), at(10), 2}}, 2},
+		{"overlapping", []resourceUse{{at(9), at(11), 1}, {at(10), at(12), 1}, {at(10), at(11), 3}}, 5},
+		{"back to back", []resourceUse{{at(9), at(10), 1}, {at(10), at(11), 1}}, 1},
+		{"apart", []resourceUse{{at(9), at(10), 2}, {at(11), at(12), 3}}, 3},
+		{"nested", []resourceUse{{at(8), at(16), 1}, {at(9), at(10), 1}, {at(12), at(13), 1}, {at(12), at(14), 1}}, 3},
+		{"unsorted", []resourceUse{{at(12), at(14), 1}, {at(8), at(13), 1}, {at(13), at(15), 1}}, 2},
+	}
+	for _, tt := range tests {
+		if got := peakUse(tt.uses); got != tt.want {
+			t.Errorf("%s: peakUse = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrReviewNotAllowed = errors.New("only completed bookings can be reviewed")
+	ErrReviewExists     = errors.New("this booking has already been reviewed")
+)
+
+const reviewColumns = `id, booking_id, user_id, provider_id, rating, comment, created_at`
+
+type ReviewService struct {
+	db *sql.DB
+}
+
+func NewReviewService(db *sql.DB) *ReviewService {
+	return &ReviewService{db: db}
+}
+
+// Create records the owner's review of a completed booking and awards the
+// review's loyalty points.
+func (s *ReviewService) Create(bookingID, userID uuid.UUID, req models.CreateReviewRequest) (*models.Review, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	booking, err := lockBooking(tx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID {
+		if booking.ProviderID == userID {
+			return nil, ErrForbidden
+		}
+		return nil, ErrBookingNotFound
+	}
+	if booking.Status != models.StatusCompleted {
+		return nil, ErrReviewNotAllowed
+	}
+
+	var exists bool
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM reviews WHERE booking_id = $1)`, booking.ID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrReviewExists
+	}
+
+	review := &models.Review{
+		ID:         uuid.New(),
+		BookingID:  booking.ID,
+		UserID:     userID,
+		ProviderID: booking.ProviderID,
+		Rating:     req.Rating,
+		Comment:    req.Comment,
+		CreatedAt:  time.Now(),
+	}
+	_, err = tx.Exec(`
+		INSERT INTO reviews (`+reviewColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		review.ID, review.BookingID, review.UserID, review.ProviderID, review.Rating, review.Comment, review.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := creditPoints(tx, userID, models.PointsEarn, models.ReasonReview, models.PointsPerReview, booking.ID); err != nil {
+		return nil, err
+	}
+	if err := enqueueWebhooks(tx, booking.ProviderID, models.WebhookReviewCreated, review); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// List returns a provider's reviews, newest first.
+func (s *ReviewService) List(providerID uuid.UUID) ([]models.Review, error) {
+	rows, err := s.db.Query(`
+		SELECT `+reviewColumns+` FROM reviews
+		WHERE provider_id = $1 ORDER BY created_at DESC`, providerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []models.Review{}
+	for rows.Next() {
+		var review models.Review
+		if err := rows.Scan(&review.ID, &review.BookingID, &review.UserID, &review.ProviderID,
+			&review.Rating, &review.Comment, &review.CreatedAt); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
@@ -0,0 +1,479 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOrganizationNotFound = errors.New("set up your organization before adding staff")
+	ErrStaffNotFound        = errors.New("staff member not found")
+	ErrStaffCannotPerform   = errors.New("this groomer does not offer the service")
+	ErrStaffUnavailable     = errors.New("this groomer is not available at that time")
+	ErrInvalidSchedule      = errors.New("shifts need HH:MM start and end times with the start first")
+)
+
+const organizationColumns = `id, owner_id, name, time_zone, created_at, updated_at`
+
+func scanOrganization(row rowScanner) (*models.Organization, error) {
+	var o models.Organization
+	if err := row.Scan(&o.ID, &o.OwnerID, &o.Name, &o.TimeZone, &o.CreatedAt, &o.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func getOrganization(q queryer, ownerID uuid.UUID) (*models.Organization, error) {
+	org, err := scanOrganization(q.QueryRow(`
+		SELECT `+organizationColumns+` FROM organizations WHERE owner_id = $1`, ownerID))
+	if err == sql.ErrNoRows {
+		return nil, ErrOrganizationNotFound
+	}
+	return org, err
+}
+
+const staffColumns = `id, organization_id, name, email, active, created_at`
+
+// listStaff loads an organization's staff with their services and
+// schedules, ordered by name.
+func listStaff(q queryer, orgID uuid.UUID, activeOnly bool) ([]models.StaffMember, error) {
+	rows, err := q.Query(`
+		SELECT `+staffColumns+` FROM staff_members
+		WHERE organization_id = $1 AND (active OR NOT $2)
+		ORDER BY name, created_at`, orgID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	staff := []models.StaffMember{}
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var m models.StaffMember
+		if err := rows.Scan(&m.ID, &m.OrganizationID, &m.Name, &m.Email, &m.Active, &m.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		m.ServiceIDs = []uuid.UUID{}
+		m.Schedule = []models.StaffShift{}
+		index[m.ID] = len(staff)
+		staff = append(staff, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(staff) == 0 {
+		return staff, err
+	}
+
+	rows, err = q.Query(`
+		SELECT ss.staff_id, ss.service_id FROM staff_services ss
+		JOIN staff_members m ON m.id = ss.staff_id
+		WHERE m.organization_id = $1`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var staffID, serviceID uuid.UUID
+		if err := rows.Scan(&staffID, &serviceID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if i, ok := index[staffID]; ok {
+			staff[i].ServiceIDs = append(staff[i].ServiceIDs, serviceID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = q.Query(`
+		SELECT sh.staff_id, sh.weekday, sh.start_minute, sh.end_minute FROM staff_shifts sh
+		JOIN staff_members m ON m.id = sh.staff_id
+		WHERE m.organization_id = $1
+		ORDER BY sh.weekday, sh.start_minute`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var staffID uuid.UUID
+		var weekday, start, end int
+		if err := rows.Scan(&staffID, &weekday, &start, &end); err != nil {
+			return nil, err
+		}
+		if i, ok := index[staffID]; ok {
+			staff[i].Schedule = append(staff[i].Schedule, models.StaffShift{
+				Weekday: time.Weekday(weekday),
+				Start:   formatClock(start),
+				End:     formatClock(end),
+			})
+		}
+	}
+	return staff, rows.Err()
+}
+
+func parseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+func formatClock(minutes int) string {
+	return time.Date(0, 1, 1, 0, minutes, 0, 0, time.UTC).Format("15:04")
+}
+
+// performs reports whether the staff member offers the service.
+func performs(m *models.StaffMember, serviceID uuid.UUID) bool {
+	for _, id := range m.ServiceIDs {
+		if id == serviceID {
+			return true
+		}
+	}
+	return false
+}
+
+// worksDuring reports whether [start, end) falls inside one of the staff
+// member's shifts, read in loc.
+func worksDuring(m *models.StaffMember, loc *time.Location, start, end time.Time) bool {
+	local := start.In(loc)
+	from := local.Hour()*60 + local.Minute()
+	to := from + int(end.Sub(start)/time.Minute)
+	for _, shift := range m.Schedule {
+		if shift.Weekday != local.Weekday() {
+			continue
+		}
+		shiftStart, _ := parseClock(shift.Start)
+		shiftEnd, _ := parseClock(shift.End)
+		if shift.End == "00:00" {
+			shiftEnd = 24 * 60
+		}
+		if from >= shiftStart && to <= shiftEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// assignStaff picks the staff member to take [start, end) for service at a
+// provider with staff: the requested one, or else the first qualified
+// groomer on shift who is not in booked. Waitlist holds and bookings made
+// before the provider had staff belong to nobody in particular, so each of
+// the reserved ones takes a free groomer out of the running.
+func assignStaff(org *models.Organization, staff []models.StaffMember, service *models.Service, requested *uuid.UUID, start, end time.Time, booked map[uuid.UUID]bool, reserved int) (*uuid.UUID, error) {
+	if requested != nil {
+		found := false
+		for i := range staff {
+			if staff[i].ID == *requested {
+				found = true
+				if !performs(&staff[i], service.ID) {
+					return nil, ErrStaffCannotPerform
+				}
+			}
+		}
+		if !found {
+			return nil, ErrStaffNotFound
+		}
+	}
+
+	loc := org.Location()
+	var free []uuid.UUID
+	for i := range staff {
+		m := &staff[i]
+		if performs(m, service.ID) && worksDuring(m, loc, start, end) && !booked[m.ID] {
+			free = append(free, m.ID)
+		}
+	}
+	if len(free) <= reserved {
+		if requested != nil {
+			return nil, ErrStaffUnavailable
+		}
+		return nil, ErrSlotUnavailable
+	}
+	if requested == nil {
+		return &free[0], nil
+	}
+	for _, id := range free {
+		if id == *requested {
+			return requested, nil
+		}
+	}
+	return nil, ErrStaffUnavailable
+}
+
+// requireStaff checks that staffID works for the provider's organization.
+func requireStaff(q queryer, staffID, providerID uuid.UUID) error {
+	var exists bool
+	err := q.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM staff_members sm
+			JOIN organizations o ON o.id = sm.organization_id
+			WHERE sm.id = $1 AND o.owner_id = $2
+		)`, staffID, providerID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrStaffNotFound
+	}
+	return nil
+}
+
+// activeStaff returns the provider's organization and active staff, or nil
+// for a provider working alone.
+func activeStaff(q queryer, providerID uuid.UUID) (*models.Organization, []models.StaffMember, error) {
+	org, err := getOrganization(q, providerID)
+	if errors.Is(err, ErrOrganizationNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	staff, err := listStaff(q, org.ID, true)
+	if err != nil || len(staff) == 0 {
+		return nil, nil, err
+	}
+	return org, staff, nil
+}
+
+// StaffService manages providers' organizations and the staff who work for
+// them.
+type StaffService struct {
+	db *sql.DB
+}
+
+func NewStaffService(db *sql.DB) *StaffService {
+	return &StaffService{db: db}
+}
+
+// GetOrganization returns the provider's organization.
+func (s *StaffService) GetOrganization(ownerID uuid.UUID) (*models.Organization, error) {
+	return getOrganization(s.db, ownerID)
+}
+
+// SaveOrganization creates the provider's organization or updates it.
+func (s *StaffService) SaveOrganization(ownerID uuid.UUID, req models.SaveOrganizationRequest) (*models.Organization, error) {
+	if err := requireProvider(s.db, ownerID); err != nil {
+		return nil, err
+	}
+	if req.TimeZone == "" {
+		req.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(req.TimeZone); err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+
+	now := time.Now()
+	return scanOrganization(s.db.QueryRow(`
+		INSERT INTO organizations (`+organizationColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (owner_id) DO UPDATE SET
+			name = EXCLUDED.name, time_zone = EXCLUDED.time_zone, updated_at = EXCLUDED.updated_at
+		RETURNING `+organizationColumns,
+		uuid.New(), ownerID, strings.TrimSpace(req.Name), req.TimeZone, now))
+}
+
+// ListStaff returns the organization's staff, including those who have
+// left.
+func (s *StaffService) ListStaff(ownerID uuid.UUID) ([]models.StaffMember, error) {
+	org, err := getOrganization(s.db, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return listStaff(s.db, org.ID, false)
+}
+
+func (s *StaffService) getStaff(q queryer, id, ownerID uuid.UUID) (*models.Organization, *models.StaffMember, error) {
+	org, err := getOrganization(q, ownerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	staff, err := listStaff(q, org.ID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range staff {
+		if staff[i].ID == id {
+			return org, &staff[i], nil
+		}
+	}
+	return nil, nil, ErrStaffNotFound
+}
+
+// AddStaff adds a groomer to the provider's organization.
+func (s *StaffService) AddStaff(ownerID uuid.UUID, req models.CreateStaffRequest) (*models.StaffMember, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	org, err := getOrganization(tx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	member := &models.StaffMember{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		Name:           strings.TrimSpace(req.Name),
+		Email:          strings.TrimSpace(req.Email),
+		Active:         true,
+		CreatedAt:      time.Now(),
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO staff_members (`+staffColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+		member.ID, member.OrganizationID, member.Name, member.Email, member.Active, member.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := setStaffServices(tx, member, ownerID, req.ServiceIDs); err != nil {
+		return nil, err
+	}
+	if err := setStaffSchedule(tx, member, req.Schedule); err != nil {
+		return nil, err
+	}
+	return member, tx.Commit()
+}
+
+// UpdateStaff changes a staff member's details, services or schedule.
+// Bookings they already have are kept.
+func (s *StaffService) UpdateStaff(id, ownerID uuid.UUID, req models.UpdateStaffRequest) (*models.StaffMember, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, member, err := s.getStaff(tx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		member.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Email != nil {
+		member.Email = strings.TrimSpace(*req.Email)
+	}
+	if _, err := tx.Exec(`UPDATE staff_members SET name = $1, email = $2 WHERE id = $3`,
+		member.Name, member.Email, member.ID); err != nil {
+		return nil, err
+	}
+	if req.ServiceIDs != nil {
+		if err := setStaffServices(tx, member, ownerID, *req.ServiceIDs); err != nil {
+			return nil, err
+		}
+	}
+	if req.Schedule != nil {
+		if err := setStaffSchedule(tx, member, *req.Schedule); err != nil {
+			return nil, err
+		}
+	}
+	return member, tx.Commit()
+}
+
+// RemoveStaff marks a staff member as having left. They are no longer
+// booked, but their past and existing bookings keep them.
+func (s *StaffService) RemoveStaff(id, ownerID uuid.UUID) error {
+	if _, _, err := s.getStaff(s.db, id, ownerID); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`UPDATE staff_members SET active = FALSE WHERE id = $1`, id)
+	return err
+}
+
+// setStaffServices replaces the services a staff member performs. Each must
+// be one of the organization owner's services.
+func setStaffServices(tx *sql.Tx, member *models.StaffMember, ownerID uuid.UUID, serviceIDs []uuid.UUID) error {
+	if _, err := tx.Exec(`DELETE FROM staff_services WHERE staff_id = $1`, member.ID); err != nil {
+		return err
+	}
+	member.ServiceIDs = []uuid.UUID{}
+	seen := make(map[uuid.UUID]bool)
+	for _, serviceID := range serviceIDs {
+		if seen[serviceID] {
+			continue
+		}
+		seen[serviceID] = true
+		if _, err := providerService(tx, serviceID, ownerID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO staff_services (staff_id, service_id) VALUES ($1, $2)`,
+			member.ID, serviceID); err != nil {
+			return err
+		}
+		member.ServiceIDs = append(member.ServiceIDs, serviceID)
+	}
+	return nil
+}
+
+// setStaffSchedule replaces a staff member's weekly shifts. An end of 00:00
+// means midnight at the end of the day.
+func setStaffSchedule(tx *sql.Tx, member *models.StaffMember, schedule []models.StaffShift) error {
+	if _, err := tx.Exec(`DELETE FROM staff_shifts WHERE staff_id = $1`, member.ID); err != nil {
+		return err
+	}
+	member.Schedule = []models.StaffShift{}
+	for _, shift := range schedule {
+		start, ok := parseClock(shift.Start)
+		end, endOK := parseClock(shift.End)
+		if end == 0 {
+			end = 24 * 60
+		}
+		if !ok || !endOK || shift.Weekday < time.Sunday || shift.Weekday > time.Saturday || end <= start {
+			return ErrInvalidSchedule
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO staff_shifts (staff_id, weekday, start_minute, end_minute) VALUES ($1, $2, $3, $4)`,
+			member.ID, int(shift.Weekday), start, end); err != nil {
+			return err
+		}
+		member.Schedule = append(member.Schedule, models.StaffShift{
+			Weekday: shift.Weekday,
+			Start:   formatClock(start),
+			End:     formatClock(end),
+		})
+	}
+	sort.Slice(member.Schedule, func(i, j int) bool {
+		a, b := member.Schedule[i], member.Schedule[j]
+		return a.Weekday < b.Weekday || (a.Weekday == b.Weekday && a.Start < b.Start)
+	})
+	return nil
+}
+
+// ServiceStaff lists the active staff who perform a service. With at set,
+// each is marked with whether they could take a booking starting then.
+func (s *StaffService) ServiceStaff(serviceID uuid.UUID, at *time.Time) ([]models.StaffAvailability, error) {
+	service, err := getService(s.db, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	_, staff, err := activeStaff(s.db, service.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.StaffAvailability{}
+	for i := range staff {
+		m := &staff[i]
+		if !performs(m, service.ID) {
+			continue
+		}
+		entry := models.StaffAvailability{ID: m.ID, Name: m.Name}
+		if at != nil {
+			_, err := checkAvailability(s.db, service, &m.ID, *at, nil)
+			available := err == nil
+			if err != nil && !errors.Is(err, ErrSlotUnavailable) && !errors.Is(err, ErrStaffUnavailable) {
+				return nil, err
+			}
+			entry.Available = &available
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
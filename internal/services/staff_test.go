@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestWorksDuring(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	m := &models.StaffMember{Schedule: []models.StaffShift{
+		{Weekday: time.Tuesday, Start: "09:00", End: "12:00"},
+		{Weekday: time.Tuesday, Start: "13:00", End: "17:00"},
+		{Weekday: time.Friday, Start: "18:00", End: "00:00"},
+	}}
+	// 20 October 2026 is a Tuesday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, newYork)
+	}
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       bool
+	}{
+		{"inside a shift", at(20, 10, 0), at(20, 11, 0), true},
+		{"from the start to the end", at(20, 9, 0), at(20, 12, 0), true},
+		{"before the shift", at(20, 8, 30), at(20, 9, 30), false},
+		{"past the end", at(20, 11, 30), at(20, 12, 30), false},
+		{"across the break", at(20, 11, 0), at(20, 14, 0), false},
+		{"second shift", at(20, 16, 0), at(20, 17, 0), true},
+		{"day off", at(21, 10, 0), at(21, 11, 0), false},
+		{"shift to midnight", at(23, 22, 0), at(24, 0, 0), true},
+		{"past midnight", at(23, 23, 0), at(24, 1, 0), false},
+	}
+	for _, tt := range tests {
+		if got := worksDuring(m, newYork, tt.start, tt.end); got != tt.want {
+			t.Errorf("%s: worksDuring = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Shifts are read in the organization's zone: 17:00 UTC is 13:00 in
+	// New York, inside the afternoon shift but past the end of a UTC one.
+	utc := time.Date(2026, 10, 20, 17, 0, 0, 0, time.UTC)
+	if !worksDuring(m, newYork, utc, utc.Add(time.Hour)) {
+		t.Error("a UTC time inside a New York shift was refused")
+	}
+	if worksDuring(m, time.UTC, utc, utc.Add(time.Hour)) {
+		t.Error("shifts were read in the wrong zone")
+	}
+}
+
+func TestAssignStaff(t *testing.T) {
+	org := &models.Organization{TimeZone: "UTC"}
+	service := &models.Service{ID: uuid.New(), Duration: 60}
+	other := uuid.New()
+	day := []models.StaffShift{{Weekday: time.Tuesday, Start: "09:00", End: "17:00"}}
+	ana := models.StaffMember{ID: uuid.New(), Name: "Ana", ServiceIDs: []uuid.UUID{service.ID}, Schedule: day}
+	ben := models.StaffMember{ID: uuid.New(), Name: "Ben", ServiceIDs: []uuid.UUID{service.ID}, Schedule: day}
+	cat := models.StaffMember{ID: uuid.New(), Name: "Cat", ServiceIDs: []uuid.UUID{other}, Schedule: day}
+	dev := models.StaffMember{ID: uuid.New(), Name: "Dev", ServiceIDs: []uuid.UUID{service.ID},
+		Schedule: []models.StaffShift{{Weekday: time.Wednesday, Start: "09:00", End: "17:00"}}}
+	staff := []models.StaffMember{ana, ben, cat, dev}
+
+	start := time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	unknown := uuid.New()
+	tests := []struct {
+		name      string
+		requested *uuid.UUID
+		booked    map[uuid.UUID]bool
+		reserved  int
+		want      *uuid.UUID
+		err       error
+	}{
+		{"first free groomer", nil, nil, 0, &ana.ID, nil},
+		{"skips the booked", nil, map[uuid.UUID]bool{ana.ID: true}, 0, &ben.ID, nil},
+		{"everyone booked", nil, map[uuid.UUID]bool{ana.ID: true, ben.ID: true}, 0, nil, ErrSlotUnavailable},
+		{"requested", &ben.ID, nil, 0, &ben.ID, nil},
+		{"requested is booked", &ben.ID, map[uuid.UUID]bool{ben.ID: true}, 0, nil, ErrStaffUnavailable},
+		{"requested is off shift", &dev.ID, nil, 0, nil, ErrStaffUnavailable},
+		{"requested does not do the service", &cat.ID, nil, 0, nil, ErrStaffCannotPerform},
+		{"requested does not work here", &unknown, nil, 0, nil, ErrStaffNotFound},
+		// Unassigned bookings and waitlist holds each take a free groomer
+		// out of the running without saying which.
+		{"one reserved of two free", nil, nil, 1, &ana.ID, nil},
+		{"both free groomers reserved", nil, nil, 2, nil, ErrSlotUnavailable},
+		{"reserved with one booked", nil, map[uuid.UUID]bool{ana.ID: true}, 1, nil, ErrSlotUnavailable},
+		{"requested with room to spare", &ben.ID, nil, 1, &ben.ID, nil},
+		{"requested but all reserved", &ben.ID, nil, 2, nil, ErrStaffUnavailable},
+	}
+	for _, tt := range tests {
+		got, err := assignStaff(org, staff, service, tt.requested, start, end, tt.booked, tt.reserved)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("%s: assigned %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidStay       = errors.New("boarding and sitting need a check-out at least one night after check-in; other services take none")
+	ErrStayNotRepeatable = errors.New("stays cannot repeat, join the waitlist or be paid with a package credit")
+	ErrStayLengthChanged = errors.New("moving a stay must keep its number of nights; cancel and rebook to change its length")
+	ErrNotInCare         = errors.New("care can only be logged for a stay that has been checked in")
+)
+
+// providerLocation returns the time zone a provider's stays are counted in:
+// their organization's, or UTC for a provider without one.
+func providerLocation(q queryer, providerID uuid.UUID) (*time.Location, error) {
+	org, err := getOrganization(q, providerID)
+	if errors.Is(err, ErrOrganizationNotFound) {
+		return time.UTC, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return org.Location(), nil
+}
+
+// nightStart returns midnight at the start of t's date in loc.
+func nightStart(loc *time.Location, t time.Time) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// countNights returns how many nights a stay from checkIn to checkOut
+// spans: the number of dates, in loc, it checks out after the one it checks
+// in on.
+func countNights(loc *time.Location, checkIn, checkOut time.Time) int {
+	inY, inM, inD := checkIn.In(loc).Date()
+	outY, outM, outD := checkOut.In(loc).Date()
+	days := time.Date(outY, outM, outD, 0, 0, 0, 0, time.UTC).Sub(time.Date(inY, inM, inD, 0, 0, 0, 0, time.UTC))
+	return int(days / (24 * time.Hour))
+}
+
+// checkNights returns ErrResourceUnavailable if a stay of service from
+// checkIn to checkOut would need more of one of its resources, such as
+// kennels, than is left on any night.
+func checkNights(q queryer, service *models.Service, loc *time.Location, checkIn, checkOut time.Time, excluded []string) error {
+	return checkResourceWindows(q, service, excluded, func(resourceNeed) (time.Time, time.Time) {
+		return nightStart(loc, checkIn), nightStart(loc, checkOut)
+	})
+}
+
+const careLogColumns = `id, booking_id, author_id, kind, notes, logged_at, created_at`
+
+// CareLogService keeps the daily care logs for boarding and sitting stays,
+// which the provider writes and the owner can read.
+type CareLogService struct {
+	db *sql.DB
+}
+
+func NewCareLogService(db *sql.DB) *CareLogService {
+	return &CareLogService{db: db}
+}
+
+// Add records care given during a stay. Only the booking's provider can
+// log, and only once the pet has been checked in.
+func (s *CareLogService) Add(bookingID, providerID uuid.UUID, req models.CreateCareLogRequest) (*models.CareLogEntry, error) {
+	booking, err := getBookingRow(s.db, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.ProviderID != providerID {
+		if booking.UserID == providerID {
+			return nil, ErrForbidden
+		}
+		return nil, ErrBookingNotFound
+	}
+	if booking.CheckOutTime == nil {
+		return nil, ErrInvalidStay
+	}
+	if booking.Status != models.StatusInProgress && booking.Status != models.StatusCompleted {
+		return nil, ErrNotInCare
+	}
+
+	now := time.Now()
+	entry := &models.CareLogEntry{
+		ID:        uuid.New(),
+		BookingID: booking.ID,
+		AuthorID:  providerID,
+		Kind:      req.Kind,
+		Notes:     req.Notes,
+		LoggedAt:  now,
+		CreatedAt: now,
+	}
+	if req.LoggedAt != nil {
+		entry.LoggedAt = *req.LoggedAt
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO care_logs (`+careLogColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		entry.ID, entry.BookingID, entry.AuthorID, entry.Kind, entry.Notes, entry.LoggedAt, entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// List returns a stay's care log grouped by day in the provider's time
+// zone, oldest first, for its owner or provider.
+func (s *CareLogService) List(bookingID, userID uuid.UUID) ([]models.CareLogDay, error) {
+	booking, err := getBookingRow(s.db, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID && booking.ProviderID != userID {
+		return nil, ErrBookingNotFound
+	}
+	if booking.CheckOutTime == nil {
+		return nil, ErrInvalidStay
+	}
+	loc, err := providerLocation(s.db, booking.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+careLogColumns+` FROM care_logs
+		WHERE booking_id = $1
+		ORDER BY logged_at, created_at`, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := []models.CareLogDay{}
+	for rows.Next() {
+		var e models.CareLogEntry
+		if err := rows.Scan(&e.ID, &e.BookingID, &e.AuthorID, &e.Kind, &e.Notes, &e.LoggedAt, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		date := e.LoggedAt.In(loc).Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, models.CareLogDay{Date: date, Entries: []models.CareLogEntry{}})
+		}
+		days[len(days)-1].Entries = append(days[len(days)-1].Entries, e)
+	}
+	return days, rows.Err()
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pet-grooming-app/internal/models"
+)
+
+func TestCountNights(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	at := func(loc *time.Location, month time.Month, day, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, loc)
+	}
+	tests := []struct {
+		name              string
+		loc               *time.Location
+		checkIn, checkOut time.Time
+		want              int
+	}{
+		{"same day", time.UTC, at(time.UTC, 10, 20, 9), at(time.UTC, 10, 20, 17), 0},
+		{"overnight", time.UTC, at(time.UTC, 10, 20, 17), at(time.UTC, 10, 21, 9), 1},
+		{"a week", time.UTC, at(time.UTC, 10, 20, 12), at(time.UTC, 10, 27, 12), 7},
+		{"across months", time.UTC, at(time.UTC, 10, 30, 12), at(time.UTC, 11, 2, 12), 3},
+		// Over the end of daylight saving time the stay has a 25-hour day
+		// but still covers two nights.
+		{"across DST", newYork, at(newYork, 10, 31, 18), at(newYork, 11, 2, 8), 2},
+		// Counted in the provider's zone: 23:00 to 01:00 in New York is
+		// one night, though both times fall on the same UTC date.
+		{"provider's zone", newYork, at(newYork, 10, 20, 23), at(newYork, 10, 21, 1), 1},
+	}
+	for _, tt := range tests {
+		if got := countNights(tt.loc, tt.checkIn, tt.checkOut); got != tt.want {
+			t.Errorf("%s: countNights = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSittingStayHoldsProviderUntilCheckOut(t *testing.T) {
+	db := openTestDB(t)
+	stay := seedBooking(t, db, models.ServiceSitting, models.NewMoney(15000, "USD"), models.Zero("USD"), "card")
+	checkOut := stay.ScheduledTime.Add(3 * 24 * time.Hour)
+	if _, err := db.Exec(`UPDATE bookings SET check_out_time = $1, nights = 3 WHERE id = $2`, checkOut, stay.ID); err != nil {
+		t.Fatal(err)
+	}
+	service, err := getService(db, stay.ServiceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A second sitting stay overlapping the first's later nights.
+	start := stay.ScheduledTime.Add(2 * 24 * time.Hour)
+	end := start.Add(2 * 24 * time.Hour)
+	if _, err := checkAvailability(db, service, nil, start, &end); !errors.Is(err, ErrSlotUnavailable) {
+		t.Errorf("overlapping stay: %v, want ErrSlotUnavailable", err)
+	}
+	// A new stay ending during the existing one blocks it too.
+	earlier := stay.ScheduledTime.Add(-24 * time.Hour)
+	if _, err := checkAvailability(db, service, nil, earlier, &start); !errors.Is(err, ErrSlotUnavailable) {
+		t.Errorf("stay running into the existing one: %v, want ErrSlotUnavailable", err)
+	}
+
+	later := checkOut.Add(time.Hour)
+	laterEnd := later.Add(24 * time.Hour)
+	if _, err := checkAvailability(db, service, nil, later, &laterEnd); err != nil {
+		t.Errorf("stay after check-out: %v", err)
+	}
+}
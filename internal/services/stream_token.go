@@ -0,0 +1,57 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// streamTokenLifetime is how long a stream token can open a stream. Stream
+// tokens travel in query strings and so end up in access logs; they only
+// need to outlive the connection attempt that follows fetching one.
+const streamTokenLifetime = time.Minute
+
+// streamTokenPurpose marks tokens that can only open the event stream.
+const streamTokenPurpose = "event_stream"
+
+var ErrInvalidStreamToken = errors.New("invalid stream token")
+
+// GenerateStreamToken issues a token that opens userID's event stream and
+// nothing else, returning it with its expiry.
+func (s *AuthService) GenerateStreamToken(userID uuid.UUID) (string, time.Time, error) {
+	expiresAt := time.Now().Add(streamTokenLifetime)
+	claims := jwt.MapClaims{
+		"user_id": userID.String(),
+		"purpose": streamTokenPurpose,
+		"exp":     expiresAt.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.jwtSecret))
+	return token, expiresAt, err
+}
+
+// ValidateStreamToken returns the user a stream token was issued to.
+// Session tokens are rejected.
+func (s *AuthService) ValidateStreamToken(tokenString string) (uuid.UUID, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return []byte(s.jwtSecret), nil
+	})
+	if err != nil {
+		return uuid.Nil, ErrInvalidStreamToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || claims["purpose"] != streamTokenPurpose {
+		return uuid.Nil, ErrInvalidStreamToken
+	}
+	userIDStr, _ := claims["user_id"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, ErrInvalidStreamToken
+	}
+	return userID, nil
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestStreamTokens(t *testing.T) {
+	s := NewAuthService(nil, "secret")
+	userID := uuid.New()
+
+	streamToken, expiresAt, err := s.GenerateStreamToken(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expiresAt.IsZero() {
+		t.Error("stream token has no expiry")
+	}
+	if got, err := s.ValidateStreamToken(streamToken); err != nil || got != userID {
+		t.Errorf("ValidateStreamToken = %s, %v; want %s", got, err, userID)
+	}
+	if _, err := s.ValidateToken(streamToken); err == nil {
+		t.Error("a stream token was accepted as a session token")
+	}
+
+	session, err := s.GenerateToken(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ValidateStreamToken(session); !errors.Is(err, ErrInvalidStreamToken) {
+		t.Errorf("session token as stream token: %v, want ErrInvalidStreamToken", err)
+	}
+	if got, err := s.ValidateToken(session); err != nil || got != userID {
+		t.Errorf("ValidateToken(session) = %s, %v", got, err)
+	}
+
+	other := NewAuthService(nil, "other secret")
+	if _, err := other.ValidateStreamToken(streamToken); !errors.Is(err, ErrInvalidStreamToken) {
+		t.Errorf("token signed with another secret: %v, want ErrInvalidStreamToken", err)
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"database/sql"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// getTaxSettings returns the provider's tax settings. Providers that have not
+// configured tax charge none.
+func getTaxSettings(q queryer, providerID uuid.UUID) (*models.TaxSettings, error) {
+	settings := models.TaxSettings{ProviderID: providerID}
+
+	err := q.QueryRow(`
+		SELECT rate, inclusive, registration_number, updated_at
+		FROM tax_settings WHERE provider_id = $1`, providerID).Scan(
+		&settings.Rate, &settings.Inclusive, &settings.RegistrationNumber, &settings.UpdatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// GetTaxSettings returns the tax settings of a provider.
+func (s *BookingService) GetTaxSettings(providerID uuid.UUID) (*models.TaxSettings, error) {
+	if err := requireProvider(s.db, providerID); err != nil {
+		return nil, err
+	}
+	return getTaxSettings(s.db, providerID)
+}
+
+// UpdateTaxSettings creates or updates a provider's tax settings; fields left
+// out of req keep their current values. Existing bookings keep the tax they
+// were priced with.
+func (s *BookingService) UpdateTaxSettings(providerID uuid.UUID, req models.UpdateTaxSettingsRequest) (*models.TaxSettings, error) {
+	if err := requireProvider(s.db, providerID); err != nil {
+		return nil, err
+	}
+
+	settings, err := getTaxSettings(s.db, providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Rate != nil {
+		settings.Rate = *req.Rate
+	}
+	if req.Inclusive != nil {
+		settings.Inclusive = *req.Inclusive
+	}
+	if req.RegistrationNumber != nil {
+		settings.RegistrationNumber = *req.RegistrationNumber
+	}
+	settings.UpdatedAt = time.Now()
+
+	_, err = s.db.Exec(`
+		INSERT INTO tax_settings (provider_id, rate, inclusive, registration_number, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (provider_id) DO UPDATE SET
+			rate = EXCLUDED.rate,
+			inclusive = EXCLUDED.inclusive,
+			registration_number = EXCLUDED.registration_number,
+			updated_at = EXCLUDED.updated_at`,
+		settings.ProviderID, settings.Rate, settings.Inclusive, settings.RegistrationNumber, settings.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
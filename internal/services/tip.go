@@ -0,0 +1,300 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTipNotAllowed = errors.New("tips can only be added to completed bookings")
+	ErrTipExists     = errors.New("a tip has already been added to this booking")
+	ErrInvalidTip    = errors.New("a tip needs either a positive amount or a percentage")
+	ErrTipNotFound   = errors.New("no tip has been added to this booking")
+	ErrTipStaffFixed = errors.New("tips on this booking go to the groomer who carried it out")
+)
+
+const tipColumns = `id, booking_id, user_id, provider_id, staff_id, amount, currency, percent, payment_id, created_at`
+
+func scanTip(row rowScanner) (*models.Tip, error) {
+	var tip models.Tip
+	var staffID, paymentID uuid.NullUUID
+	var percent sql.NullFloat64
+	err := row.Scan(
+		&tip.ID, &tip.BookingID, &tip.UserID, &tip.ProviderID, &staffID,
+		&tip.Amount.Amount, &tip.Amount.Currency, &percent, &paymentID, &tip.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if staffID.Valid {
+		tip.StaffID = &staffID.UUID
+	}
+	if percent.Valid {
+		tip.Percent = &percent.Float64
+	}
+	if paymentID.Valid {
+		tip.PaymentID = &paymentID.UUID
+	}
+	return &tip, nil
+}
+
+func getTip(q queryer, bookingID uuid.UUID) (*models.Tip, error) {
+	tip, err := scanTip(q.QueryRow(`SELECT `+tipColumns+` FROM tips WHERE booking_id = $1`, bookingID))
+	if err == sql.ErrNoRows {
+		return nil, ErrTipNotFound
+	}
+	return tip, err
+}
+
+// tipLine is the invoice line recording a tip.
+func tipLine(tip *models.Tip) models.InvoiceLine {
+	description := "Tip"
+	if tip.Percent != nil {
+		description += " (" + strconv.FormatFloat(*tip.Percent, 'f', -1, 64) + "%)"
+	}
+	return models.InvoiceLine{
+		Kind:        models.InvoiceLineTip,
+		Description: description,
+		Quantity:    1,
+		UnitAmount:  tip.Amount,
+		Amount:      tip.Amount,
+	}
+}
+
+// TipService records gratuities on completed bookings, charging them to the
+// booking's payment method when there is one.
+type TipService struct {
+	db       *sql.DB
+	payments *PaymentService
+	invoices *InvoiceService
+}
+
+func NewTipService(db *sql.DB, payments *PaymentService, invoices *InvoiceService) *TipService {
+	return &TipService{db: db, payments: payments, invoices: invoices}
+}
+
+// Add tips a completed booking on behalf of its owner. Each booking takes at
+// most one tip, credited to the booking's groomer or, when it had none, the
+// one the owner picks. If the tip cannot be charged it is not recorded.
+func (s *TipService) Add(bookingID, userID uuid.UUID, req models.AddTipRequest) (*models.Tip, error) {
+	if (req.Amount == nil) == (req.Percent == nil) {
+		return nil, ErrInvalidTip
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	booking, err := lockBooking(tx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID {
+		if booking.ProviderID == userID {
+			return nil, ErrForbidden
+		}
+		return nil, ErrBookingNotFound
+	}
+	if booking.Status != models.StatusCompleted {
+		return nil, ErrTipNotAllowed
+	}
+	if _, err := getTip(tx, booking.ID); err == nil {
+		return nil, ErrTipExists
+	} else if !errors.Is(err, ErrTipNotFound) {
+		return nil, err
+	}
+
+	staffID := booking.StaffID
+	if req.StaffID != nil {
+		if staffID != nil && *staffID != *req.StaffID {
+			return nil, ErrTipStaffFixed
+		}
+		if err := requireStaff(tx, *req.StaffID, booking.ProviderID); err != nil {
+			return nil, err
+		}
+		staffID = req.StaffID
+	}
+
+	tip := &models.Tip{
+		ID:         uuid.New(),
+		BookingID:  booking.ID,
+		UserID:     booking.UserID,
+		ProviderID: booking.ProviderID,
+		StaffID:    staffID,
+		Percent:    req.Percent,
+		CreatedAt:  time.Now(),
+	}
+	if req.Percent != nil {
+		tip.Amount = booking.TotalPrice.Percent(*req.Percent)
+	} else {
+		tip.Amount = *req.Amount
+		tip.Amount.Currency = models.CurrencyOr(tip.Amount.Currency, booking.TotalPrice.Currency)
+		if !tip.Amount.SameCurrency(booking.TotalPrice) {
+			return nil, models.ErrCurrencyMismatch
+		}
+	}
+	if !tip.Amount.IsPositive() {
+		return nil, ErrInvalidTip
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO tips (`+tipColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		tip.ID, tip.BookingID, tip.UserID, tip.ProviderID, tip.StaffID,
+		tip.Amount.Amount, tip.Amount.Currency, tip.Percent, tip.PaymentID, tip.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if booking.PaymentMethod != "" {
+		payment, err := s.payments.ChargeTip(booking, tip.Amount)
+		if err != nil {
+			if _, dbErr := s.db.Exec(`DELETE FROM tips WHERE id = $1`, tip.ID); dbErr != nil {
+				log.Printf("Failed to discard tip %s: %v", tip.ID, dbErr)
+			}
+			return nil, err
+		}
+		tip.PaymentID = &payment.ID
+		if _, err := s.db.Exec(`UPDATE tips SET payment_id = $1 WHERE id = $2`, tip.PaymentID, tip.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.invoices.addTip(tip); err != nil {
+		log.Printf("Failed to add tip %s to invoice: %v", tip.ID, err)
+	}
+	return tip, nil
+}
+
+// Get returns the tip on a booking, visible to its owner and provider.
+func (s *TipService) Get(bookingID, userID uuid.UUID) (*models.Tip, error) {
+	booking, err := getBookingRow(s.db, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID && booking.ProviderID != userID {
+		return nil, ErrBookingNotFound
+	}
+	return getTip(s.db, booking.ID)
+}
+
+// Report lists the tips a provider received in [from, to) with totals per
+// currency, overall and for each groomer.
+func (s *TipService) Report(providerID uuid.UUID, from, to time.Time) (*models.TipReport, error) {
+	if err := requireProvider(s.db, providerID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+tipColumns+` FROM tips
+		WHERE provider_id = $1 AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at`, providerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &models.TipReport{From: from, To: to, Totals: []models.Money{}, Tips: []models.Tip{}}
+	for rows.Next() {
+		tip, err := scanTip(rows)
+		if err != nil {
+			return nil, err
+		}
+		report.Tips = append(report.Tips, *tip)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report.Count = len(report.Tips)
+	for _, tip := range report.Tips {
+		report.Totals = addToTotals(report.Totals, tip.Amount)
+	}
+
+	report.ByStaff, err = tipsByStaff(s.db, providerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// tipsByStaff totals a provider's tips in [from, to) per groomer, the
+// provider's own first and then staff by name.
+func tipsByStaff(q queryer, providerID uuid.UUID, from, to time.Time) ([]models.TipStaffTotal, error) {
+	rows, err := q.Query(`
+		SELECT t.staff_id, COALESCE(sm.name, ''), t.currency, COUNT(*), SUM(t.amount)
+		FROM tips t
+		LEFT JOIN staff_members sm ON sm.id = t.staff_id
+		WHERE t.provider_id = $1 AND t.created_at >= $2 AND t.created_at < $3
+		GROUP BY t.staff_id, sm.name, t.currency
+		ORDER BY t.staff_id IS NOT NULL, sm.name, t.staff_id, t.currency`, providerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.TipStaffTotal{}
+	for rows.Next() {
+		var staffID uuid.NullUUID
+		var name string
+		var count int
+		var total models.Money
+		if err := rows.Scan(&staffID, &name, &total.Currency, &count, &total.Amount); err != nil {
+			return nil, err
+		}
+		var id *uuid.UUID
+		if staffID.Valid {
+			id = &staffID.UUID
+		}
+		groups = addStaffTotal(groups, id, name, count, total)
+	}
+	return groups, rows.Err()
+}
+
+// addStaffTotal adds count tips worth total to the group for staffID,
+// starting a new group when the last one is for someone else. Rows must
+// arrive grouped by staff member.
+func addStaffTotal(groups []models.TipStaffTotal, staffID *uuid.UUID, name string, count int, total models.Money) []models.TipStaffTotal {
+	if n := len(groups); n > 0 && sameStaff(groups[n-1].StaffID, staffID) {
+		groups[n-1].Count += count
+		groups[n-1].Totals = addToTotals(groups[n-1].Totals, total)
+		return groups
+	}
+	return append(groups, models.TipStaffTotal{
+		StaffID:   staffID,
+		StaffName: name,
+		Count:     count,
+		Totals:    []models.Money{total},
+	})
+}
+
+func sameStaff(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// addToTotals adds amount to the running total for its currency.
+func addToTotals(totals []models.Money, amount models.Money) []models.Money {
+	for i := range totals {
+		if totals[i].SameCurrency(amount) {
+			totals[i].Amount += amount.Amount
+			return totals
+		}
+	}
+	return append(totals, amount)
+}
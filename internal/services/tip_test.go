@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestAddToTotals(t *testing.T) {
+	var totals []models.Money
+	totals = addToTotals(totals, models.NewMoney(500, "USD"))
+	totals = addToTotals(totals, models.NewMoney(300, "EUR"))
+	totals = addToTotals(totals, models.NewMoney(250, "USD"))
+
+	want := []models.Money{models.NewMoney(750, "USD"), models.NewMoney(300, "EUR")}
+	if len(totals) != len(want) {
+		t.Fatalf("totals = %v, want %v", totals, want)
+	}
+	for i := range want {
+		if totals[i] != want[i] {
+			t.Errorf("totals[%d] = %s, want %s", i, totals[i], want[i])
+		}
+	}
+}
+
+func TestAddStaffTotal(t *testing.T) {
+	ana, ben := uuid.New(), uuid.New()
+
+	// Rows as tipsByStaff reads them: the provider's own, then each
+	// groomer's, one row per currency.
+	var groups []models.TipStaffTotal
+	groups = addStaffTotal(groups, nil, "", 1, models.NewMoney(400, "USD"))
+	groups = addStaffTotal(groups, &ana, "Ana", 2, models.NewMoney(1000, "EUR"))
+	groups = addStaffTotal(groups, &ana, "Ana", 3, models.NewMoney(1500, "USD"))
+	groups = addStaffTotal(groups, &ben, "Ben", 1, models.NewMoney(200, "USD"))
+
+	if len(groups) != 3 {
+		t.Fatalf("got %d groups, want provider, Ana and Ben", len(groups))
+	}
+	if groups[0].StaffID != nil || groups[0].Count != 1 {
+		t.Errorf("provider group = %+v", groups[0])
+	}
+	if g := groups[1]; g.StaffName != "Ana" || g.Count != 5 || len(g.Totals) != 2 {
+		t.Errorf("Ana's group = %+v, want 5 tips in two currencies", g)
+	}
+	if g := groups[2]; g.StaffID == nil || *g.StaffID != ben || g.Totals[0].Amount != 200 {
+		t.Errorf("Ben's group = %+v", g)
+	}
+}
@@ -0,0 +1,439 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/notify"
+	"pet-grooming-app/internal/realtime"
+
+	"github.com/google/uuid"
+)
+
+// WaitlistHoldDuration is how long a freed slot is held for the waitlisted
+// owner it was offered to before moving on to the next in line.
+const WaitlistHoldDuration = 30 * time.Minute
+
+var (
+	ErrWaitlistEntryNotFound = errors.New("waitlist entry not found")
+	ErrOfferNotActive        = errors.New("no active offer for this waitlist entry")
+	ErrInvalidWindow         = errors.New("window_end must be after window_start and in the future")
+)
+
+const waitlistColumns = `id, user_id, pet_id, service_id, provider_id, window_start, window_end, status, offered_time, hold_expires_at, booking_id, created_at, updated_at`
+
+func scanWaitlistEntry(row rowScanner) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	var offeredTime, holdExpiresAt sql.NullTime
+	var bookingID uuid.NullUUID
+	err := row.Scan(
+		&entry.ID, &entry.UserID, &entry.PetID, &entry.ServiceID, &entry.ProviderID,
+		&entry.WindowStart, &entry.WindowEnd, &entry.Status, &offeredTime, &holdExpiresAt,
+		&bookingID, &entry.CreatedAt, &entry.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if offeredTime.Valid {
+		entry.OfferedTime = &offeredTime.Time
+	}
+	if holdExpiresAt.Valid {
+		entry.HoldExpiresAt = &holdExpiresAt.Time
+	}
+	if bookingID.Valid {
+		entry.BookingID = &bookingID.UUID
+	}
+	return &entry, nil
+}
+
+type WaitlistService struct {
+	db       *sql.DB
+	payments *PaymentService
+	events   realtime.PubSub
+}
+
+func NewWaitlistService(db *sql.DB, payments *PaymentService, events realtime.PubSub) *WaitlistService {
+	return &WaitlistService{db: db, payments: payments, events: events}
+}
+
+// Join registers interest in any slot with the service's provider inside the
+// requested window.
+func (s *WaitlistService) Join(userID uuid.UUID, req models.CreateWaitlistEntryRequest) (*models.WaitlistEntry, error) {
+	if !req.WindowEnd.After(req.WindowStart) || !req.WindowEnd.After(time.Now()) {
+		return nil, ErrInvalidWindow
+	}
+
+	if err := checkPetOwner(s.db, req.PetID, userID); err != nil {
+		return nil, err
+	}
+
+	service, err := getService(s.db, req.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	if service.Category.IsStay() {
+		return nil, ErrStayNotRepeatable
+	}
+
+	now := time.Now()
+	entry := &models.WaitlistEntry{
+		ID:          uuid.New(),
+		UserID:      userID,
+		PetID:       req.PetID,
+		ServiceID:   service.ID,
+		ProviderID:  service.ProviderID,
+		WindowStart: req.WindowStart,
+		WindowEnd:   req.WindowEnd,
+		Status:      models.WaitlistWaiting,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO waitlist_entries (id, user_id, pet_id, service_id, provider_id, window_start, window_end, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		entry.ID, entry.UserID, entry.PetID, entry.ServiceID, entry.ProviderID,
+		entry.WindowStart, entry.WindowEnd, entry.Status, entry.CreatedAt, entry.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// List returns the owner's waitlist entries, newest first. Lapsed holds are
+// expired first so the caller never sees a stale offer.
+func (s *WaitlistService) List(userID uuid.UUID) ([]models.WaitlistEntry, error) {
+	if err := s.ExpireHolds(); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+waitlistColumns+` FROM waitlist_entries
+		WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.WaitlistEntry{}
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+func getWaitlistEntryForUpdate(tx *sql.Tx, id, userID uuid.UUID) (*models.WaitlistEntry, error) {
+	entry, err := scanWaitlistEntry(tx.QueryRow(`
+		SELECT `+waitlistColumns+` FROM waitlist_entries
+		WHERE id = $1 AND user_id = $2 FOR UPDATE`, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrWaitlistEntryNotFound
+	}
+	return entry, err
+}
+
+// Accept converts an active offer into a booking for the offered slot. If the
+// deposit cannot be collected the booking is discarded and the offer stands.
+func (s *WaitlistService) Accept(id, userID uuid.UUID, paymentMethod string) (*models.Booking, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	entry, err := getWaitlistEntryForUpdate(tx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.HoldActive(time.Now()) {
+		return nil, ErrOfferNotActive
+	}
+
+	if err := lockProvider(tx, entry.ProviderID); err != nil {
+		return nil, err
+	}
+
+	// Release the hold before booking so it does not block its own slot.
+	_, err = tx.Exec(`UPDATE waitlist_entries SET status = $1, updated_at = $2 WHERE id = $3`,
+		models.WaitlistBooked, time.Now(), entry.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	booking, err := createBooking(tx, userID, models.CreateBookingRequest{
+		PetID:         entry.PetID,
+		ServiceID:     entry.ServiceID,
+		ScheduledTime: *entry.OfferedTime,
+		PaymentMethod: paymentMethod,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`UPDATE waitlist_entries SET booking_id = $1 WHERE id = $2`, booking.ID, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if err := s.payments.CollectDeposit(booking); err != nil {
+		discardBookings(s.db, booking.ID)
+		if _, dbErr := s.db.Exec(`UPDATE waitlist_entries SET status = $1 WHERE id = $2`,
+			models.WaitlistOffered, entry.ID); dbErr != nil {
+			return nil, dbErr
+		}
+		return nil, err
+	}
+	publishBookingsAfterCommit(s.events, realtime.BookingCreated, *booking)
+	return booking, nil
+}
+
+// Decline turns down an offer. The owner stays on the waitlist for later
+// slots and the declined slot moves on to the next owner in line.
+func (s *WaitlistService) Decline(id, userID uuid.UUID) (*models.WaitlistEntry, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	entry, err := getWaitlistEntryForUpdate(tx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.HoldActive(time.Now()) {
+		return nil, ErrOfferNotActive
+	}
+
+	slot := *entry.OfferedTime
+	if err := resetOffer(tx, entry, models.WaitlistWaiting); err != nil {
+		return nil, err
+	}
+	if _, err := offerSlot(tx, entry.ProviderID, slot, entry.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Withdraw removes the owner from the waitlist, passing on any slot they were
+// holding.
+func (s *WaitlistService) Withdraw(id, userID uuid.UUID) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	entry, err := getWaitlistEntryForUpdate(tx, id, userID)
+	if err != nil {
+		return err
+	}
+	if entry.Status != models.WaitlistWaiting && entry.Status != models.WaitlistOffered {
+		return ErrWaitlistEntryNotFound
+	}
+
+	held := entry.HoldActive(time.Now())
+	slot := entry.OfferedTime
+	if err := resetOffer(tx, entry, models.WaitlistWithdrawn); err != nil {
+		return err
+	}
+	if held {
+		if _, err := offerSlot(tx, entry.ProviderID, *slot, entry.ID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Run expires lapsed holds every interval until ctx is cancelled, so slots
+// move down the waitlist whether or not anyone is looking at it.
+func (s *WaitlistService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.ExpireHolds(); err != nil {
+			log.Printf("Failed to expire waitlist holds: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireHolds marks lapsed offers as expired and re-offers their slots to the
+// next owner in line. It runs from Run and again before waitlist reads; rows
+// being processed by another instance are skipped.
+func (s *WaitlistService) ExpireHolds() error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT ` + waitlistColumns + ` FROM waitlist_entries
+		WHERE status = 'offered' AND hold_expires_at <= NOW()
+		ORDER BY hold_expires_at
+		FOR UPDATE SKIP LOCKED`)
+	if err != nil {
+		return err
+	}
+
+	var expired []*models.WaitlistEntry
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		expired = append(expired, entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, entry := range expired {
+		slot := *entry.OfferedTime
+		if err := resetOffer(tx, entry, models.WaitlistExpired); err != nil {
+			return err
+		}
+		if slot.After(now) {
+			if _, err := offerSlot(tx, entry.ProviderID, slot, entry.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func resetOffer(tx *sql.Tx, entry *models.WaitlistEntry, status models.WaitlistStatus) error {
+	entry.Status = status
+	entry.OfferedTime = nil
+	entry.HoldExpiresAt = nil
+	entry.UpdatedAt = time.Now()
+
+	_, err := tx.Exec(`
+		UPDATE waitlist_entries
+		SET status = $1, offered_time = NULL, hold_expires_at = NULL, updated_at = $2
+		WHERE id = $3`, entry.Status, entry.UpdatedAt, entry.ID)
+	return err
+}
+
+// offerSlot offers a freed slot to the oldest waiting entry for the provider
+// whose window covers it and whose service fits, holding it for
+// WaitlistHoldDuration, and queues a notification telling the owner. Entries
+// in skip are passed over. It returns the entry that received the offer, or
+// nil if nobody was eligible.
+func offerSlot(tx *sql.Tx, providerID uuid.UUID, slot time.Time, skip ...uuid.UUID) (*models.WaitlistEntry, error) {
+	rows, err := tx.Query(`
+		SELECT `+waitlistColumns+` FROM waitlist_entries
+		WHERE provider_id = $1 AND status = 'waiting'
+			AND window_start <= $2 AND window_end > $2
+		ORDER BY created_at
+		FOR UPDATE SKIP LOCKED`, providerID, slot)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*models.WaitlistEntry
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		candidates = append(candidates, entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+candidates:
+	for _, entry := range candidates {
+		for _, id := range skip {
+			if entry.ID == id {
+				continue candidates
+			}
+		}
+
+		service, err := getService(tx, entry.ServiceID)
+		if err != nil {
+			return nil, err
+		}
+		_, err = checkAvailability(tx, service, nil, slot, nil)
+		if errors.Is(err, ErrSlotUnavailable) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		holdExpiresAt := time.Now().Add(WaitlistHoldDuration)
+		entry.Status = models.WaitlistOffered
+		entry.OfferedTime = &slot
+		entry.HoldExpiresAt = &holdExpiresAt
+		entry.UpdatedAt = time.Now()
+
+		_, err = tx.Exec(`
+			UPDATE waitlist_entries
+			SET status = $1, offered_time = $2, hold_expires_at = $3, updated_at = $4
+			WHERE id = $5`,
+			entry.Status, entry.OfferedTime, entry.HoldExpiresAt, entry.UpdatedAt, entry.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := notifyOffer(tx, entry, service); err != nil {
+			return nil, err
+		}
+		return entry, nil
+	}
+
+	return nil, nil
+}
+
+// notifyOffer tells the owner of entry about the slot it has just been
+// offered and how long it is held for.
+func notifyOffer(q queryer, entry *models.WaitlistEntry, service *models.Service) error {
+	owner, err := getContact(q, entry.UserID)
+	if err != nil {
+		return err
+	}
+	provider, err := getContact(q, entry.ProviderID)
+	if err != nil {
+		return err
+	}
+	data := notify.BookingData{
+		RecipientName: owner.name,
+		OwnerName:     owner.name,
+		ProviderName:  provider.name,
+		ServiceName:   service.Name,
+		ScheduledTime: *entry.OfferedTime,
+		HoldExpiresAt: *entry.HoldExpiresAt,
+	}
+	if err := q.QueryRow(`SELECT name FROM pets WHERE id = $1`, entry.PetID).Scan(&data.PetName); err != nil {
+		return err
+	}
+	return enqueueNotification(q, owner, models.EventWaitlistOffer, nil, data)
+}
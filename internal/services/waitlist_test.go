@@ -0,0 +1,86 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"pet-grooming-app/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// seedWaitlistEntry adds a new owner waiting for booking's service between
+// windowStart and windowEnd, as if they joined at joined.
+func seedWaitlistEntry(t *testing.T, db *sql.DB, booking *models.Booking, windowStart, windowEnd, joined time.Time) uuid.UUID {
+	t.Helper()
+	ownerID := seedUser(t, db, "owner")
+	petID, id := uuid.New(), uuid.New()
+	if _, err := db.Exec(`INSERT INTO pets (id, owner_id, name, species) VALUES ($1, $2, 'Rex', 'dog')`, petID, ownerID); err != nil {
+		t.Fatal(err)
+	}
+	_, err := db.Exec(`
+		INSERT INTO waitlist_entries (id, user_id, pet_id, service_id, provider_id, window_start, window_end, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'waiting', $8, $8)`,
+		id, ownerID, petID, booking.ServiceID, booking.ProviderID, windowStart, windowEnd, joined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// offer runs offerSlot in its own transaction, committing it when commit is
+// set, and returns the ID of the entry offered the slot.
+func offer(t *testing.T, db *sql.DB, booking *models.Booking, slot time.Time, commit bool, skip ...uuid.UUID) uuid.UUID {
+	t.Helper()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	entry, err := offerSlot(tx, booking.ProviderID, slot, skip...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit {
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if entry == nil {
+		return uuid.Nil
+	}
+	return entry.ID
+}
+
+func TestOfferSlotGoesToLongestWaiting(t *testing.T) {
+	db := openTestDB(t)
+	booking := seedBooking(t, db, models.ServiceGrooming, models.NewMoney(5000, "USD"), models.Zero("USD"), "card")
+	slot := booking.ScheduledTime.Add(2 * time.Hour)
+	now := time.Now()
+
+	// The longest waiting owner wants a different day.
+	seedWaitlistEntry(t, db, booking, slot.Add(24*time.Hour), slot.Add(48*time.Hour), now.Add(-3*time.Hour))
+	first := seedWaitlistEntry(t, db, booking, slot.Add(-time.Hour), slot.Add(time.Hour), now.Add(-2*time.Hour))
+	second := seedWaitlistEntry(t, db, booking, slot.Add(-time.Hour), slot.Add(time.Hour), now.Add(-time.Hour))
+
+	if got := offer(t, db, booking, slot, false); got != first {
+		t.Errorf("offered to %s, want the longest waiting owner whose window fits %s", got, first)
+	}
+	// An owner who already turned the slot down is passed over.
+	if got := offer(t, db, booking, slot, false, first); got != second {
+		t.Errorf("offered to %s after skipping, want %s", got, second)
+	}
+	// A slot the provider is busy for is not offered at all.
+	if got := offer(t, db, booking, booking.ScheduledTime, false); got != uuid.Nil {
+		t.Errorf("offered a booked slot to %s", got)
+	}
+
+	// Once offered, the hold keeps the slot from the next in line.
+	if got := offer(t, db, booking, slot, true); got != first {
+		t.Fatalf("offered to %s, want %s", got, first)
+	}
+	if got := offer(t, db, booking, slot, false); got != uuid.Nil {
+		t.Errorf("a held slot was offered again to %s", got)
+	}
+}
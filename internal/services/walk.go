@@ -0,0 +1,340 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"pet-grooming-app/internal/geo"
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/realtime"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotWalk            = errors.New("only walking bookings have walks")
+	ErrWalkNotFound       = errors.New("walk has not started")
+	ErrWalkAlreadyStarted = errors.New("walk has already started")
+	ErrWalkEnded          = errors.New("walk has already ended")
+)
+
+// walkRow is a walk as stored, with its encoded track.
+type walkRow struct {
+	models.Walk
+	track []byte
+}
+
+func getWalk(q queryer, bookingID uuid.UUID, forUpdate bool) (*walkRow, error) {
+	query := `
+		SELECT booking_id, started_at, ended_at, distance_meters, point_count, track
+		FROM walks WHERE booking_id = $1`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	var w walkRow
+	var endedAt sql.NullTime
+	err := q.QueryRow(query, bookingID).Scan(
+		&w.BookingID, &w.StartedAt, &endedAt, &w.DistanceMeters, &w.PointCount, &w.track)
+	if err == sql.ErrNoRows {
+		return nil, ErrWalkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if endedAt.Valid {
+		w.EndedAt = &endedAt.Time
+	}
+	return &w, nil
+}
+
+// WalkService records dog walks: the walker's GPS track and what the dog did
+// on the way, reported to the owner.
+type WalkService struct {
+	db     *sql.DB
+	events realtime.PubSub
+}
+
+func NewWalkService(db *sql.DB, events realtime.PubSub) *WalkService {
+	return &WalkService{db: db, events: events}
+}
+
+// walkBooking loads a walking booking the provider is carrying out.
+func walkBooking(q queryer, bookingID, providerID uuid.UUID) (*models.Booking, error) {
+	booking, err := getBookingRow(q, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.ProviderID != providerID {
+		if booking.UserID == providerID {
+			return nil, ErrForbidden
+		}
+		return nil, ErrBookingNotFound
+	}
+	service, err := getService(q, booking.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	if service.Category != models.ServiceWalking {
+		return nil, ErrNotWalk
+	}
+	return booking, nil
+}
+
+// Start begins the walk for a booking.
+func (s *WalkService) Start(bookingID, providerID uuid.UUID) (*models.Walk, error) {
+	booking, err := walkBooking(s.db, bookingID, providerID)
+	if err != nil {
+		return nil, err
+	}
+	if !booking.Status.IsActive() {
+		return nil, ErrBookingInactive
+	}
+
+	now := time.Now()
+	result, err := s.db.Exec(`
+		INSERT INTO walks (booking_id, started_at, distance_meters, point_count, track, updated_at)
+		VALUES ($1, $2, 0, 0, $3, $2)
+		ON CONFLICT (booking_id) DO NOTHING`, booking.ID, now, geo.Encode(nil))
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, ErrWalkAlreadyStarted
+	}
+
+	walk, err := s.report(s.db, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	publishAfterCommit(s.events, realtime.WalkStarted, booking.ID, walk, booking.UserID, booking.ProviderID)
+	return walk, nil
+}
+
+// Record adds points and events to a walk in progress.
+func (s *WalkService) Record(bookingID, providerID uuid.UUID, req models.RecordWalkRequest) (*models.Walk, error) {
+	booking, err := walkBooking(s.db, bookingID, providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	w, err := getWalk(tx, booking.ID, true)
+	if err != nil {
+		return nil, err
+	}
+	if w.EndedAt != nil {
+		return nil, ErrWalkEnded
+	}
+	if err := recordTrack(tx, w, req.Points); err != nil {
+		return nil, err
+	}
+	for _, e := range req.Events {
+		if _, err := tx.Exec(`
+			INSERT INTO walk_events (id, booking_id, kind, lat, lng, occurred_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (booking_id, kind, occurred_at) DO NOTHING`,
+			uuid.New(), booking.ID, e.Kind, e.Lat, e.Lng, e.Time); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	walk, err := s.report(s.db, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	publishAfterCommit(s.events, realtime.WalkUpdated, booking.ID, walk, booking.UserID, booking.ProviderID)
+	return walk, nil
+}
+
+// recordTrack appends the points after the end of w's track to it and
+// updates its distance.
+func recordTrack(tx *sql.Tx, w *walkRow, points []models.TrackPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	track, err := geo.Decode(w.track)
+	if err != nil {
+		return err
+	}
+
+	fresh := make([]geo.Point, 0, len(points))
+	for _, p := range points {
+		fresh = append(fresh, geo.Point{Lat: p.Lat, Lng: p.Lng, Time: p.Time.Truncate(time.Second)})
+	}
+	sort.SliceStable(fresh, func(i, j int) bool { return fresh[i].Time.Before(fresh[j].Time) })
+
+	var last *geo.Point
+	if len(track) > 0 {
+		last = &track[len(track)-1]
+	}
+	added := 0
+	for _, p := range fresh {
+		if p.Time.Before(w.StartedAt.Truncate(time.Second)) || (last != nil && !p.Time.After(last.Time)) {
+			continue
+		}
+		if last != nil {
+			w.DistanceMeters += geo.Distance([]geo.Point{*last, p})
+		}
+		track = append(track, p)
+		last = &track[len(track)-1]
+		added++
+	}
+	if added == 0 {
+		return nil
+	}
+
+	w.PointCount = len(track)
+	_, err = tx.Exec(`
+		UPDATE walks SET track = $1, point_count = $2, distance_meters = $3, updated_at = $4
+		WHERE booking_id = $5`,
+		geo.Encode(track), w.PointCount, w.DistanceMeters, time.Now(), w.BookingID)
+	return err
+}
+
+// Stop ends a walk.
+func (s *WalkService) Stop(bookingID, providerID uuid.UUID) (*models.Walk, error) {
+	booking, err := walkBooking(s.db, bookingID, providerID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.db.Exec(`
+		UPDATE walks SET ended_at = $1, updated_at = $1
+		WHERE booking_id = $2 AND ended_at IS NULL`, time.Now(), booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		if _, err := getWalk(s.db, booking.ID, false); err != nil {
+			return nil, err
+		}
+		return nil, ErrWalkEnded
+	}
+
+	walk, err := s.report(s.db, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	publishAfterCommit(s.events, realtime.WalkEnded, booking.ID, walk, booking.UserID, booking.ProviderID)
+	return walk, nil
+}
+
+// viewableWalk loads the walk for a booking userID owns or provides.
+func viewableWalk(q queryer, bookingID, userID uuid.UUID) (*walkRow, error) {
+	booking, err := getBookingRow(q, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID && booking.ProviderID != userID {
+		return nil, ErrBookingNotFound
+	}
+	return getWalk(q, booking.ID, false)
+}
+
+// Get returns the walk report for a booking's owner or provider.
+func (s *WalkService) Get(bookingID, userID uuid.UUID) (*models.Walk, error) {
+	if _, err := viewableWalk(s.db, bookingID, userID); err != nil {
+		return nil, err
+	}
+	return s.report(s.db, bookingID)
+}
+
+func (s *WalkService) report(q queryer, bookingID uuid.UUID) (*models.Walk, error) {
+	w, err := getWalk(q, bookingID, false)
+	if err != nil {
+		return nil, err
+	}
+	walk := w.Walk
+
+	end := time.Now()
+	if walk.EndedAt != nil {
+		end = *walk.EndedAt
+	}
+	walk.DurationSeconds = int64(end.Sub(walk.StartedAt) / time.Second)
+
+	if walk.Events, err = walkEvents(q, bookingID); err != nil {
+		return nil, err
+	}
+	walk.EventCounts = map[models.WalkEventKind]int{models.WalkPee: 0, models.WalkPoo: 0, models.WalkWater: 0}
+	for _, e := range walk.Events {
+		walk.EventCounts[e.Kind]++
+	}
+	return &walk, nil
+}
+
+func walkEvents(q queryer, bookingID uuid.UUID) ([]models.WalkEvent, error) {
+	rows, err := q.Query(`
+		SELECT id, booking_id, kind, lat, lng, occurred_at FROM walk_events
+		WHERE booking_id = $1
+		ORDER BY occurred_at`, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.WalkEvent{}
+	for rows.Next() {
+		var e models.WalkEvent
+		if err := rows.Scan(&e.ID, &e.BookingID, &e.Kind, &e.Lat, &e.Lng, &e.Time); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// GeoJSON returns a booking's walk as a GeoJSON feature collection: the
+// route as a LineString, or a Point while it has a single fix, followed by
+// a Point for each event.
+func (s *WalkService) GeoJSON(bookingID, userID uuid.UUID) (*geo.FeatureCollection, error) {
+	w, err := viewableWalk(s.db, bookingID, userID)
+	if err != nil {
+		return nil, err
+	}
+	track, err := geo.Decode(w.track)
+	if err != nil {
+		return nil, err
+	}
+	events, err := walkEvents(s.db, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	fc := geo.NewFeatureCollection()
+	route := map[string]any{
+		"booking_id":      w.BookingID,
+		"started_at":      w.StartedAt,
+		"ended_at":        w.EndedAt,
+		"distance_meters": w.DistanceMeters,
+	}
+	if len(track) > 0 {
+		times := make([]time.Time, len(track))
+		for i, p := range track {
+			times[i] = p.Time
+		}
+		route["times"] = times
+	}
+	switch {
+	case len(track) == 1:
+		fc.Add(geo.PointGeometry(track[0]), route)
+	case len(track) > 1:
+		fc.Add(geo.LineString(track), route)
+	}
+	for _, e := range events {
+		fc.Add(geo.PointGeometry(geo.Point{Lat: e.Lat, Lng: e.Lng}), map[string]any{
+			"kind": e.Kind,
+			"time": e.Time,
+		})
+	}
+	return fc, nil
+}
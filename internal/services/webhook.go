@@ -0,0 +1,468 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"pet-grooming-app/internal/models"
+	"pet-grooming-app/internal/safehttp"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrWebhookNotFound     = errors.New("webhook not found")
+	ErrInvalidWebhookURL   = errors.New("webhook URL must be an absolute http or https URL")
+	ErrWebhookURLNotPublic = errors.New("webhook URL must resolve to a public address")
+	ErrInvalidWebhookEvent = errors.New("unknown webhook event")
+)
+
+const (
+	// webhookBatchSize caps how many deliveries one dispatch pass claims.
+	webhookBatchSize = 50
+	// webhookLease is how long a claimed delivery is hidden from other
+	// dispatchers.
+	webhookLease = 2 * time.Minute
+	// webhookTimeout bounds each POST, so a slow receiver cannot hold up
+	// the rest of the batch for long.
+	webhookTimeout = 10 * time.Second
+	// maxWebhookAttempts is when delivery is given up on. With the backoff
+	// doubling from a minute, the last attempt is about two hours after the
+	// first.
+	maxWebhookAttempts = 8
+)
+
+const webhookColumns = `id, provider_id, url, events, secret, active, created_at`
+
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var w models.Webhook
+	var events []string
+	if err := row.Scan(&w.ID, &w.ProviderID, &w.URL, pq.Array(&events), &w.Secret, &w.Active, &w.CreatedAt); err != nil {
+		return nil, err
+	}
+	w.Events = make([]models.WebhookEvent, len(events))
+	for i, e := range events {
+		w.Events[i] = models.WebhookEvent(e)
+	}
+	return &w, nil
+}
+
+const webhookDeliveryColumns = `id, webhook_id, event, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at`
+
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var payload []byte
+	var responseStatus sql.NullInt64
+	var deliveredAt sql.NullTime
+	err := row.Scan(&d.ID, &d.WebhookID, &d.Event, &payload, &d.Status, &d.Attempts, &responseStatus,
+		&d.LastError, &d.NextAttemptAt, &d.CreatedAt, &deliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	if responseStatus.Valid {
+		status := int(responseStatus.Int64)
+		d.ResponseStatus = &status
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return &d, nil
+}
+
+// enqueueWebhooks queues event for each of the provider's webhooks that
+// subscribes to it, through q, normally the transaction making the change.
+// data is sent as the payload's data field.
+func enqueueWebhooks(q queryer, providerID uuid.UUID, event models.WebhookEvent, data any) error {
+	rows, err := q.Query(`
+		SELECT id FROM webhooks
+		WHERE provider_id = $1 AND active AND $2 = ANY(events)`, providerID, event)
+	if err != nil {
+		return err
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(ids) == 0 {
+		return err
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := insertWebhookDelivery(q, id, event, body, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertWebhookDelivery logs a delivery to be sent from due onwards.
+func insertWebhookDelivery(q queryer, webhookID uuid.UUID, event models.WebhookEvent, data json.RawMessage, due time.Time) (*models.WebhookDelivery, error) {
+	now := time.Now()
+	d := &models.WebhookDelivery{
+		ID:            uuid.New(),
+		WebhookID:     webhookID,
+		Event:         event,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: due,
+		CreatedAt:     now,
+	}
+	payload, err := json.Marshal(models.WebhookPayload{ID: d.ID, Event: event, CreatedAt: now, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+
+	_, err = q.Exec(`
+		INSERT INTO webhook_deliveries (`+webhookDeliveryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, 0, NULL, '', $6, $7, NULL)`,
+		d.ID, d.WebhookID, d.Event, []byte(d.Payload), d.Status, d.NextAttemptAt, d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// webhookBookings queues event for each booking's provider.
+func webhookBookings(q queryer, event models.WebhookEvent, bookings ...models.Booking) error {
+	for i := range bookings {
+		if err := enqueueWebhooks(q, bookings[i].ProviderID, event, &bookings[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// webhookPayment queues the payment event matching payment's outcome for the
+// booking's provider.
+func webhookPayment(q queryer, booking *models.Booking, payment *models.Payment) error {
+	event := models.WebhookPaymentSucceeded
+	switch payment.Status {
+	case models.PaymentRecordFailed:
+		event = models.WebhookPaymentFailed
+	case models.PaymentRecordPartiallyRefunded, models.PaymentRecordRefunded:
+		event = models.WebhookPaymentRefunded
+	}
+	return enqueueWebhooks(q, booking.ProviderID, event, payment)
+}
+
+// signWebhook returns the X-Webhook-Signature header for body sent at t: the
+// timestamp and a hex HMAC-SHA256 of "timestamp.body". Receivers recompute
+// it with their secret and reject stale timestamps to stop replays.
+func signWebhook(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// WebhookService manages providers' webhook subscriptions and delivers the
+// queued events to them.
+type WebhookService struct {
+	db     *sql.DB
+	client *http.Client
+}
+
+// NewWebhookService delivers with a client that only connects to public
+// addresses, so webhooks cannot be used to probe the internal network.
+func NewWebhookService(db *sql.DB) *WebhookService {
+	return &WebhookService{db: db, client: safehttp.NewClient(webhookTimeout)}
+}
+
+// Create subscribes the provider's URL to req.Events. The URL's host must
+// resolve to public addresses. The returned webhook is the only time its
+// secret is shown.
+func (s *WebhookService) Create(providerID uuid.UUID, req models.CreateWebhookRequest) (*models.Webhook, error) {
+	if err := requireProvider(s.db, providerID); err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	if err := safehttp.CheckURL(ctx, u.String()); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebhookURLNotPublic, err)
+	}
+	events := make([]string, len(req.Events))
+	for i, event := range req.Events {
+		if !event.IsValid() {
+			return nil, ErrInvalidWebhookEvent
+		}
+		events[i] = string(event)
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	webhook := &models.Webhook{
+		ID:         uuid.New(),
+		ProviderID: providerID,
+		URL:        u.String(),
+		Events:     req.Events,
+		Secret:     secret,
+		Active:     true,
+		CreatedAt:  time.Now(),
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO webhooks (`+webhookColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		webhook.ID, webhook.ProviderID, webhook.URL, pq.Array(events), webhook.Secret, webhook.Active, webhook.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// List returns the provider's active webhooks, without their secrets.
+func (s *WebhookService) List(providerID uuid.UUID) ([]models.Webhook, error) {
+	rows, err := s.db.Query(`
+		SELECT `+webhookColumns+` FROM webhooks
+		WHERE provider_id = $1 AND active ORDER BY created_at`, providerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		w.Secret = ""
+		webhooks = append(webhooks, *w)
+	}
+	return webhooks, rows.Err()
+}
+
+func (s *WebhookService) get(id, providerID uuid.UUID) (*models.Webhook, error) {
+	w, err := scanWebhook(s.db.QueryRow(`SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id))
+	if err == sql.ErrNoRows || (err == nil && (w.ProviderID != providerID || !w.Active)) {
+		return nil, ErrWebhookNotFound
+	}
+	return w, err
+}
+
+// Delete unsubscribes a webhook. Its delivery log is kept, and anything
+// still queued for it is abandoned.
+func (s *WebhookService) Delete(id, providerID uuid.UUID) error {
+	if _, err := s.get(id, providerID); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`UPDATE webhooks SET active = FALSE WHERE id = $1`, id)
+	return err
+}
+
+// Deliveries returns the webhook's most recent deliveries, newest first.
+func (s *WebhookService) Deliveries(id, providerID uuid.UUID) ([]models.WebhookDelivery, error) {
+	if _, err := s.get(id, providerID); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(`
+		SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries
+		WHERE webhook_id = $1 ORDER BY created_at DESC LIMIT 100`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *d)
+	}
+	return deliveries, rows.Err()
+}
+
+// Test sends a ping to the webhook straight away and returns the logged
+// delivery, so providers can check their endpoint and signature handling.
+// Test deliveries are not retried.
+func (s *WebhookService) Test(ctx context.Context, id, providerID uuid.UUID) (*models.WebhookDelivery, error) {
+	webhook, err := s.get(id, providerID)
+	if err != nil {
+		return nil, err
+	}
+	// The delivery starts out leased, so dispatchers leave it to us.
+	d, err := insertWebhookDelivery(s.db, webhook.ID, models.WebhookPing, json.RawMessage(`{}`), time.Now().Add(webhookLease))
+	if err != nil {
+		return nil, err
+	}
+	d.Attempts = 1
+	status, sendErr := s.send(ctx, webhook, d)
+	if err := s.record(d, status, sendErr, false); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Run dispatches queued deliveries every interval until ctx is cancelled.
+func (s *WebhookService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Dispatch(ctx); err != nil {
+			log.Printf("Failed to dispatch webhooks: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch claims a batch of due deliveries and sends them, returning how
+// many succeeded. Claiming works as for notifications, so several instances
+// can dispatch at once.
+func (s *WebhookService) Dispatch(ctx context.Context) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE webhook_deliveries SET attempts = attempts + 1, next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+webhookDeliveryColumns, time.Now().Add(webhookLease), webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var claimed []*models.WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		claimed = append(claimed, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	webhooks := make(map[uuid.UUID]*models.Webhook)
+	sent := 0
+	for _, d := range claimed {
+		webhook, ok := webhooks[d.WebhookID]
+		if !ok {
+			webhook, err = scanWebhook(s.db.QueryRowContext(ctx,
+				`SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, d.WebhookID))
+			if err != nil {
+				return sent, err
+			}
+			webhooks[d.WebhookID] = webhook
+		}
+
+		var status int
+		sendErr := errors.New("webhook was deleted")
+		retry := false
+		if webhook.Active {
+			status, sendErr = s.send(ctx, webhook, d)
+			retry = true
+		}
+		if sendErr == nil {
+			sent++
+		}
+		if err := s.record(d, status, sendErr, retry); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// send POSTs the delivery's payload and returns the response status. Any
+// 2xx response counts as delivered; redirects are not followed.
+func (s *WebhookService) send(ctx context.Context, webhook *models.Webhook, d *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pet-grooming-app-webhooks/1")
+	req.Header.Set("X-Webhook-Event", string(d.Event))
+	req.Header.Set("X-Webhook-Delivery", d.ID.String())
+	req.Header.Set("X-Webhook-Signature", signWebhook(webhook.Secret, time.Now(), d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// record stores the outcome of a delivery attempt. Failures are retried with
+// exponential backoff until the attempts are used up.
+func (s *WebhookService) record(d *models.WebhookDelivery, status int, sendErr error, retry bool) error {
+	now := time.Now()
+	if status != 0 {
+		d.ResponseStatus = &status
+	}
+	switch {
+	case sendErr == nil:
+		d.Status = models.WebhookDeliverySucceeded
+		d.DeliveredAt = &now
+		d.LastError = ""
+	case !retry || d.Attempts >= maxWebhookAttempts:
+		d.Status = models.WebhookDeliveryFailed
+		d.LastError = sendErr.Error()
+	default:
+		d.NextAttemptAt = now.Add(time.Minute << (d.Attempts - 1))
+		d.LastError = sendErr.Error()
+	}
+
+	_, err := s.db.Exec(`
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, response_status = $3, last_error = $4, next_attempt_at = $5, delivered_at = $6
+		WHERE id = $7`,
+		d.Status, d.Attempts, d.ResponseStatus, d.LastError, d.NextAttemptAt, d.DeliveredAt, d.ID)
+	return err
+}
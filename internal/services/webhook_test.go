@@ -0,0 +1,58 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"pet-grooming-app/internal/models"
+)
+
+func TestSignWebhook(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	body := []byte(`{"event":"booking.created"}`)
+
+	header := signWebhook("whsec_test", at, body)
+	timestamp, signature, ok := strings.Cut(header, ",v1=")
+	if !ok || timestamp != "t=1700000000" {
+		t.Fatalf("header = %q, want t=<unix>,v1=<hex>", header)
+	}
+
+	// What a receiver does: HMAC "timestamp.body" with the shared secret.
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte("1700000000." + string(body)))
+	if want := hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Errorf("signature = %s, want %s", signature, want)
+	}
+
+	if signWebhook("other", at, body) == header {
+		t.Error("different secrets gave the same signature")
+	}
+	if signWebhook("whsec_test", at.Add(time.Second), body) == header {
+		t.Error("different timestamps gave the same signature")
+	}
+}
+
+func TestCreateWebhookRejectsInternalURLs(t *testing.T) {
+	db := openTestDB(t)
+	s := NewWebhookService(db)
+	providerID := seedUser(t, db, "provider")
+
+	for _, u := range []string{
+		"http://localhost:8080/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.5/hook",
+	} {
+		_, err := s.Create(providerID, models.CreateWebhookRequest{URL: u, Events: []models.WebhookEvent{models.WebhookBookingCreated}})
+		if !errors.Is(err, ErrWebhookURLNotPublic) {
+			t.Errorf("Create(%s): %v, want ErrWebhookURLNotPublic", u, err)
+		}
+	}
+	if _, err := s.Create(providerID, models.CreateWebhookRequest{URL: "ftp://example.com/"}); !errors.Is(err, ErrInvalidWebhookURL) {
+		t.Errorf("ftp URL: %v, want ErrInvalidWebhookURL", err)
+	}
+}
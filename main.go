@@ -43,7 +43,10 @@ func main() {
 	}
 
 	// Initialize API server
-	server := api.NewServer(db, cfg)
+	server, err := api.NewServer(db, cfg)
+	if err != nil {
+		log.Fatal("Failed to configure server: ", err)
+	}
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -1,3 +1,4 @@
+import 'money.dart';
 import 'pet.dart';
 import 'service.dart';
 import 'user.dart';
@@ -11,7 +12,7 @@ class Booking {
   final DateTime scheduledTime;
   final BookingStatus status;
   final String? notes;
-  final double totalPrice;
+  final Money totalPrice;
   final DateTime createdAt;
   final DateTime updatedAt;
 
@@ -29,7 +30,7 @@ class Booking {
     required this.updatedAt,
   });
 
-  String get formattedPrice => '\$${totalPrice.toStringAsFixed(2)}';
+  String get formattedPrice => totalPrice.formatted;
 
   factory Booking.fromJson(Map<String, dynamic> json) {
     return Booking(
@@ -41,7 +42,7 @@ class Booking {
       scheduledTime: DateTime.parse(json['scheduled_time']),
       status: BookingStatus.fromString(json['status']),
       notes: json['notes'],
-      totalPrice: json['total_price'].toDouble(),
+      totalPrice: Money.fromJson(json['total_price']),
       createdAt: DateTime.parse(json['created_at']),
       updatedAt: DateTime.parse(json['updated_at']),
     );
@@ -57,7 +58,7 @@ class Booking {
       'scheduled_time': scheduledTime.toIso8601String(),
       'status': status.value,
       'notes': notes,
-      'total_price': totalPrice,
+      'total_price': totalPrice.toJson(),
       'created_at': createdAt.toIso8601String(),
       'updated_at': updatedAt.toIso8601String(),
     };
@@ -69,7 +70,8 @@ enum BookingStatus {
   confirmed('confirmed'),
   inProgress('in_progress'),
   completed('completed'),
-  cancelled('cancelled');
+  cancelled('cancelled'),
+  noShow('no_show');
 
   const BookingStatus(this.value);
   final String value;
@@ -90,6 +92,8 @@ enum BookingStatus {
         return 'Completed';
       case BookingStatus.cancelled:
         return 'Cancelled';
+      case BookingStatus.noShow:
+        return 'No-show';
     }
   }
 }
@@ -0,0 +1,48 @@
+import 'dart:math';
+
+import 'package:intl/intl.dart';
+
+/// An exact amount in the minor units (e.g. cents) of an ISO 4217 currency,
+/// as sent by the API: `{"amount": 1250, "currency": "USD"}`.
+class Money {
+  final int amount;
+  final String currency;
+
+  const Money({required this.amount, required this.currency});
+
+  /// Currencies whose minor unit is not a hundredth of the major unit. Kept
+  /// in step with the server's list.
+  static const Map<String, int> _exponents = {
+    'BHD': 3, 'CLP': 0, 'ISK': 0, 'JOD': 3, 'JPY': 0,
+    'KRW': 0, 'KWD': 3, 'OMR': 3, 'TND': 3, 'VND': 0,
+  };
+
+  /// How many digits of the amount fall after the decimal point.
+  int get exponent => _exponents[currency] ?? 2;
+
+  /// The amount in major units, for display only.
+  double get majorUnits => amount / pow(10, exponent);
+
+  /// Formats the amount with the currency's symbol and number of decimals,
+  /// e.g. "$12.50" or "¥1,500".
+  String get formatted =>
+      NumberFormat.simpleCurrency(name: currency, decimalDigits: exponent)
+          .format(majorUnits);
+
+  factory Money.fromJson(Map<String, dynamic> json) {
+    return Money(
+      amount: (json['amount'] as num).toInt(),
+      currency: json['currency'] ?? 'USD',
+    );
+  }
+
+  Map<String, dynamic> toJson() {
+    return {
+      'amount': amount,
+      'currency': currency,
+    };
+  }
+
+  @override
+  String toString() => formatted;
+}
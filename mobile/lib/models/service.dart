@@ -1,10 +1,12 @@
+import 'money.dart';
+
 class Service {
   final String id;
   final String providerId;
   final String name;
   final String description;
   final ServiceType category;
-  final double price;
+  final Money price;
   final int duration; // in minutes
   final bool available;
   final DateTime createdAt;
@@ -23,7 +25,7 @@ class Service {
     required this.updatedAt,
   });
 
-  String get formattedPrice => '\$${price.toStringAsFixed(2)}';
+  String get formattedPrice => price.formatted;
   String get formattedDuration => '${duration} min';
 
   factory Service.fromJson(Map<String, dynamic> json) {
@@ -33,7 +35,7 @@ class Service {
       name: json['name'],
       description: json['description'],
       category: ServiceType.fromString(json['category']),
-      price: json['price'].toDouble(),
+      price: Money.fromJson(json['price']),
       duration: json['duration'],
       available: json['available'],
       createdAt: DateTime.parse(json['created_at']),
@@ -48,7 +50,7 @@ class Service {
       'name': name,
       'description': description,
       'category': category.value,
-      'price': price,
+      'price': price.toJson(),
       'duration': duration,
       'available': available,
       'created_at': createdAt.toIso8601String(),
@@ -91,7 +93,7 @@ class CreateServiceRequest {
   final String name;
   final String description;
   final ServiceType category;
-  final double price;
+  final Money price;
   final int duration;
   final bool available;
 
@@ -109,7 +111,7 @@ class CreateServiceRequest {
       'name': name,
       'description': description,
       'category': category.value,
-      'price': price,
+      'price': price.toJson(),
       'duration': duration,
       'available': available,
     };
@@ -331,6 +331,7 @@ class _BookingsList extends StatelessWidget {
       case BookingStatus.completed:
         return Colors.green;
       case BookingStatus.cancelled:
+      case BookingStatus.noShow:
         return Colors.red;
     }
   }
@@ -0,0 +1,54 @@
+import 'package:flutter_test/flutter_test.dart';
+
+import 'package:pet_grooming_app/models/booking.dart';
+import 'package:pet_grooming_app/models/money.dart';
+import 'package:pet_grooming_app/models/service.dart';
+
+void main() {
+  test('reads amounts in minor units', () {
+    final money = Money.fromJson({'amount': 1250, 'currency': 'USD'});
+    expect(money.amount, 1250);
+    expect(money.currency, 'USD');
+    expect(money.toJson(), {'amount': 1250, 'currency': 'USD'});
+  });
+
+  test('formats using the currency exponent', () {
+    expect(const Money(amount: 1250, currency: 'USD').formatted, '\$12.50');
+    expect(const Money(amount: 1500, currency: 'JPY').formatted, '¥1,500');
+    expect(const Money(amount: 12345, currency: 'KWD').exponent, 3);
+    expect(const Money(amount: 12345, currency: 'KWD').majorUnits, 12.345);
+  });
+
+  test('services and bookings parse money objects', () {
+    final service = Service.fromJson({
+      'id': 's1',
+      'provider_id': 'p1',
+      'name': 'Bath',
+      'description': '',
+      'category': 'grooming',
+      'price': {'amount': 4500, 'currency': 'EUR'},
+      'duration': 60,
+      'available': true,
+      'created_at': '2024-01-01T00:00:00Z',
+      'updated_at': '2024-01-01T00:00:00Z',
+    });
+    expect(service.price, isA<Money>());
+    expect(service.price.amount, 4500);
+    expect(service.formattedPrice, '€45.00');
+
+    final booking = Booking.fromJson({
+      'id': 'b1',
+      'user_id': 'u1',
+      'pet_id': 'pet1',
+      'service_id': 's1',
+      'provider_id': 'p1',
+      'scheduled_time': '2024-01-02T10:00:00Z',
+      'status': 'confirmed',
+      'total_price': {'amount': 1500, 'currency': 'JPY'},
+      'created_at': '2024-01-01T00:00:00Z',
+      'updated_at': '2024-01-01T00:00:00Z',
+    });
+    expect(booking.totalPrice.currency, 'JPY');
+    expect(booking.formattedPrice, '¥1,500');
+  });
+}